@@ -14,18 +14,23 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	commandline "github.com/aws/amazon-ec2-instance-selector/v2/pkg/cli"
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/instancetypes"
 	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/selector"
 	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/selector/outputs"
 	"github.com/aws/aws-sdk-go/aws/session"
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
 	"gopkg.in/ini.v1"
 )
 
@@ -44,20 +49,27 @@ const (
 	tableOutput     = "table"
 	tableWideOutput = "table-wide"
 	oneLine         = "one-line"
+	ndjsonOutput    = "ndjson"
 )
 
 // Filter Flag Constants
 const (
 	vcpus                  = "vcpus"
+	threads                = "threads"
+	cores                  = "cores"
 	memory                 = "memory"
+	memoryTolerancePercent = "memory-tolerance-percent"
 	vcpusToMemoryRatio     = "vcpus-to-memory-ratio"
 	cpuArchitecture        = "cpu-architecture"
 	gpus                   = "gpus"
 	gpuMemoryTotal         = "gpu-memory-total"
+	minGpuTier             = "min-gpu-tier"
+	minVCpusPerGpu         = "min-vcpus-per-gpu"
 	placementGroupStrategy = "placement-group-strategy"
 	usageClass             = "usage-class"
 	rootDeviceType         = "root-device-type"
 	enaSupport             = "ena-support"
+	acceleratedNetworking  = "accelerated-networking"
 	efaSupport             = "efa-support"
 	hibernationSupport     = "hibernation-support"
 	baremetal              = "baremetal"
@@ -68,17 +80,28 @@ const (
 	currentGeneration      = "current-generation"
 	networkInterfaces      = "network-interfaces"
 	networkPerformance     = "network-performance"
+	minNetworkPerVCpu      = "min-network-per-vcpu"
+	minAggregateNetwork    = "min-aggregate-network-gbps"
+	ebsVolumeAttachments   = "ebs-volume-attachments"
+	minEBSThroughput       = "min-ebs-baseline-throughput"
+	minStoreThroughput     = "min-instance-store-throughput"
+	familyAgeDays          = "family-age-days"
 	allowList              = "allow-list"
 	denyList               = "deny-list"
+	allowListGlob          = "allow-list-glob"
+	denyListGlob           = "deny-list-glob"
 	virtualizationType     = "virtualization-type"
 	pricePerHour           = "price-per-hour"
+	minSpotSavingsPercent  = "min-spot-savings-percent"
+	requirePriceAvailable  = "require-price-available"
 )
 
 // Aggregate Filter Flags
 const (
-	instanceTypeBase = "base-instance-type"
-	flexible         = "flexible"
-	service          = "service"
+	instanceTypeBase           = "base-instance-type"
+	flexible                   = "flexible"
+	service                    = "service"
+	previousGenerationFallback = "previous-generation-fallback"
 )
 
 // Configuration Flag Constants
@@ -90,6 +113,10 @@ const (
 	version    = "version"
 	region     = "region"
 	output     = "output"
+	unitBase   = "unit-base"
+
+	priceEnrichmentSeconds    = "price-enrichment-seconds"
+	priceEnrichmentMaxLookups = "price-enrichment-max-lookups"
 )
 
 var (
@@ -108,15 +135,18 @@ func main() {
 Filtering allows you to select all the instance types that match your application requirements.
 Full docs can be found at github.com/aws/amazon-` + binName
 	examples := fmt.Sprintf(`%s --vcpus 4 --region us-east-2 --availability-zones us-east-2b
-%s --memory-min 4 --memory-max 8 --vcpus-min 4 --vcpus-max 8 --region us-east-2`, binName, binName)
+%s --memory-min 4 --memory-max 8 --vcpus-min 4 --vcpus-max 8 --region us-east-2
+%s %s m5.large c6g.xlarge --region us-east-2`, binName, binName, binName, priceSubcommand)
 
 	runFunc := func(cmd *cobra.Command, args []string) {}
 	cli := commandline.New(binName, shortUsage, longUsage, examples, runFunc)
+	cli.AddSubcommand(newPriceSubcommand(runPriceSubcommand))
 
 	cliOutputTypes := []string{
 		tableOutput,
 		tableWideOutput,
 		oneLine,
+		ndjsonOutput,
 	}
 	resultsOutputFn := outputs.SimpleInstanceTypeOutput
 
@@ -124,15 +154,21 @@ Full docs can be found at github.com/aws/amazon-` + binName
 	// Filter Flags - These will be grouped at the top of the help flags
 
 	cli.IntMinMaxRangeFlags(vcpus, cli.StringMe("c"), nil, "Number of vcpus available to the instance type.")
+	cli.IntMinMaxRangeFlags(threads, nil, nil, "Number of threads (logical processors) available to the instance type.")
+	cli.IntMinMaxRangeFlags(cores, nil, nil, "Number of physical cores available to the instance type.")
 	cli.ByteQuantityMinMaxRangeFlags(memory, cli.StringMe("m"), nil, "Amount of Memory available (Example: 4 GiB)")
+	cli.Float64Flag(memoryTolerancePercent, nil, nil, "Percentage an exact --memory value may be off by in either direction to allow for unit rounding (Example: 1) (default 0, an exact match)")
 	cli.RatioFlag(vcpusToMemoryRatio, nil, nil, "The ratio of vcpus to GiBs of memory. (Example: 1:2)")
 	cli.StringOptionsFlag(cpuArchitecture, cli.StringMe("a"), nil, "CPU architecture [x86_64/amd64, i386, or arm64]", []string{"x86_64", "amd64", "i386", "arm64"})
 	cli.IntMinMaxRangeFlags(gpus, cli.StringMe("g"), nil, "Total Number of GPUs (Example: 4)")
 	cli.ByteQuantityMinMaxRangeFlags(gpuMemoryTotal, nil, nil, "Number of GPUs' total memory (Example: 4 GiB)")
+	cli.StringOptionsFlag(minGpuTier, nil, nil, fmt.Sprintf("Minimum GPU compute capability tier: [%s]", strings.Join(selector.GpuTiers, ", ")), selector.GpuTiers)
+	cli.Float64Flag(minVCpusPerGpu, nil, nil, "Minimum number of vcpus per GPU (Example: 8)")
 	cli.StringOptionsFlag(placementGroupStrategy, nil, nil, "Placement group strategy: [cluster, partition, spread]", []string{"cluster", "partition", "spread"})
 	cli.StringOptionsFlag(usageClass, cli.StringMe("u"), nil, "Usage class: [spot or on-demand]", []string{"spot", "on-demand"})
 	cli.StringOptionsFlag(rootDeviceType, nil, nil, "Supported root device types: [ebs or instance-store]", []string{"ebs", "instance-store"})
 	cli.BoolFlag(enaSupport, cli.StringMe("e"), nil, "Instance types where ENA is supported or required")
+	cli.BoolFlag(acceleratedNetworking, nil, nil, "Instance types which support SR-IOV based enhanced networking through ENA")
 	cli.BoolFlag(efaSupport, nil, nil, "Instance types that support Elastic Fabric Adapters (EFA)")
 	cli.BoolFlag(hibernationSupport, nil, nil, "Hibernation supported")
 	cli.BoolFlag(baremetal, nil, nil, "Bare Metal instance types (.metal instances)")
@@ -143,16 +179,27 @@ Full docs can be found at github.com/aws/amazon-` + binName
 	cli.BoolFlag(currentGeneration, nil, nil, "Current generation instance types (explicitly set this to false to not return current generation instance types)")
 	cli.IntMinMaxRangeFlags(networkInterfaces, nil, nil, "Number of network interfaces (ENIs) that can be attached to the instance")
 	cli.IntMinMaxRangeFlags(networkPerformance, nil, nil, "Bandwidth in Gib/s of network performance (Example: 100)")
+	cli.Float64Flag(minNetworkPerVCpu, nil, nil, "Minimum network bandwidth in Gib/s per vcpu (Example: 1.25)")
+	cli.Float64Flag(minAggregateNetwork, nil, nil, "Minimum network bandwidth in Gib/s summed across all network cards, for multi-card instance types (Example: 400)")
+	cli.IntMinMaxRangeFlags(ebsVolumeAttachments, nil, nil, "Number of EBS volume attachments, including the root volume, supported by the instance type (Nitro limits assume only the primary ENI is attached)")
+	cli.Float64Flag(minEBSThroughput, nil, nil, "Minimum EBS-optimized baseline throughput in MB/s, for fast initialization of volumes restored from snapshots (Example: 1187.5)")
+	cli.Float64Flag(minStoreThroughput, nil, nil, "Minimum NVMe instance store read throughput in MB/s, only storage optimized families with published throughput match (Example: 1200)")
+	cli.IntMinMaxRangeFlags(familyAgeDays, nil, nil, "Number of days since the instance type family was launched (Example: 365)")
 	cli.RegexFlag(allowList, nil, nil, "List of allowed instance types to select from w/ regex syntax (Example: m[3-5]\\.*)")
 	cli.RegexFlag(denyList, nil, nil, "List of instance types which should be excluded w/ regex syntax (Example: m[1-2]\\.*)")
+	cli.StringSliceFlag(allowListGlob, nil, nil, "List of allowed instance types to select from w/ glob syntax, can't be used with --allow-list (Example: c6g.*,*.xlarge)")
+	cli.StringSliceFlag(denyListGlob, nil, nil, "List of instance types which should be excluded w/ glob syntax, can't be used with --deny-list (Example: *.metal)")
 	cli.StringOptionsFlag(virtualizationType, nil, nil, "Virtualization Type supported: [hvm or pv]", []string{"hvm", "paravirtual", "pv"})
 	cli.Float64MinMaxRangeFlags(pricePerHour, nil, nil, "Price/hour in USD (Example: 0.09)")
+	cli.Float64Flag(minSpotSavingsPercent, nil, nil, "Minimum percentage the 30 day avg spot price is below the on-demand price (Example: 60)")
+	cli.BoolFlag(requirePriceAvailable, nil, nil, "Only return instance types which have an on-demand price in the region")
 
 	// Suite Flags - higher level aggregate filters that return opinionated result
 
 	cli.SuiteStringFlag(instanceTypeBase, nil, nil, "Instance Type used to retrieve similarly spec'd instance types", nil)
 	cli.SuiteBoolFlag(flexible, nil, nil, "Retrieves a group of instance types spanning multiple generations based on opinionated defaults and user overridden resource filters")
 	cli.SuiteStringFlag(service, nil, nil, "Filter instance types based on service support (Example: eks, eks-20201211, or emr-5.20.0)", nil)
+	cli.SuiteBoolFlag(previousGenerationFallback, nil, nil, "Retrieves current generation instance types, falling back to include previous generation instance types only if no current generation instance types match")
 
 	// Configuration Flags - These will be grouped at the bottom of the help flags
 
@@ -160,12 +207,18 @@ Full docs can be found at github.com/aws/amazon-` + binName
 	cli.ConfigStringFlag(profile, nil, nil, "AWS CLI profile to use for credentials and config", nil)
 	cli.ConfigStringFlag(region, cli.StringMe("r"), nil, "AWS Region to use for API requests (NOTE: if not passed in, uses AWS SDK default precedence)", nil)
 	cli.ConfigStringFlag(output, cli.StringMe("o"), nil, fmt.Sprintf("Specify the output format (%s)", strings.Join(cliOutputTypes, ", ")), nil)
+	cli.ByteQuantityUnitBaseFlag(unitBase, nil, nil, "Unit base of memory quantities without an \"i\" like 16gb or 16: [binary (16gb = 16 GiB) or decimal (16gb = 16 GB)] (default binary)")
+	cli.ConfigIntFlag(priceEnrichmentSeconds, nil, nil, "The maximum number of seconds spent looking up prices, instance types are returned without prices once exceeded")
+	cli.ConfigIntFlag(priceEnrichmentMaxLookups, nil, nil, "The maximum number of price lookups, instance types are returned without prices once exceeded")
 	cli.ConfigBoolFlag(verbose, cli.StringMe("v"), nil, "Verbose - will print out full instance specs")
 	cli.ConfigBoolFlag(help, cli.StringMe("h"), nil, "Help")
 	cli.ConfigBoolFlag(version, nil, nil, "Prints CLI version")
 
 	// Parses the user input with the registered flags and runs type specific validation on the user input
 	flags, err := cli.ParseAndValidateFlags()
+	if errors.Is(err, commandline.ErrSubcommandExecuted) {
+		os.Exit(0)
+	}
 	if err != nil {
 		log.Printf("There was an error while parsing the commandline flags: %v", err)
 		os.Exit(1)
@@ -188,62 +241,108 @@ Full docs can be found at github.com/aws/amazon-` + binName
 	flags[region] = sess.Config.Region
 
 	instanceSelector := selector.New(sess)
+	filters := selector.Filters{
+		VCpusRange:                 cli.IntRangeMe(flags[vcpus]),
+		ThreadsRange:               cli.IntRangeMe(flags[threads]),
+		CoresRange:                 cli.IntRangeMe(flags[cores]),
+		MemoryRange:                cli.ByteQuantityRangeMe(flags[memory]),
+		MemoryTolerancePercent:     cli.Float64Me(flags[memoryTolerancePercent]),
+		VCpusToMemoryRatio:         cli.Float64Me(flags[vcpusToMemoryRatio]),
+		CPUArchitecture:            cli.StringMe(flags[cpuArchitecture]),
+		GpusRange:                  cli.IntRangeMe(flags[gpus]),
+		GpuMemoryRange:             cli.ByteQuantityRangeMe(flags[gpuMemoryTotal]),
+		MinGpuTier:                 cli.StringMe(flags[minGpuTier]),
+		MinVCpusPerGpu:             cli.Float64Me(flags[minVCpusPerGpu]),
+		PlacementGroupStrategy:     cli.StringMe(flags[placementGroupStrategy]),
+		UsageClass:                 cli.StringMe(flags[usageClass]),
+		RootDeviceType:             cli.StringMe(flags[rootDeviceType]),
+		EnaSupport:                 cli.BoolMe(flags[enaSupport]),
+		AcceleratedNetworking:      cli.BoolMe(flags[acceleratedNetworking]),
+		EfaSupport:                 cli.BoolMe(flags[efaSupport]),
+		HibernationSupported:       cli.BoolMe(flags[hibernationSupport]),
+		Hypervisor:                 cli.StringMe(flags[hypervisor]),
+		BareMetal:                  cli.BoolMe(flags[baremetal]),
+		Fpga:                       cli.BoolMe(flags[fpgaSupport]),
+		Burstable:                  cli.BoolMe(flags[burstSupport]),
+		Region:                     cli.StringMe(flags[region]),
+		AvailabilityZones:          cli.StringSliceMe(flags[availabilityZones]),
+		CurrentGeneration:          cli.BoolMe(flags[currentGeneration]),
+		MaxResults:                 cli.IntMe(flags[maxResults]),
+		PriceEnrichmentBudget:      getPriceEnrichmentBudget(cli.IntMe(flags[priceEnrichmentSeconds]), cli.IntMe(flags[priceEnrichmentMaxLookups])),
+		NetworkInterfaces:          cli.IntRangeMe(flags[networkInterfaces]),
+		NetworkPerformance:         cli.IntRangeMe(flags[networkPerformance]),
+		MinNetworkPerVCpu:          cli.Float64Me(flags[minNetworkPerVCpu]),
+		MinAggregateNetworkGbps:    cli.Float64Me(flags[minAggregateNetwork]),
+		EBSVolumeAttachments:       cli.IntRangeMe(flags[ebsVolumeAttachments]),
+		MinEBSThroughputForRestore: cli.Float64Me(flags[minEBSThroughput]),
+		MinInstanceStoreThroughput: cli.Float64Me(flags[minStoreThroughput]),
+		FamilyAge:                  cli.IntRangeMe(flags[familyAgeDays]),
+		AllowList:                  cli.RegexMe(flags[allowList]),
+		DenyList:                   cli.RegexMe(flags[denyList]),
+		InstanceTypeBase:           cli.StringMe(flags[instanceTypeBase]),
+		Flexible:                   cli.BoolMe(flags[flexible]),
+		Service:                    cli.StringMe(flags[service]),
+		PreviousGenerationFallback: cli.BoolMe(flags[previousGenerationFallback]),
+		VirtualizationType:         cli.StringMe(flags[virtualizationType]),
+		PricePerHour:               cli.Float64RangeMe(flags[pricePerHour]),
+		MinSpotSavingsPercent:      cli.Float64Me(flags[minSpotSavingsPercent]),
+		RequirePriceAvailable:      cli.BoolMe(flags[requirePriceAvailable]),
+	}
+
+	if filters.AllowList, err = getListRegex(filters.AllowList, cli.StringSliceMe(flags[allowListGlob]), allowList, allowListGlob); err != nil {
+		log.Printf("There was an error while parsing the commandline flags: %v", err)
+		os.Exit(1)
+	}
+	if filters.DenyList, err = getListRegex(filters.DenyList, cli.StringSliceMe(flags[denyListGlob]), denyList, denyListGlob); err != nil {
+		log.Printf("There was an error while parsing the commandline flags: %v", err)
+		os.Exit(1)
+	}
+
+	if err := filters.Validate(); err != nil {
+		log.Println("The filter criteria is invalid:")
+		for _, validationErr := range multierr.Errors(err) {
+			log.Printf("\t - %v", validationErr)
+		}
+		os.Exit(1)
+	}
+
 	outputFlag := cli.StringMe(flags[output])
-	if outputFlag != nil && *outputFlag == tableWideOutput {
+	var hydrateOndemand, hydrateSpot bool
+	if (outputFlag != nil && (*outputFlag == tableWideOutput || *outputFlag == ndjsonOutput)) || flags[minSpotSavingsPercent] != nil {
 		// If output type is `table-wide`, simply print both prices for better comparison,
 		//   even if the actual filter is applied on any one of those based on usage class
+		// The `ndjson` output is meant for ingestion, so it includes both prices as well
+		// The spot savings filter compares both prices, so both caches are needed for it as well
+		hydrateOndemand, hydrateSpot = true, true
+	} else if flags[pricePerHour] != nil || flags[requirePriceAvailable] != nil {
+		// Else, if price filters are applied, only hydrate the respective cache as we don't have to print the prices
+		// The price availability filter requires on-demand prices regardless of the usage class
+		hydrateOndemand = flags[usageClass] == nil || *cli.StringMe(flags[usageClass]) == "on-demand" || flags[requirePriceAvailable] != nil
+		hydrateSpot = flags[pricePerHour] != nil && flags[usageClass] != nil && *cli.StringMe(flags[usageClass]) == "spot"
+	}
 
+	if filters.PriceEnrichmentBudget != nil {
+		// Hydrating the caches in full is not bounded by the budget, so prices are looked up one instance type at a time instead
+		filters.PriceEnrichmentBudget.LookupOnDemandPrices = hydrateOndemand
+		filters.PriceEnrichmentBudget.LookupSpotPrices = hydrateSpot
+	} else {
 		// Save time by hydrating in parallel
 		wg := &sync.WaitGroup{}
-		wg.Add(2)
-		go func(waitGroup *sync.WaitGroup) {
-			defer waitGroup.Done()
-			_ = instanceSelector.EC2Pricing.HydrateOndemandCache()
-		}(wg)
-		go func(waitGroup *sync.WaitGroup) {
-			defer waitGroup.Done()
-			_ = instanceSelector.EC2Pricing.HydrateSpotCache(30)
-		}(wg)
-		wg.Wait()
-	} else if flags[pricePerHour] != nil {
-		// Else, if price filters are applied, only hydrate the respective cache as we don't have to print the prices
-		if flags[usageClass] == nil || *cli.StringMe(flags[usageClass]) == "on-demand" {
-			_ = instanceSelector.EC2Pricing.HydrateOndemandCache()
-		} else {
-			_ = instanceSelector.EC2Pricing.HydrateSpotCache(30)
+		if hydrateOndemand {
+			wg.Add(1)
+			go func(waitGroup *sync.WaitGroup) {
+				defer waitGroup.Done()
+				_ = instanceSelector.EC2Pricing.HydrateOndemandCache()
+			}(wg)
 		}
-	}
-
-	filters := selector.Filters{
-		VCpusRange:             cli.IntRangeMe(flags[vcpus]),
-		MemoryRange:            cli.ByteQuantityRangeMe(flags[memory]),
-		VCpusToMemoryRatio:     cli.Float64Me(flags[vcpusToMemoryRatio]),
-		CPUArchitecture:        cli.StringMe(flags[cpuArchitecture]),
-		GpusRange:              cli.IntRangeMe(flags[gpus]),
-		GpuMemoryRange:         cli.ByteQuantityRangeMe(flags[gpuMemoryTotal]),
-		PlacementGroupStrategy: cli.StringMe(flags[placementGroupStrategy]),
-		UsageClass:             cli.StringMe(flags[usageClass]),
-		RootDeviceType:         cli.StringMe(flags[rootDeviceType]),
-		EnaSupport:             cli.BoolMe(flags[enaSupport]),
-		EfaSupport:             cli.BoolMe(flags[efaSupport]),
-		HibernationSupported:   cli.BoolMe(flags[hibernationSupport]),
-		Hypervisor:             cli.StringMe(flags[hypervisor]),
-		BareMetal:              cli.BoolMe(flags[baremetal]),
-		Fpga:                   cli.BoolMe(flags[fpgaSupport]),
-		Burstable:              cli.BoolMe(flags[burstSupport]),
-		Region:                 cli.StringMe(flags[region]),
-		AvailabilityZones:      cli.StringSliceMe(flags[availabilityZones]),
-		CurrentGeneration:      cli.BoolMe(flags[currentGeneration]),
-		MaxResults:             cli.IntMe(flags[maxResults]),
-		NetworkInterfaces:      cli.IntRangeMe(flags[networkInterfaces]),
-		NetworkPerformance:     cli.IntRangeMe(flags[networkPerformance]),
-		AllowList:              cli.RegexMe(flags[allowList]),
-		DenyList:               cli.RegexMe(flags[denyList]),
-		InstanceTypeBase:       cli.StringMe(flags[instanceTypeBase]),
-		Flexible:               cli.BoolMe(flags[flexible]),
-		Service:                cli.StringMe(flags[service]),
-		VirtualizationType:     cli.StringMe(flags[virtualizationType]),
-		PricePerHour:           cli.Float64RangeMe(flags[pricePerHour]),
+		if hydrateSpot {
+			wg.Add(1)
+			go func(waitGroup *sync.WaitGroup) {
+				defer waitGroup.Done()
+				_ = instanceSelector.EC2Pricing.HydrateSpotCache(30)
+			}(wg)
+		}
+		wg.Wait()
 	}
 
 	if flags[verbose] != nil {
@@ -272,6 +371,7 @@ Full docs can be found at github.com/aws/amazon-` + binName
 	}
 
 	outputFn := getOutputFn(outputFlag, selector.InstanceTypesOutputFn(resultsOutputFn))
+	outputFn = logPreviousGenerationFallback(outputFn)
 
 	instanceTypes, itemsTruncated, err := instanceSelector.FilterWithOutput(filters, outputFn)
 	if err != nil {
@@ -292,6 +392,16 @@ Full docs can be found at github.com/aws/amazon-` + binName
 	}
 }
 
+// logPreviousGenerationFallback wraps an output func to log when the results fell back to include previous generation instance types
+func logPreviousGenerationFallback(outputFn selector.InstanceTypesOutputFn) selector.InstanceTypesOutputFn {
+	return func(instanceTypes []instancetypes.Details) []string {
+		if len(instanceTypes) > 0 && instanceTypes[0].PreviousGenerationFallback {
+			log.Println("No current generation instance types matched the criteria, falling back to include previous generation instance types")
+		}
+		return outputFn(instanceTypes)
+	}
+}
+
 func getOutputFn(outputFlag *string, currentFn selector.InstanceTypesOutputFn) selector.InstanceTypesOutputFn {
 	outputFn := selector.InstanceTypesOutputFn(currentFn)
 	if outputFlag != nil {
@@ -308,11 +418,43 @@ func getOutputFn(outputFlag *string, currentFn selector.InstanceTypesOutputFn) s
 			return selector.InstanceTypesOutputFn(outputs.TableOutputShort)
 		case oneLine:
 			return selector.InstanceTypesOutputFn(outputs.OneLineOutput)
+		case ndjsonOutput:
+			return selector.InstanceTypesOutputFn(outputs.NDJSONOutput)
 		}
 	}
 	return outputFn
 }
 
+// getPriceEnrichmentBudget returns the price enrichment budget from the flags or nil if neither limit was set
+func getPriceEnrichmentBudget(seconds *int, maxLookups *int) *selector.PriceEnrichmentBudget {
+	if seconds == nil && maxLookups == nil {
+		return nil
+	}
+	budget := &selector.PriceEnrichmentBudget{}
+	if seconds != nil {
+		budget.MaxDuration = time.Duration(*seconds) * time.Second
+	}
+	if maxLookups != nil {
+		budget.MaxLookups = *maxLookups
+	}
+	return budget
+}
+
+// getListRegex returns the allow or deny list regex compiled from either the regex flag or the glob flag
+func getListRegex(listRegex *regexp.Regexp, listGlobs *[]string, regexFlag string, globFlag string) (*regexp.Regexp, error) {
+	if listGlobs == nil {
+		return listRegex, nil
+	}
+	if listRegex != nil {
+		return nil, fmt.Errorf("--%s and --%s cannot be used together", regexFlag, globFlag)
+	}
+	globRegex, err := selector.GlobsToRegexp(*listGlobs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input for --%s: %w", globFlag, err)
+	}
+	return globRegex, nil
+}
+
 func getRegionAndProfileAWSSession(regionName *string, profileName *string) (*session.Session, error) {
 	sessOpts := session.Options{SharedConfigState: session.SharedConfigEnable}
 	if regionName != nil {
@@ -0,0 +1,149 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	commandline "github.com/aws/amazon-ec2-instance-selector/v2/pkg/cli"
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/selector"
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/selector/outputs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/spf13/cobra"
+)
+
+const (
+	priceSubcommand = "price"
+
+	// price subcommand output types
+	jsonOutput = "json"
+	csvOutput  = "csv"
+
+	// price subcommand config flags
+	spotDays = "spot-days"
+)
+
+// priceSubcommandPricing is the batch pricing the price subcommand looks up prices with, it is implemented by ec2pricing.EC2Pricing
+type priceSubcommandPricing interface {
+	GetOndemandInstanceTypeCosts(instanceTypes []string) (map[string]float64, []string, error)
+	HydrateSpotCacheForTypes(instanceTypes []string, days int) error
+	GetSpotInstanceTypeNDayAvgCost(instanceType string, availabilityZones []string, days int) (float64, error)
+}
+
+// newPriceSubcommand creates the price subcommand, which prints the on-demand and N-day average spot prices for the instance types
+// passed as arguments. The run func is passed the subcommand's CLI, its processed flags, and the instance types.
+func newPriceSubcommand(run func(cli *commandline.CommandLineInterface, flags map[string]interface{}, instanceTypes []string)) *commandline.CommandLineInterface {
+	shortUsage := "Prints the on-demand and spot prices of EC2 instance types"
+	longUsage := binName + " " + priceSubcommand + ` prints the on-demand hourly price and the N-day average hourly spot price
+of each instance type passed as an argument without running any filters.`
+	examples := fmt.Sprintf(`%s %s m5.large c6g.xlarge --region us-east-2
+%s %s m5.large --spot-days 7 --output csv`, binName, priceSubcommand, binName, priceSubcommand)
+
+	var cli commandline.CommandLineInterface
+	runFunc := func(cmd *cobra.Command, args []string) {
+		flags, err := cli.ProcessAndValidateFlags()
+		if err != nil {
+			log.Printf("There was an error while parsing the commandline flags: %v", err)
+			os.Exit(1)
+		}
+		if flags[help] != nil {
+			return
+		}
+		run(&cli, flags, args)
+	}
+	cli = commandline.New(priceSubcommand, shortUsage, longUsage, examples, runFunc)
+
+	priceOutputTypes := []string{
+		tableOutput,
+		jsonOutput,
+		csvOutput,
+	}
+
+	cli.ConfigIntFlag(spotDays, nil, cli.IntMe(30), "The number of days of spot price history to average")
+	cli.ConfigStringFlag(profile, nil, nil, "AWS CLI profile to use for credentials and config", nil)
+	cli.ConfigStringFlag(region, cli.StringMe("r"), nil, "AWS Region to use for API requests (NOTE: if not passed in, uses AWS SDK default precedence)", nil)
+	cli.ConfigStringOptionsFlag(output, cli.StringMe("o"), cli.StringMe(tableOutput), fmt.Sprintf("Specify the output format (%s)", strings.Join(priceOutputTypes, ", ")), priceOutputTypes)
+	cli.ConfigBoolFlag(help, cli.StringMe("h"), nil, "Help")
+	return &cli
+}
+
+// runPriceSubcommand prints the prices of the instance types with the price subcommand's flags
+func runPriceSubcommand(cli *commandline.CommandLineInterface, flags map[string]interface{}, instanceTypes []string) {
+	if len(instanceTypes) == 0 {
+		log.Printf("At least one instance type must be passed to the %s subcommand (Example: %s %s m5.large)", priceSubcommand, binName, priceSubcommand)
+		os.Exit(1)
+	}
+	days := *cli.IntMe(flags[spotDays])
+	if days <= 0 {
+		log.Printf("--%s must be greater than 0", spotDays)
+		os.Exit(1)
+	}
+
+	sess, err := getRegionAndProfileAWSSession(cli.StringMe(flags[region]), cli.StringMe(flags[profile]))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	instanceTypePrices := getInstanceTypePrices(ec2pricing.New(sess), instanceTypes, days)
+	for _, line := range getPriceOutputFn(cli.StringMe(flags[output]))(instanceTypePrices) {
+		fmt.Println(line)
+	}
+}
+
+// getInstanceTypePrices retrieves the on-demand and N-day average spot prices of the instance types with the batch pricing APIs
+// The on-demand prices are retrieved at once and the spot price history of only the instance types is hydrated, so the spot prices
+// are read from the spot cache. Prices which could not be retrieved are left nil.
+func getInstanceTypePrices(pricing priceSubcommandPricing, instanceTypes []string, days int) []instancetypes.Details {
+	onDemandCosts, missing, err := pricing.GetOndemandInstanceTypeCosts(instanceTypes)
+	if err != nil {
+		log.Printf("Could not retrieve the instantaneous hourly on-demand prices of instance types %s: %v\n", strings.Join(missing, ", "), err)
+	}
+	if err := pricing.HydrateSpotCacheForTypes(instanceTypes, days); err != nil {
+		log.Printf("Could not retrieve the spot price history of the instance types: %v\n", err)
+	}
+
+	instanceTypePrices := []instancetypes.Details{}
+	for _, instanceType := range instanceTypes {
+		details := instancetypes.Details{}
+		details.InstanceType = aws.String(instanceType)
+		if price, ok := onDemandCosts[instanceType]; ok && price >= 0 {
+			details.OndemandPricePerHour = aws.Float64(price)
+		}
+		if price, err := pricing.GetSpotInstanceTypeNDayAvgCost(instanceType, []string{}, days); err != nil {
+			log.Printf("Could not retrieve %d day avg hourly spot price for instance type %s: %v\n", days, instanceType, err)
+		} else if price >= 0 {
+			details.SpotPrice = aws.Float64(price)
+		}
+		instanceTypePrices = append(instanceTypePrices, details)
+	}
+	return instanceTypePrices
+}
+
+func getPriceOutputFn(outputFlag *string) selector.InstanceTypesOutputFn {
+	if outputFlag != nil {
+		switch *outputFlag {
+		case jsonOutput:
+			return selector.InstanceTypesOutputFn(outputs.PriceJSONOutput)
+		case csvOutput:
+			return selector.InstanceTypesOutputFn(outputs.PriceCSVOutput)
+		}
+	}
+	return selector.InstanceTypesOutputFn(outputs.PriceTableOutput)
+}
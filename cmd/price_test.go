@@ -0,0 +1,128 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	commandline "github.com/aws/amazon-ec2-instance-selector/v2/pkg/cli"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/spf13/cobra"
+)
+
+type priceSubcommandPricingMock struct {
+	onDemandCosts     map[string]float64
+	spotCosts         map[string]float64
+	onDemandLookups   [][]string
+	spotHydrations    [][]string
+	spotHydrationDays int
+}
+
+func (m *priceSubcommandPricingMock) GetOndemandInstanceTypeCosts(instanceTypes []string) (map[string]float64, []string, error) {
+	m.onDemandLookups = append(m.onDemandLookups, instanceTypes)
+	costs := map[string]float64{}
+	missing := []string{}
+	for _, instanceType := range instanceTypes {
+		if cost, ok := m.onDemandCosts[instanceType]; ok {
+			costs[instanceType] = cost
+		} else {
+			missing = append(missing, instanceType)
+		}
+	}
+	if len(missing) != 0 {
+		return costs, missing, errors.New("no on-demand price")
+	}
+	return costs, missing, nil
+}
+
+func (m *priceSubcommandPricingMock) HydrateSpotCacheForTypes(instanceTypes []string, days int) error {
+	m.spotHydrations = append(m.spotHydrations, instanceTypes)
+	m.spotHydrationDays = days
+	return nil
+}
+
+func (m *priceSubcommandPricingMock) GetSpotInstanceTypeNDayAvgCost(instanceType string, availabilityZones []string, days int) (float64, error) {
+	if cost, ok := m.spotCosts[instanceType]; ok {
+		return cost, nil
+	}
+	return -1, errors.New("no spot price history")
+}
+
+func TestGetInstanceTypePrices(t *testing.T) {
+	pricingMock := &priceSubcommandPricingMock{
+		onDemandCosts: map[string]float64{"m5.large": 0.096, "c5.large": 0.085},
+		spotCosts:     map[string]float64{"m5.large": 0.035},
+	}
+	instanceTypes := []string{"m5.large", "c5.large", "x9.large"}
+	prices := getInstanceTypePrices(pricingMock, instanceTypes, 7)
+
+	// the prices are retrieved with a single batch lookup and spot hydration rather than per instance type
+	h.Equals(t, [][]string{instanceTypes}, pricingMock.onDemandLookups)
+	h.Equals(t, [][]string{instanceTypes}, pricingMock.spotHydrations)
+	h.Equals(t, 7, pricingMock.spotHydrationDays)
+
+	h.Equals(t, 3, len(prices))
+	h.Equals(t, "m5.large", *prices[0].InstanceType)
+	h.Equals(t, 0.096, *prices[0].OndemandPricePerHour)
+	h.Equals(t, 0.035, *prices[0].SpotPrice)
+	h.Equals(t, "c5.large", *prices[1].InstanceType)
+	h.Equals(t, 0.085, *prices[1].OndemandPricePerHour)
+	h.Assert(t, prices[1].SpotPrice == nil, "Expected no spot price for c5.large")
+	h.Equals(t, "x9.large", *prices[2].InstanceType)
+	h.Assert(t, prices[2].OndemandPricePerHour == nil && prices[2].SpotPrice == nil, "Expected no prices for x9.large")
+}
+
+func TestPriceSubcommand_FlagsBeforeSubcommand(t *testing.T) {
+	defer func(args []string) { os.Args = args }(os.Args)
+	os.Args = []string{binName, "--region", "us-west-2", priceSubcommand, "m5.large", "c5.large", "--output", csvOutput}
+
+	var priceFlags map[string]interface{}
+	var priceInstanceTypes []string
+	root := commandline.New(binName, "", "", "", func(cmd *cobra.Command, args []string) {
+		t.Error("Expected the price subcommand to run instead of the root command")
+	})
+	root.ConfigStringFlag(region, root.StringMe("r"), nil, "", nil)
+	root.AddSubcommand(newPriceSubcommand(func(cli *commandline.CommandLineInterface, flags map[string]interface{}, instanceTypes []string) {
+		priceFlags = flags
+		priceInstanceTypes = instanceTypes
+	}))
+
+	_, err := root.ParseFlags()
+	h.Assert(t, errors.Is(err, commandline.ErrSubcommandExecuted), "Expected ErrSubcommandExecuted, got %v", err)
+	h.Equals(t, []string{"m5.large", "c5.large"}, priceInstanceTypes)
+	h.Equals(t, "us-west-2", *root.StringMe(priceFlags[region]))
+	h.Equals(t, csvOutput, *root.StringMe(priceFlags[output]))
+	h.Equals(t, 30, *root.IntMe(priceFlags[spotDays]))
+}
+
+func TestPriceSubcommand_NotExecutedWithoutName(t *testing.T) {
+	defer func(args []string) { os.Args = args }(os.Args)
+	os.Args = []string{binName, "--region", "us-west-2"}
+
+	rootRan := false
+	root := commandline.New(binName, "", "", "", func(cmd *cobra.Command, args []string) {
+		rootRan = true
+	})
+	root.ConfigStringFlag(region, root.StringMe("r"), nil, "", nil)
+	root.AddSubcommand(newPriceSubcommand(func(cli *commandline.CommandLineInterface, flags map[string]interface{}, instanceTypes []string) {
+		t.Error("Expected the root command to run instead of the price subcommand")
+	}))
+
+	flags, err := root.ParseFlags()
+	h.Ok(t, err)
+	h.Assert(t, rootRan, "Expected the root command to run")
+	h.Equals(t, "us-west-2", *root.StringMe(flags[region]))
+}
@@ -33,6 +33,24 @@ const (
 	maxTiB            = math.MaxUint64 / tbConvert
 )
 
+// UnitBase determines whether byte quantity units without an explicit binary "i" (like gb, g, or no unit at all) are
+// interpreted as powers of 1024 (binary) or powers of 1000 (decimal)
+type UnitBase string
+
+const (
+	// UnitBaseBinary interprets 1gb as 1 GiB (1024^3 bytes). This is the default.
+	UnitBaseBinary UnitBase = "binary"
+	// UnitBaseDecimal interprets 1gb as 1 GB (1000^3 bytes). Units with an "i" like 1gib are always binary.
+	UnitBaseDecimal UnitBase = "decimal"
+)
+
+// decimalToMiB holds the number of mebibytes in one decimal unit
+var decimalToMiB = map[string]float64{
+	"m": 1e6 / (1 << 20),
+	"g": 1e9 / (1 << 20),
+	"t": 1e12 / (1 << 20),
+}
+
 // ByteQuantity is a data type representing a byte quantity
 type ByteQuantity struct {
 	Quantity uint64
@@ -40,7 +58,16 @@ type ByteQuantity struct {
 
 // ParseToByteQuantity parses a string representation of a byte quantity to a ByteQuantity type.
 // A unit can be appended such as 16 GiB. If no unit is appended, GiB is assumed.
+// Units are always binary, so 16gb is the same as 16gib. Use ParseToByteQuantityWithUnitBase to parse decimal units.
 func ParseToByteQuantity(byteQuantityStr string) (ByteQuantity, error) {
+	return ParseToByteQuantityWithUnitBase(byteQuantityStr, UnitBaseBinary)
+}
+
+// ParseToByteQuantityWithUnitBase parses a string representation of a byte quantity to a ByteQuantity type.
+// Units with an "i" such as 16 GiB are always binary. Units without one such as 16 GB, as well as quantities
+// without a unit, are interpreted using the unitBase. If no unit is appended, gigabytes are assumed.
+// Decimal quantities are rounded to the nearest mebibyte.
+func ParseToByteQuantityWithUnitBase(byteQuantityStr string, unitBase UnitBase) (ByteQuantity, error) {
 	bqRegexp := regexp.MustCompile(byteQuantityRegex)
 	matches := bqRegexp.FindStringSubmatch(strings.ToLower(byteQuantityStr))
 	if len(matches) < 2 {
@@ -52,6 +79,15 @@ func ParseToByteQuantity(byteQuantityStr string) (ByteQuantity, error) {
 	if len(matches) > 2 && matches[2] != "" {
 		unit = matches[2]
 	}
+	switch unitBase {
+	case UnitBaseBinary, "":
+	case UnitBaseDecimal:
+		if len(matches) < 3 || !strings.Contains(matches[2], "i") {
+			return parseDecimal(quantityStr, strings.ToLower(string(unit[0])))
+		}
+	default:
+		return ByteQuantity{}, fmt.Errorf("error unit base %s is not supported", unitBase)
+	}
 	quantity := uint64(0)
 	switch strings.ToLower(string(unit[0])) {
 	//mib
@@ -101,6 +137,28 @@ func ParseToByteQuantity(byteQuantityStr string) (ByteQuantity, error) {
 	}, nil
 }
 
+// parseDecimal converts a quantity of decimal megabytes, gigabytes, or terabytes to a ByteQuantity
+func parseDecimal(quantityStr string, unit string) (ByteQuantity, error) {
+	toMiB, ok := decimalToMiB[unit]
+	if !ok {
+		return ByteQuantity{}, fmt.Errorf("error unit %s is not supported", unit)
+	}
+	quantityDec, err := strconv.ParseFloat(quantityStr, 64)
+	if err != nil {
+		return ByteQuantity{}, err
+	}
+	if unit == "m" && quantityDec != math.Trunc(quantityDec) {
+		return ByteQuantity{}, fmt.Errorf("cannot accept floating point MB value, only integers are accepted")
+	}
+	quantityMiB := math.Round(quantityDec * toMiB)
+	if quantityMiB >= math.MaxUint64 {
+		return ByteQuantity{}, fmt.Errorf("error %sB value is too large", strings.ToUpper(unit))
+	}
+	return ByteQuantity{
+		Quantity: uint64(quantityMiB),
+	}, nil
+}
+
 // FromTiB returns a byte quantity of the passed in tebibytes quantity
 func FromTiB(tib uint64) ByteQuantity {
 	return ByteQuantity{
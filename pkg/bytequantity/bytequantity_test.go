@@ -116,3 +116,34 @@ func TestFromTiB(t *testing.T) {
 	bq := bytequantity.FromTiB(testVal)
 	h.Assert(t, bq.TiB() == expectedVal, "%d TiB should equal %d, instead got %s", expectedVal, expectedVal, bq.StringTiB())
 }
+
+func TestParseToByteQuantityWithUnitBase(t *testing.T) {
+	for _, testQuantity := range []string{"32", "32gb", "32 g", "32.000 GB"} {
+		bq, err := bytequantity.ParseToByteQuantityWithUnitBase(testQuantity, bytequantity.UnitBaseDecimal)
+		h.Ok(t, err)
+		h.Assert(t, bq.Quantity == uint64(30518), "quantity should have been 30518, got %d instead on string %s", bq.Quantity, testQuantity)
+
+		bq, err = bytequantity.ParseToByteQuantityWithUnitBase(testQuantity, bytequantity.UnitBaseBinary)
+		h.Ok(t, err)
+		h.Assert(t, bq.Quantity == uint64(32768), "quantity should have been 32768, got %d instead on string %s", bq.Quantity, testQuantity)
+	}
+
+	// binary units are not affected by the unit base
+	bq, err := bytequantity.ParseToByteQuantityWithUnitBase("32gib", bytequantity.UnitBaseDecimal)
+	h.Ok(t, err)
+	h.Equals(t, uint64(32768), bq.Quantity)
+
+	bq, err = bytequantity.ParseToByteQuantityWithUnitBase("1000mb", bytequantity.UnitBaseDecimal)
+	h.Ok(t, err)
+	h.Equals(t, uint64(954), bq.Quantity)
+
+	bq, err = bytequantity.ParseToByteQuantityWithUnitBase("2tb", bytequantity.UnitBaseDecimal)
+	h.Ok(t, err)
+	h.Equals(t, uint64(1907349), bq.Quantity)
+
+	_, err = bytequantity.ParseToByteQuantityWithUnitBase("1.5mb", bytequantity.UnitBaseDecimal)
+	h.Nok(t, err)
+
+	_, err = bytequantity.ParseToByteQuantityWithUnitBase("1gb", bytequantity.UnitBase("octal"))
+	h.Nok(t, err)
+}
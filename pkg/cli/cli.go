@@ -15,6 +15,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"os"
@@ -49,15 +50,35 @@ func New(binaryName string, shortUsage string, longUsage, examples string, run r
 	}
 }
 
+// ErrSubcommandExecuted is returned by ParseFlags when the args named a subcommand, which was executed instead of the command
+var ErrSubcommandExecuted = errors.New("a subcommand was executed")
+
+// AddSubcommand registers the subcommand so that it is executed instead of the command when its name is passed as an argument
+// The subcommand's run func should call ProcessAndValidateFlags to retrieve the subcommand's flags
+func (cl *CommandLineInterface) AddSubcommand(subcommand *CommandLineInterface) {
+	subcommand.setUsageTemplate()
+	cl.Command.AddCommand(subcommand.Command)
+}
+
 // ParseFlags will parse flags registered in this instance of CLI from os.Args
+// ErrSubcommandExecuted is returned if a subcommand was executed instead
 func (cl *CommandLineInterface) ParseFlags() (map[string]interface{}, error) {
 	cl.setUsageTemplate()
 	// Remove Suite Flags so that args only include Config and Filter Flags
-	cl.Command.SetArgs(removeIntersectingArgs(cl.suiteFlags))
+	// The binary name is dropped so that it is not mistaken for the name of a subcommand
+	args := removeIntersectingArgs(cl.suiteFlags)
+	if len(args) > 0 {
+		args = args[1:]
+	}
+	cl.Command.SetArgs(args)
 	// This parses Config and Filter flags only
-	if err := cl.Command.Execute(); err != nil {
+	executedCommand, err := cl.Command.ExecuteC()
+	if err != nil {
 		return nil, err
 	}
+	if executedCommand != cl.Command {
+		return nil, ErrSubcommandExecuted
+	}
 
 	// Remove Config and Filter flags so that only suite flags are parsed
 	if err := cl.suiteFlags.Parse(removeIntersectingArgs(cl.Command.Flags())); err != nil {
@@ -90,6 +111,21 @@ func (cl *CommandLineInterface) ParseAndValidateFlags() (map[string]interface{},
 	return flags, nil
 }
 
+// ProcessAndValidateFlags processes and validates the flags once they were parsed by executing the command
+// Subcommands call it from their run func since their flags are parsed when the parent command's ParseFlags executes them
+func (cl *CommandLineInterface) ProcessAndValidateFlags() (map[string]interface{}, error) {
+	if err := cl.SetUntouchedFlagValuesToNil(); err != nil {
+		return nil, err
+	}
+	if err := cl.ProcessFlags(); err != nil {
+		return nil, err
+	}
+	if err := cl.ValidateFlags(); err != nil {
+		return nil, err
+	}
+	return cl.Flags, nil
+}
+
 // ProcessFlags iterates through any registered processors and executes them
 // Processors are executed before validators
 func (cl *CommandLineInterface) ProcessFlags() error {
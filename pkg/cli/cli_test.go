@@ -115,6 +115,35 @@ func TestParseFlags(t *testing.T) {
 	h.Assert(t, *flagOutput == "test", "Flag %s should have been parsed", flagArg)
 }
 
+func TestParseFlags_ByteQuantityUnitBase(t *testing.T) {
+	flagName := "test-bq-flag"
+	unitBaseFlagName := "test-unit-base"
+	for unitBase, expectedMiB := range map[string]uint64{"binary": 32768, "decimal": 30518} {
+		cli := getTestCLI()
+		cli.ByteQuantityFlag(flagName, nil, nil, "Test Byte Quantity")
+		cli.ByteQuantityUnitBaseFlag(unitBaseFlagName, nil, nil, "Test Unit Base")
+		os.Args = []string{"ec2-instance-selector", "--" + flagName, "32gb", "--" + unitBaseFlagName, unitBase}
+		flags, err := cli.ParseAndValidateFlags()
+		h.Ok(t, err)
+		h.Equals(t, expectedMiB, flags[flagName].(*bytequantity.ByteQuantity).Quantity)
+	}
+
+	// binary is used when the unit base flag is not set
+	cli := getTestCLI()
+	cli.ByteQuantityFlag(flagName, nil, nil, "Test Byte Quantity")
+	cli.ByteQuantityUnitBaseFlag(unitBaseFlagName, nil, nil, "Test Unit Base")
+	os.Args = []string{"ec2-instance-selector", "--" + flagName, "32gb"}
+	flags, err := cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+	h.Equals(t, uint64(32768), flags[flagName].(*bytequantity.ByteQuantity).Quantity)
+
+	cli = getTestCLI()
+	cli.ByteQuantityUnitBaseFlag(unitBaseFlagName, nil, nil, "Test Unit Base")
+	os.Args = []string{"ec2-instance-selector", "--" + unitBaseFlagName, "octal"}
+	_, err = cli.ParseAndValidateFlags()
+	h.Nok(t, err)
+}
+
 func TestParseFlags_IntRange(t *testing.T) {
 	flagName := "test-flag"
 	flagMinArg := fmt.Sprintf("%s-%s", flagName, "min")
@@ -74,6 +74,11 @@ func (cl *CommandLineInterface) IntFlag(name string, shorthand *string, defaultV
 	cl.IntFlagOnFlagSet(cl.Command.Flags(), name, shorthand, defaultValue, description)
 }
 
+// Float64Flag creates and registers a flag accepting a float64
+func (cl *CommandLineInterface) Float64Flag(name string, shorthand *string, defaultValue *float64, description string) {
+	cl.Float64FlagOnFlagSet(cl.Command.Flags(), name, shorthand, defaultValue, description)
+}
+
 // StringFlag creates and registers a flag accepting a String and a validator function.
 // The validator function is provided so that more complex flags can be created from a string input.
 func (cl *CommandLineInterface) StringFlag(name string, shorthand *string, defaultValue *string, description string, validationFn validator) {
@@ -95,6 +100,13 @@ func (cl *CommandLineInterface) StringOptionsFlag(name string, shorthand *string
 	cl.StringOptionsFlagOnFlagSet(cl.Command.Flags(), name, shorthand, defaultValue, description, validOpts)
 }
 
+// ByteQuantityUnitBaseFlag creates and registers a config flag selecting whether ByteQuantity flag units without an "i" (like gb)
+// are binary (powers of 1024) or decimal (powers of 1000). ByteQuantity flags are binary when the flag is not set.
+func (cl *CommandLineInterface) ByteQuantityUnitBaseFlag(name string, shorthand *string, defaultValue *string, description string) {
+	cl.byteQuantityUnitBaseFlag = name
+	cl.ConfigStringOptionsFlag(name, shorthand, defaultValue, description, []string{string(bytequantity.UnitBaseBinary), string(bytequantity.UnitBaseDecimal)})
+}
+
 // BoolFlag creates and registers a flag accepting a boolean
 func (cl *CommandLineInterface) BoolFlag(name string, shorthand *string, defaultValue *bool, description string) {
 	cl.BoolFlagOnFlagSet(cl.Command.Flags(), name, shorthand, defaultValue, description)
@@ -239,7 +251,7 @@ func (cl *CommandLineInterface) ByteQuantityFlagOnFlagSet(flagSet *pflag.FlagSet
 		}
 		switch byteQuantityInput := val.(type) {
 		case *string:
-			bq, err := bytequantity.ParseToByteQuantity(*byteQuantityInput)
+			bq, err := bytequantity.ParseToByteQuantityWithUnitBase(*byteQuantityInput, cl.byteQuantityUnitBase())
 			if err != nil {
 				return fmt.Errorf(invalidInputMsg+"Can't parse byte quantity %s.", *byteQuantityInput)
 			}
@@ -271,6 +283,19 @@ func (cl *CommandLineInterface) ByteQuantityFlagOnFlagSet(flagSet *pflag.FlagSet
 	cl.StringFlagOnFlagSet(flagSet, name, shorthand, stringDefaultValue, description, byteQuantityProcessor, byteQuantityValidator)
 }
 
+// byteQuantityUnitBase returns the unit base used to parse ByteQuantity flags
+// Invalid unit bases are left to the unit base flag's validator, so binary is returned for them here
+func (cl *CommandLineInterface) byteQuantityUnitBase() bytequantity.UnitBase {
+	if cl.byteQuantityUnitBaseFlag == "" {
+		return bytequantity.UnitBaseBinary
+	}
+	unitBase, ok := cl.Flags[cl.byteQuantityUnitBaseFlag].(*string)
+	if !ok || unitBase == nil || bytequantity.UnitBase(*unitBase) != bytequantity.UnitBaseDecimal {
+		return bytequantity.UnitBaseBinary
+	}
+	return bytequantity.UnitBaseDecimal
+}
+
 // IntFlagOnFlagSet creates and registers a flag accepting an int
 func (cl *CommandLineInterface) IntFlagOnFlagSet(flagSet *pflag.FlagSet, name string, shorthand *string, defaultValue *int, description string) {
 	if defaultValue == nil {
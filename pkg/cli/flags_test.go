@@ -219,3 +219,17 @@ func TestFloat64MinMaxRangeFlags(t *testing.T) {
 	h.Assert(t, len(cli.Flags) == 3, "Should contain 3 flags w/ no shorthand")
 	h.Assert(t, ok, "Should contain %s flag w/ no shorthand", flagName)
 }
+
+func TestFloat64Flag(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-float64"
+	cli.Float64Flag(flagName, cli.StringMe("t"), nil, "Test Float64")
+	_, ok := cli.Flags[flagName]
+	h.Assert(t, len(cli.Flags) == 1, "Should contain 1 flag")
+	h.Assert(t, ok, "Should contain %s flag", flagName)
+
+	cli = getTestCLI()
+	cli.Float64Flag(flagName, nil, nil, "Test Float64")
+	h.Assert(t, len(cli.Flags) == 1, "Should contain 1 flag w/ no shorthand")
+	h.Assert(t, ok, "Should contain %s flag w/ no shorthand", flagName)
+}
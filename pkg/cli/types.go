@@ -63,6 +63,8 @@ type CommandLineInterface struct {
 	validators  map[string]validator
 	processors  map[string]processor
 	suiteFlags  *pflag.FlagSet
+	// byteQuantityUnitBaseFlag is the name of the flag selecting the unit base used to parse ByteQuantity flags
+	byteQuantityUnitBaseFlag string
 }
 
 // Float64Me takes an interface and returns a pointer to a float64 value
@@ -0,0 +1,103 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/multierr"
+)
+
+// maxIndividualOndemandLookups is the most uncached instance types GetOndemandInstanceTypeCosts queries the Pricing API for one at a time
+// The Pricing API's filters only match a single value, so larger lists hydrate the whole on-demand cache in one paginated query instead
+const maxIndividualOndemandLookups = 10
+
+// GetOndemandInstanceTypeCosts retrieves the on-demand hourly cost of each instance type keyed by instance type, along with the
+// instance types which do not have an on-demand price
+// Cached prices and prices set with SetOndemandPriceOverride are used first. If more than a handful of instance types are not cached,
+// the on-demand cache is hydrated so that all of them are retrieved at once rather than with a Pricing API round trip each.
+// Instance types whose price could not be retrieved are reported as missing and their errors are combined into the returned error
+func (p *EC2Pricing) GetOndemandInstanceTypeCosts(instanceTypes []string) (map[string]float64, []string, error) {
+	return p.GetOndemandInstanceTypeCostsWithContext(context.Background(), instanceTypes)
+}
+
+// GetOndemandInstanceTypeCostsWithContext is like GetOndemandInstanceTypeCosts but the Pricing API requests are canceled when the
+// context is done
+func (p *EC2Pricing) GetOndemandInstanceTypeCostsWithContext(ctx context.Context, instanceTypes []string) (map[string]float64, []string, error) {
+	p.refreshExpiredOndemandCache(ctx)
+	uncached := 0
+	p.cacheMu.RLock()
+	for _, instanceType := range instanceTypes {
+		_, isOverridden := p.onDemandPriceOverrides[instanceType]
+		_, isCached := p.onDemandCache[instanceType]
+		if !isOverridden && !isCached {
+			uncached++
+		}
+	}
+	p.cacheMu.RUnlock()
+
+	isHydrated := false
+	if uncached > maxIndividualOndemandLookups {
+		p.log().Debugf("%d instance types are not in the on-demand price cache, hydrating it", uncached)
+		err := p.HydrateOndemandCacheWithContext(ctx)
+		var partialErr *PartialHydrationError
+		if errors.As(err, &partialErr) {
+			// the skipped price documents are reported as missing below, the rest of the cache is usable
+			p.log().Warnf("continuing with a partially hydrated on-demand price cache: %v", err)
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("unable to hydrate the on-demand price cache: %w", err)
+		}
+		isHydrated = true
+	}
+
+	costs := make(map[string]float64, len(instanceTypes))
+	missing := []string{}
+	var errs error
+	for _, instanceType := range instanceTypes {
+		if _, ok := costs[instanceType]; ok {
+			continue
+		}
+		if isHydrated && p.MaxCacheEntries <= 0 && !p.isOndemandPriceKnown(instanceType) {
+			// the instance type would have been in the freshly hydrated cache if the Pricing API had a price for it,
+			// unless it was evicted from a bounded cache
+			missing = append(missing, instanceType)
+			continue
+		}
+		price, err := p.GetOndemandInstanceTypeCostWithContext(ctx, instanceType)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("unable to retrieve the on-demand price of instance type %s: %w", instanceType, err))
+			missing = append(missing, instanceType)
+			continue
+		}
+		if price < 0 {
+			missing = append(missing, instanceType)
+			continue
+		}
+		costs[instanceType] = price
+	}
+	return costs, missing, errs
+}
+
+// isOndemandPriceKnown returns true if the instance type has an on-demand price override or is in the on-demand cache
+func (p *EC2Pricing) isOndemandPriceKnown(instanceType string) bool {
+	p.cacheMu.RLock()
+	defer p.cacheMu.RUnlock()
+	if _, ok := p.onDemandPriceOverrides[instanceType]; ok {
+		return true
+	}
+	_, ok := p.onDemandCache[instanceType]
+	return ok
+}
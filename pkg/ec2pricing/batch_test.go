@@ -0,0 +1,131 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/pricing"
+	"go.uber.org/multierr"
+)
+
+// productsPriceDoc returns the m5.large price document with the instance type and on-demand price replaced
+func productsPriceDoc(t *testing.T, instanceType string, price string) aws.JSONValue {
+	mockFilename := fmt.Sprintf("%s/%s/%s", mockFilesPath, getProductsPages, "m5_large.json")
+	mockFile, err := ioutil.ReadFile(mockFilename)
+	h.Ok(t, err)
+	priceDocJSON := strings.ReplaceAll(string(mockFile), "m5.large", instanceType)
+	priceDocJSON = strings.ReplaceAll(priceDocJSON, "0.0960000000", price)
+	priceDoc := aws.JSONValue{}
+	h.Ok(t, json.Unmarshal([]byte(priceDocJSON), &priceDoc))
+	return priceDoc
+}
+
+func setupBatchPricing(t *testing.T) (*ec2pricing.EC2Pricing, mockedPricing) {
+	pricingMock := mockedPricing{
+		GetProductsPagesCalls: new(int),
+		GetProductsPagesRespPages: []pricing.GetProductsOutput{
+			{PriceList: []aws.JSONValue{productsPriceDoc(t, "m5.large", "0.0960000000"), productsPriceDoc(t, "c5.large", "0.0850000000")}},
+			{PriceList: []aws.JSONValue{productsPriceDoc(t, "r5.large", "0.1260000000"), productsPriceDoc(t, "m5.xlarge", "0.1920000000")}},
+			{PriceList: []aws.JSONValue{productsPriceDoc(t, "c5.xlarge", "0.1700000000")}},
+		},
+	}
+	ec2pricingClient := setupFixturePricing(t, "m5_large.json")
+	ec2pricingClient.PricingClient = pricingMock
+	return ec2pricingClient, pricingMock
+}
+
+func TestGetOndemandInstanceTypeCosts_HydratesLargeLists(t *testing.T) {
+	ec2pricingClient, pricingMock := setupBatchPricing(t)
+	instanceTypes := []string{"m5.large", "c5.large", "r5.large", "m5.xlarge", "c5.xlarge",
+		"a1.large", "t3.micro", "t3.small", "z1d.large", "x1.16xlarge", "d2.xlarge", "h1.2xlarge"}
+	costs, missing, err := ec2pricingClient.GetOndemandInstanceTypeCosts(instanceTypes)
+	h.Ok(t, err)
+	h.Equals(t, 2, *pricingMock.GetProductsPagesCalls)
+	h.Equals(t, map[string]float64{
+		"m5.large":  0.096,
+		"c5.large":  0.085,
+		"r5.large":  0.126,
+		"m5.xlarge": 0.192,
+		"c5.xlarge": 0.17,
+	}, costs)
+	h.Equals(t, []string{"a1.large", "t3.micro", "t3.small", "z1d.large", "x1.16xlarge", "d2.xlarge", "h1.2xlarge"}, missing)
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() != nil, "Expected the on-demand cache to be hydrated")
+}
+
+func TestGetOndemandInstanceTypeCosts_PartialHydration(t *testing.T) {
+	ec2pricingClient, pricingMock := setupBatchPricing(t)
+	// the Pricing API lists $0 prices for new or misconfigured SKUs, which are skipped by the hydration
+	pricingMock.GetProductsPagesRespPages[2].PriceList = append(pricingMock.GetProductsPagesRespPages[2].PriceList, productsPriceDoc(t, "t3.micro", "0.0000000000"))
+	ec2pricingClient.PricingClient = pricingMock
+	instanceTypes := []string{"m5.large", "c5.large", "r5.large", "m5.xlarge", "c5.xlarge",
+		"a1.large", "t3.micro", "t3.small", "z1d.large", "x1.16xlarge", "d2.xlarge", "h1.2xlarge"}
+	costs, missing, err := ec2pricingClient.GetOndemandInstanceTypeCosts(instanceTypes)
+	h.Ok(t, err)
+	h.Equals(t, 2, *pricingMock.GetProductsPagesCalls)
+	h.Equals(t, map[string]float64{
+		"m5.large":  0.096,
+		"c5.large":  0.085,
+		"r5.large":  0.126,
+		"m5.xlarge": 0.192,
+		"c5.xlarge": 0.17,
+	}, costs)
+	h.Equals(t, []string{"a1.large", "t3.micro", "t3.small", "z1d.large", "x1.16xlarge", "d2.xlarge", "h1.2xlarge"}, missing)
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() != nil, "Expected the on-demand cache to be hydrated")
+}
+
+func TestGetOndemandInstanceTypeCosts_SmallList(t *testing.T) {
+	ec2pricingClient, pricingMock := setupBatchPricing(t)
+	costs, missing, err := ec2pricingClient.GetOndemandInstanceTypeCosts([]string{"m5.large", "c5.xlarge", "z1d.large"})
+	h.Ok(t, err)
+	// each uncached instance type is queried on its own instead of hydrating the whole cache, and the empty price list of
+	// z1d.large is retried once
+	h.Equals(t, 4, *pricingMock.GetProductsPagesCalls)
+	h.Equals(t, map[string]float64{"m5.large": 0.096, "c5.xlarge": 0.17}, costs)
+	h.Equals(t, []string{"z1d.large"}, missing)
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() == nil, "Expected the on-demand cache to not be hydrated")
+}
+
+func TestGetOndemandInstanceTypeCosts_Cached(t *testing.T) {
+	ec2pricingClient, pricingMock := setupBatchPricing(t)
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	ec2pricingClient.SetOndemandPriceOverride("z1d.large", 0.186)
+	costs, missing, err := ec2pricingClient.GetOndemandInstanceTypeCosts([]string{"m5.large", "r5.large", "z1d.large"})
+	h.Ok(t, err)
+	h.Equals(t, 2, *pricingMock.GetProductsPagesCalls)
+	h.Equals(t, map[string]float64{"m5.large": 0.096, "r5.large": 0.126, "z1d.large": 0.186}, costs)
+	h.Equals(t, []string{}, missing)
+}
+
+func TestGetOndemandInstanceTypeCostsWithContext_Canceled(t *testing.T) {
+	ec2pricingClient, _ := setupBatchPricing(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	costs, missing, err := ec2pricingClient.GetOndemandInstanceTypeCostsWithContext(ctx, []string{"m5.large", "c5.large"})
+	h.Equals(t, map[string]float64{}, costs)
+	h.Equals(t, []string{"m5.large", "c5.large"}, missing)
+	h.Equals(t, 2, len(multierr.Errors(err)))
+	for _, lookupErr := range multierr.Errors(err) {
+		h.Assert(t, errors.Is(lookupErr, context.Canceled), "Expected the lookup to be canceled, got %v", lookupErr)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+// CostBreakdown itemizes the hourly on-demand price of an instance type running the OperatingSystem
+type CostBreakdown struct {
+	InstanceType    string `json:"InstanceType"`
+	OperatingSystem string `json:"OperatingSystem"`
+	// BaseHourly is the hourly on-demand price of the instance type running Linux, which carries no license cost
+	BaseHourly float64 `json:"BaseHourly"`
+	// LicenseHourly is the hourly operating system license cost, the difference between the TotalHourly and the BaseHourly
+	LicenseHourly float64 `json:"LicenseHourly"`
+	// TotalHourly is the hourly on-demand price of the instance type running the OperatingSystem
+	TotalHourly float64 `json:"TotalHourly"`
+	Currency    string  `json:"Currency"`
+}
+
+// GetOndemandInstanceTypeCostBreakdown retrieves the hourly on-demand price of the instance type for the OperatingSystem and breaks it
+// down into the base instance price and the operating system license cost
+// The base price is the instance type's Linux price, so the license cost is 0 for Linux and for mac metal instance types, which are
+// priced for macOS regardless of the OperatingSystem
+// An ErrNoOndemandPrice error is returned if the Pricing API does not have either price for the instance type
+func (p *EC2Pricing) GetOndemandInstanceTypeCostBreakdown(instanceType string) (CostBreakdown, error) {
+	return p.GetOndemandInstanceTypeCostBreakdownWithContext(context.Background(), instanceType)
+}
+
+// GetOndemandInstanceTypeCostBreakdownWithContext is like GetOndemandInstanceTypeCostBreakdown but the Pricing API requests are
+// canceled when the context is done
+func (p *EC2Pricing) GetOndemandInstanceTypeCostBreakdownWithContext(ctx context.Context, instanceType string) (CostBreakdown, error) {
+	breakdown := CostBreakdown{
+		InstanceType:    instanceType,
+		OperatingSystem: p.OperatingSystem(),
+		Currency:        p.OndemandCurrency(),
+	}
+	total, err := p.GetOndemandInstanceTypeCostWithContext(ctx, instanceType)
+	if err != nil {
+		return breakdown, err
+	}
+	if total < 0 {
+		return breakdown, fmt.Errorf("%w for instance type %s", ErrNoOndemandPrice, instanceType)
+	}
+	breakdown.TotalHourly = total
+	breakdown.BaseHourly = total
+	if p.OperatingSystem() == OperatingSystemLinux || isMacMetalInstanceType(instanceType) {
+		return breakdown, nil
+	}
+
+	productInput, err := p.getOndemandProductsInput(instanceType)
+	if err != nil {
+		return breakdown, err
+	}
+	productInput.Filters = withOperatingSystemFilter(productInput.Filters, operatingSystems[OperatingSystemLinux].pricingAPIValue)
+	base, err := p.queryOndemandInstanceTypeCost(ctx, instanceType, productInput)
+	if err == errEmptyPriceList {
+		return breakdown, fmt.Errorf("%w for instance type %s running %s", ErrNoOndemandPrice, instanceType, OperatingSystemLinux)
+	}
+	if err != nil {
+		return breakdown, err
+	}
+	breakdown.BaseHourly = base
+	breakdown.LicenseHourly = total - base
+	return breakdown, nil
+}
+
+// withOperatingSystemFilter returns a copy of the Pricing API filters matching the operating system instead
+func withOperatingSystemFilter(filters []*pricing.Filter, operatingSystem string) []*pricing.Filter {
+	operatingSystemFilters := make([]*pricing.Filter, 0, len(filters))
+	for _, filter := range filters {
+		if aws.StringValue(filter.Field) == "operatingSystem" {
+			filter = &pricing.Filter{Type: filter.Type, Field: filter.Field, Value: aws.String(operatingSystem)}
+		}
+		operatingSystemFilters = append(operatingSystemFilters, filter)
+	}
+	return operatingSystemFilters
+}
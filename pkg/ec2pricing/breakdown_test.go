@@ -0,0 +1,88 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+// operatingSystemPricingMock returns the price document of the GetProductsPages input's operatingSystem filter
+type operatingSystemPricingMock struct {
+	mockedPricing
+	priceDocs map[string]aws.JSONValue
+}
+
+func (m operatingSystemPricingMock) GetProductsPagesWithContext(ctx aws.Context, input *pricing.GetProductsInput, fn gpFn, opts ...request.Option) error {
+	output := &pricing.GetProductsOutput{}
+	if priceDoc, ok := m.priceDocs[getOperatingSystemFilter(input)]; ok {
+		output.PriceList = []aws.JSONValue{priceDoc}
+	}
+	fn(output, true)
+	return nil
+}
+
+func setupBreakdownPricing(t *testing.T, priceDocs map[string]aws.JSONValue) *ec2pricing.EC2Pricing {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	return &ec2pricing.EC2Pricing{
+		PricingClient: operatingSystemPricingMock{priceDocs: priceDocs},
+		AWSSession:    &sess,
+	}
+}
+
+func TestGetOndemandInstanceTypeCostBreakdown(t *testing.T) {
+	ec2pricingClient := setupBreakdownPricing(t, map[string]aws.JSONValue{
+		"Linux":   productsPriceDoc(t, "m5.large", "0.0960000000"),
+		"Windows": productsPriceDoc(t, "m5.large", "0.1880000000"),
+	})
+	breakdown, err := ec2pricingClient.GetOndemandInstanceTypeCostBreakdown("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, ec2pricing.CostBreakdown{
+		InstanceType:    "m5.large",
+		OperatingSystem: ec2pricing.OperatingSystemLinux,
+		BaseHourly:      0.096,
+		TotalHourly:     0.096,
+		Currency:        "USD",
+	}, breakdown)
+
+	h.Ok(t, ec2pricingClient.SetOperatingSystem(ec2pricing.OperatingSystemWindows))
+	breakdown, err = ec2pricingClient.GetOndemandInstanceTypeCostBreakdown("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, ec2pricing.OperatingSystemWindows, breakdown.OperatingSystem)
+	h.Equals(t, 0.096, breakdown.BaseHourly)
+	h.Equals(t, 0.188, breakdown.TotalHourly)
+	h.Assert(t, breakdown.LicenseHourly > 0.0919 && breakdown.LicenseHourly < 0.0921, "Expected a license cost of 0.092, got %f", breakdown.LicenseHourly)
+}
+
+func TestGetOndemandInstanceTypeCostBreakdown_NoBasePrice(t *testing.T) {
+	ec2pricingClient := setupBreakdownPricing(t, map[string]aws.JSONValue{
+		"RHEL": productsPriceDoc(t, "m5.large", "0.1560000000"),
+	})
+	h.Ok(t, ec2pricingClient.SetOperatingSystem(ec2pricing.OperatingSystemRHEL))
+	breakdown, err := ec2pricingClient.GetOndemandInstanceTypeCostBreakdown("m5.large")
+	h.Nok(t, err)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoOndemandPrice), "Expected ErrNoOndemandPrice, got %v", err)
+	h.Equals(t, 0.156, breakdown.TotalHourly)
+}
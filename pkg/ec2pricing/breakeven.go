@@ -0,0 +1,102 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+const (
+	// hoursPerMonth is the average number of hours in a month, which is the period spot interruption frequencies are reported over
+	hoursPerMonth = 730
+
+	// breakEvenIterations bounds the bisection search for the break-even job length
+	breakEvenIterations = 200
+)
+
+// BreakEvenSpotHours returns the job length in hours beyond which running on spot is expected to cost more than on-demand.
+// Interrupted jobs are assumed to restart from the beginning after relaunchCostHours of overhead, so the expected spot run time of
+// a job grows exponentially with its length while the savings per hour stay constant. Jobs shorter than the break-even hours are
+// expected to be cheaper on spot. The spot price is the days average in the availability zone, and the interruption rate is
+// retrieved from SpotInterruptionRate.
+// +Inf is returned when spot instances of the type are never interrupted and 0 is returned when spot is never cheaper.
+func (p *EC2Pricing) BreakEvenSpotHours(instanceType string, availabilityZone string, days int, relaunchCostHours float64) (float64, error) {
+	return p.BreakEvenSpotHoursWithContext(context.Background(), instanceType, availabilityZone, days, relaunchCostHours)
+}
+
+// BreakEvenSpotHoursWithContext is like BreakEvenSpotHours but the Pricing API and spot-pricing-history api requests are canceled
+// when the context is done
+func (p *EC2Pricing) BreakEvenSpotHoursWithContext(ctx context.Context, instanceType string, availabilityZone string, days int, relaunchCostHours float64) (float64, error) {
+	if relaunchCostHours < 0 {
+		return 0, fmt.Errorf("relaunch cost hours must be greater than or equal to 0")
+	}
+	if p.SpotInterruptionRate == nil {
+		return 0, fmt.Errorf("spot interruption rates are required to compute the break-even spot hours")
+	}
+	onDemandPrice, err := p.GetOndemandInstanceTypeCostWithContext(ctx, instanceType)
+	if err != nil {
+		return 0, fmt.Errorf("unable to retrieve the on-demand price of instance type %s: %w", instanceType, err)
+	}
+	spotPrice, err := p.GetSpotInstanceTypeNDayAvgCostWithContext(ctx, instanceType, []string{availabilityZone}, days)
+	if err != nil {
+		return 0, fmt.Errorf("unable to retrieve the spot price of instance type %s: %w", instanceType, err)
+	}
+	spotPrice, ok := p.spotPriceInOndemandCurrency(spotPrice)
+	if !ok {
+		return 0, fmt.Errorf("spot prices in %s cannot be compared with on-demand prices in %s", p.SpotCurrency(), p.OndemandCurrency())
+	}
+	interruptionRate, err := p.SpotInterruptionRate(instanceType, availabilityZone)
+	if err != nil {
+		return 0, fmt.Errorf("unable to retrieve the spot interruption rate of instance type %s: %w", instanceType, err)
+	}
+	if interruptionRate < 0 || interruptionRate > 1 {
+		return 0, fmt.Errorf("spot interruption rate %f of instance type %s must be between 0 and 1", interruptionRate, instanceType)
+	}
+	return breakEvenSpotHours(onDemandPrice, spotPrice, interruptionRate/hoursPerMonth, relaunchCostHours), nil
+}
+
+// breakEvenSpotHours returns the job length T where the expected spot cost equals the on-demand cost.
+// With interruptions arriving at interruptionsPerHour (λ) and a restart overhead of R hours, the expected time to complete T hours
+// of work is (1/λ + R)(e^(λT) - 1), so the root of spotPrice*(1/λ + R)(e^(λT) - 1) - onDemandPrice*T is found by bisection.
+func breakEvenSpotHours(onDemandPrice float64, spotPrice float64, interruptionsPerHour float64, relaunchCostHours float64) float64 {
+	if spotPrice >= onDemandPrice {
+		return 0
+	}
+	if interruptionsPerHour == 0 {
+		return math.Inf(1)
+	}
+	costDifference := func(hours float64) float64 {
+		expectedSpotHours := (1/interruptionsPerHour + relaunchCostHours) * math.Expm1(interruptionsPerHour*hours)
+		return spotPrice*expectedSpotHours - onDemandPrice*hours
+	}
+	// spot is more expensive from the start when the overhead of the first interruptions outweighs the savings
+	if spotPrice*(1+interruptionsPerHour*relaunchCostHours) >= onDemandPrice {
+		return 0
+	}
+	lower, upper := 0.0, 1/interruptionsPerHour
+	for costDifference(upper) < 0 {
+		lower, upper = upper, upper*2
+	}
+	for i := 0; i < breakEvenIterations && upper-lower > 1e-9*upper; i++ {
+		mid := (lower + upper) / 2
+		if costDifference(mid) < 0 {
+			lower = mid
+		} else {
+			upper = mid
+		}
+	}
+	return (lower + upper) / 2
+}
@@ -0,0 +1,105 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+)
+
+func setupBreakEvenPricing(t *testing.T, interruptionRate float64) *ec2pricing.EC2Pricing {
+	ec2pricingClient := setupFixturePricing(t, "m5_large_multi_os.json")
+	ec2pricingClient.SpotInterruptionRate = func(instanceType string, availabilityZone string) (float64, error) {
+		return interruptionRate, nil
+	}
+	ec2pricingClient.SetOndemandPriceOverride("m5.large", 0.096)
+	return ec2pricingClient
+}
+
+func TestBreakEvenSpotHours(t *testing.T) {
+	ec2pricingClient := setupBreakEvenPricing(t, 0.05)
+	hours, err := ec2pricingClient.BreakEvenSpotHours("m5.large", "us-east-1a", 30, 0.5)
+	h.Ok(t, err)
+	h.Assert(t, hours > 0 && !math.IsInf(hours, 1), "Expected a finite break-even, got %f", hours)
+
+	// at the break-even the expected spot cost of a restarted job equals the on-demand cost
+	interruptionsPerHour := 0.05 / 730
+	expectedSpotHours := (1/interruptionsPerHour + 0.5) * math.Expm1(interruptionsPerHour*hours)
+	h.Assert(t, math.Abs(0.04*expectedSpotHours-0.096*hours) < 1e-6*hours, "Expected spot and on-demand costs to be equal at %f hours", hours)
+
+	// more relaunch overhead shortens the break-even
+	shorterHours, err := ec2pricingClient.BreakEvenSpotHours("m5.large", "us-east-1a", 30, 5)
+	h.Ok(t, err)
+	h.Assert(t, shorterHours < hours, "Expected a shorter break-even than %f, got %f", hours, shorterHours)
+}
+
+func TestBreakEvenSpotHours_NeverInterrupted(t *testing.T) {
+	ec2pricingClient := setupBreakEvenPricing(t, 0)
+	hours, err := ec2pricingClient.BreakEvenSpotHours("m5.large", "us-east-1a", 30, 0.5)
+	h.Ok(t, err)
+	h.Assert(t, math.IsInf(hours, 1), "Expected spot to always be cheaper, got %f", hours)
+}
+
+func TestBreakEvenSpotHours_NeverCheaper(t *testing.T) {
+	ec2pricingClient := setupBreakEvenPricing(t, 1)
+	hours, err := ec2pricingClient.BreakEvenSpotHours("m5.large", "us-east-1a", 30, 2000)
+	h.Ok(t, err)
+	h.Equals(t, float64(0), hours)
+}
+
+func TestBreakEvenSpotHours_Errors(t *testing.T) {
+	ec2pricingClient := setupBreakEvenPricing(t, 0.05)
+	_, err := ec2pricingClient.BreakEvenSpotHours("m5.large", "us-east-1a", 30, -1)
+	h.Nok(t, err)
+
+	ec2pricingClient = setupBreakEvenPricing(t, 1.5)
+	_, err = ec2pricingClient.BreakEvenSpotHours("m5.large", "us-east-1a", 30, 0.5)
+	h.Nok(t, err)
+
+	ec2pricingClient.SpotInterruptionRate = func(instanceType string, availabilityZone string) (float64, error) {
+		return 0, fmt.Errorf("no interruption rate for %s", instanceType)
+	}
+	_, err = ec2pricingClient.BreakEvenSpotHours("m5.large", "us-east-1a", 30, 0.5)
+	h.Nok(t, err)
+
+	ec2pricingClient.SpotInterruptionRate = nil
+	_, err = ec2pricingClient.BreakEvenSpotHours("m5.large", "us-east-1a", 30, 0.5)
+	h.Nok(t, err)
+}
+
+func TestBreakEvenSpotHours_SpotCurrency(t *testing.T) {
+	ec2pricingClient := setupBreakEvenPricing(t, 0.05)
+	hours, err := ec2pricingClient.BreakEvenSpotHours("m5.large", "us-east-1a", 30, 0.5)
+	h.Ok(t, err)
+
+	// converting spot prices does not change how they compare to the USD on-demand price
+	ec2pricing.WithSpotCurrency("EUR", 0.9)(ec2pricingClient)
+	convertedHours, err := ec2pricingClient.BreakEvenSpotHours("m5.large", "us-east-1a", 30, 0.5)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(hours-convertedHours) < 1e-6*hours, "Expected the same break-even as in USD %f, got %f", hours, convertedHours)
+}
+
+func TestBreakEvenSpotHoursWithContext_Canceled(t *testing.T) {
+	ec2pricingClient := setupBreakEvenPricing(t, 0.05)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ec2pricingClient.BreakEvenSpotHoursWithContext(ctx, "m5.large", "us-east-1a", 30, 0.5)
+	h.Assert(t, errors.Is(err, context.Canceled), "Expected the spot price lookup to be canceled, got %v", err)
+}
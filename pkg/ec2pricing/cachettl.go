@@ -0,0 +1,62 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"time"
+)
+
+// isCacheExpired returns true if the cache was hydrated and is older than the CacheTTL
+// Caches never expire when the CacheTTL is 0
+func (p *EC2Pricing) isCacheExpired(lastCacheUTC *time.Time) bool {
+	return p.CacheTTL > 0 && lastCacheUTC != nil && p.now().Sub(*lastCacheUTC) >= p.CacheTTL
+}
+
+// refreshExpiredOndemandCache re-hydrates the on-demand cache if it has expired
+// The expired cache is kept and used if it cannot be refreshed
+func (p *EC2Pricing) refreshExpiredOndemandCache(ctx context.Context) {
+	if p.CacheTTL <= 0 {
+		return
+	}
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+	if !p.isCacheExpired(p.LastOnDemandCacheUTC()) {
+		return
+	}
+	p.log().Infof("the on-demand price cache is older than %s, refreshing it", p.CacheTTL)
+	if err := p.HydrateOndemandCacheWithContext(ctx); err != nil {
+		p.log().Warnf("unable to refresh the expired on-demand price cache: %v", err)
+	}
+}
+
+// refreshExpiredSpotCache re-hydrates the spot cache with the same days, product descriptions, and instance types if it has expired
+// The expired cache is kept and used if it cannot be refreshed
+func (p *EC2Pricing) refreshExpiredSpotCache(ctx context.Context) {
+	if p.CacheTTL <= 0 {
+		return
+	}
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+	if !p.isCacheExpired(p.LastSpotCacheUTC()) {
+		return
+	}
+	p.cacheMu.RLock()
+	days, productDescriptions, instanceTypes := p.spotCacheDays, p.spotCacheProductDescriptions, p.spotCacheInstanceTypes
+	p.cacheMu.RUnlock()
+	p.log().Infof("the spot price cache is older than %s, refreshing it", p.CacheTTL)
+	if err := p.hydrateSpotCache(ctx, days, productDescriptions, instanceTypes); err != nil {
+		p.log().Warnf("unable to refresh the expired spot price cache: %v", err)
+	}
+}
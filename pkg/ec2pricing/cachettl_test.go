@@ -0,0 +1,130 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// fakeClock is a manually advanced clock
+type fakeClock struct {
+	current time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.current
+}
+
+func setupCacheTTLPricing(t *testing.T, ttl time.Duration, clock *fakeClock) (*ec2pricing.EC2Pricing, *int, *[]*ec2.DescribeSpotPriceHistoryInput) {
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	pricingMock.GetProductsPagesCalls = new(int)
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &[]*ec2.DescribeSpotPriceHistoryInput{}
+	ec2pricingClient := setupFixturePricing(t, "m5_large.json")
+	ec2pricingClient.PricingClient = pricingMock
+	ec2pricingClient.EC2Client = ec2Mock
+	ec2pricingClient.Clock = clock.now
+	ec2pricingClient.CacheTTL = ttl
+	return ec2pricingClient, pricingMock.GetProductsPagesCalls, ec2Mock.DescribeSpotPriceHistoryPagesInputs
+}
+
+func TestCacheTTL_OndemandExpiry(t *testing.T) {
+	clock := &fakeClock{current: fixtureClock()}
+	ec2pricingClient, productsCalls, _ := setupCacheTTLPricing(t, time.Hour, clock)
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Equals(t, 2, *productsCalls)
+	h.Equals(t, clock.current, *ec2pricingClient.LastOnDemandCacheUTC())
+
+	clock.current = clock.current.Add(59 * time.Minute)
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+	h.Equals(t, 2, *productsCalls)
+
+	clock.current = clock.current.Add(time.Minute)
+	price, err = ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+	h.Equals(t, 4, *productsCalls)
+	h.Equals(t, clock.current, *ec2pricingClient.LastOnDemandCacheUTC())
+}
+
+func TestCacheTTL_SpotExpiry(t *testing.T) {
+	clock := &fakeClock{current: fixtureClock()}
+	ec2pricingClient, _, spotInputs := setupCacheTTLPricing(t, time.Hour, clock)
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	h.Equals(t, 1, len(*spotInputs))
+
+	_, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 1, len(*spotInputs))
+
+	clock.current = clock.current.Add(2 * time.Hour)
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 2, len(*spotInputs))
+	// the refresh is a bulk hydration over the same window rather than a single instance type query
+	refreshInput := (*spotInputs)[1]
+	h.Assert(t, refreshInput.InstanceTypes == nil, "Expected the expired spot cache to be re-hydrated for all instance types")
+	h.Equals(t, 30*24*time.Hour, refreshInput.EndTime.Sub(*refreshInput.StartTime))
+	h.Equals(t, clock.current, *ec2pricingClient.LastSpotCacheUTC())
+}
+
+func TestCacheTTL_NeverExpires(t *testing.T) {
+	clock := &fakeClock{current: fixtureClock()}
+	ec2pricingClient, productsCalls, spotInputs := setupCacheTTLPricing(t, 0, clock)
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+
+	clock.current = clock.current.Add(365 * 24 * time.Hour)
+	_, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", nil, 30)
+	h.Ok(t, err)
+	h.Equals(t, 2, *productsCalls)
+	h.Equals(t, 1, len(*spotInputs))
+}
+
+func TestClearCaches(t *testing.T) {
+	clock := &fakeClock{current: fixtureClock()}
+	ec2pricingClient, productsCalls, spotInputs := setupCacheTTLPricing(t, 0, clock)
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	_, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 2, *productsCalls)
+	h.Equals(t, 1, len(*spotInputs))
+
+	ec2pricingClient.ClearCaches()
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() == nil, "the on-demand cache should be cleared")
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() == nil, "the spot cache should be cleared")
+
+	// the getters query the APIs again after the caches are cleared
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+	h.Equals(t, 3, *productsCalls)
+	spotPrice, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, float64(0.04148843143974511), spotPrice)
+	h.Equals(t, 2, len(*spotInputs))
+	h.Equals(t, "m5.large", *(*spotInputs)[1].InstanceTypes[0])
+}
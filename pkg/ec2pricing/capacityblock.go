@@ -0,0 +1,170 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+const (
+	// capacityBlockMarketOption is the Pricing API's marketoption attribute of Capacity Block products
+	capacityBlockMarketOption = "CapacityBlock"
+	// Capacity Blocks are reserved for 1 to 14 days in 1 day increments, or up to 182 days in 7 day increments
+	maxCapacityBlockDailyDays = 14
+	maxCapacityBlockDays      = 182
+)
+
+// ErrNoCapacityBlockPrice is returned when the Pricing API does not have a Capacity Block price for an instance type
+var ErrNoCapacityBlockPrice = errors.New("no capacity block price found")
+
+// CapacityBlockPrice is the price of an EC2 Capacity Block for ML of an instance type
+type CapacityBlockPrice struct {
+	InstanceType string
+	// DurationDays is the length of the block
+	DurationDays int
+	// AmountPerHour is the hourly rate of a single instance in the block
+	AmountPerHour float64
+	// Total is the upfront fee of a single instance for the whole block, which is the AmountPerHour for every hour of the DurationDays
+	Total    float64
+	Currency string
+}
+
+// GetCapacityBlockInstanceTypePrice retrieves the price of an EC2 Capacity Block for ML of the instance type in the current AWSSession's
+// region in the OndemandCurrency. The durationDays is 1 to 14 days, or a multiple of 7 days up to 182 days.
+// Capacity Blocks are only offered for a few accelerated instance types (Example: p5.48xlarge, p4d.24xlarge, trn1.32xlarge) in a subset
+// of regions (Example: us-east-1, us-east-2, us-west-2), see https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/ec2-capacity-blocks.html
+// Capacity Block products are separate from the on-demand products and are read from their own pricing term, so they are neither
+// cached nor affected by the Tenancy. Each call queries the Pricing API.
+// An ErrNoCapacityBlockPrice error is returned if the Pricing API does not have a Capacity Block price for the instance type in the region
+func (p *EC2Pricing) GetCapacityBlockInstanceTypePrice(instanceType string, durationDays int) (CapacityBlockPrice, error) {
+	return p.GetCapacityBlockInstanceTypePriceWithContext(context.Background(), instanceType, durationDays)
+}
+
+// GetCapacityBlockInstanceTypePriceWithContext is like GetCapacityBlockInstanceTypePrice but the Pricing API request is canceled when
+// the context is done
+func (p *EC2Pricing) GetCapacityBlockInstanceTypePriceWithContext(ctx context.Context, instanceType string, durationDays int) (CapacityBlockPrice, error) {
+	if err := validateCapacityBlockDuration(durationDays); err != nil {
+		return CapacityBlockPrice{}, err
+	}
+	regionDescription, err := p.getRegionForPricingAPI()
+	if err != nil {
+		return CapacityBlockPrice{}, err
+	}
+	productInput := pricing.GetProductsInput{
+		ServiceCode: aws.String(serviceCode),
+		Filters: []*pricing.Filter{
+			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("ServiceCode"), Value: aws.String(serviceCode)},
+			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("operatingSystem"), Value: aws.String(p.operatingSystemPricing().pricingAPIValue)},
+			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("location"), Value: aws.String(regionDescription)},
+			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("marketoption"), Value: aws.String(capacityBlockMarketOption)},
+			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("instanceType"), Value: aws.String(instanceType)},
+		},
+	}
+
+	if p.planAPICall(APIGetProducts, &productInput) {
+		return CapacityBlockPrice{}, fmt.Errorf("%w for the capacity block price of instance type %s", ErrDryRun, instanceType)
+	}
+	hourlyRate := float64(-1)
+	var processingErr error
+	var errCtx error
+	apiCallStart := time.Now()
+	errAPI := p.getProductsPages(ctx, &productInput, func(pricingOutput *pricing.GetProductsOutput, nextPage bool) bool {
+		if err := ctx.Err(); err != nil {
+			errCtx = err
+			return false
+		}
+		for _, priceDoc := range pricingOutput.PriceList {
+			rate, found, errParse := parseCapacityBlockHourlyRate(priceDoc, p.OndemandCurrency())
+			if errParse != nil {
+				p.log().Warnf("unable to parse a capacity block price document of instance type %s: %v", instanceType, errParse)
+				processingErr = errParse
+				continue
+			}
+			if found {
+				hourlyRate = rate
+				return false
+			}
+		}
+		return true
+	})
+	p.observe().OnAPICall(APIGetProducts, time.Since(apiCallStart))
+	if errAPI == nil {
+		errAPI = errCtx
+	}
+	if errAPI != nil {
+		return CapacityBlockPrice{}, errAPI
+	}
+	if hourlyRate >= 0 {
+		return CapacityBlockPrice{
+			InstanceType:  instanceType,
+			DurationDays:  durationDays,
+			AmountPerHour: hourlyRate,
+			Total:         hourlyRate * float64(24*durationDays),
+			Currency:      p.OndemandCurrency(),
+		}, nil
+	}
+	if processingErr != nil {
+		return CapacityBlockPrice{}, processingErr
+	}
+	return CapacityBlockPrice{}, fmt.Errorf("%w for instance type %s in region %s", ErrNoCapacityBlockPrice, instanceType, p.region())
+}
+
+// validateCapacityBlockDuration returns an error if Capacity Blocks cannot be reserved for the number of days
+func validateCapacityBlockDuration(durationDays int) error {
+	if durationDays < 1 || durationDays > maxCapacityBlockDays || (durationDays > maxCapacityBlockDailyDays && durationDays%7 != 0) {
+		return fmt.Errorf("capacity block duration of %d days is not supported, it must be 1 to %d days or a multiple of 7 days up to %d days",
+			durationDays, maxCapacityBlockDailyDays, maxCapacityBlockDays)
+	}
+	return nil
+}
+
+// parseCapacityBlockHourlyRate returns the hourly rate of a Capacity Block price document from the Pricing API in the currency
+// Capacity Block products list their rate as the single OnDemand term of the document, so documents of other products which do not
+// have the CapacityBlock market option are skipped and false is returned
+func parseCapacityBlockHourlyRate(priceList aws.JSONValue, currency string) (float64, bool, error) {
+	doc, err := decodePriceListDoc(priceList)
+	if err != nil {
+		return -1, false, err
+	}
+	if doc.Product == nil || doc.Product.Attributes == nil {
+		return -1, false, fmt.Errorf("Unable to find product attributes")
+	}
+	if aws.StringValue(doc.Product.Attributes.MarketOption) != capacityBlockMarketOption {
+		return -1, false, nil
+	}
+	if doc.Terms == nil || len(doc.Terms.OnDemand) == 0 {
+		return -1, false, fmt.Errorf("Unable to find capacity block pricing terms")
+	}
+	if len(doc.Terms.OnDemand) > 1 {
+		return -1, false, fmt.Errorf("Unable to choose between %d capacity block pricing terms", len(doc.Terms.OnDemand))
+	}
+	for _, capacityBlockTerm := range doc.Terms.OnDemand {
+		dimension, err := capacityBlockTerm.ondemandHourlyDimension()
+		if err != nil {
+			return -1, false, err
+		}
+		rate, err := dimension.price(currency)
+		if err != nil {
+			return -1, false, fmt.Errorf("unable to parse capacity block price: %w", err)
+		}
+		return rate, true, nil
+	}
+	return -1, false, nil
+}
@@ -0,0 +1,87 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+func TestGetCapacityBlockInstanceTypePrice(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-2"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "p5_48xlarge_capacity_block.json")
+	pricingMock.GetProductsPagesInputs = &[]*pricing.GetProductsInput{}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	price, err := ec2pricingClient.GetCapacityBlockInstanceTypePrice("p5.48xlarge", 7)
+	h.Ok(t, err)
+	h.Equals(t, "p5.48xlarge", price.InstanceType)
+	h.Equals(t, 7, price.DurationDays)
+	h.Equals(t, 31.464, price.AmountPerHour)
+	h.Assert(t, math.Abs(price.Total-31.464*168) < 1e-9, "Expected the hourly rate for every hour of the block, got %f", price.Total)
+	h.Equals(t, "USD", price.Currency)
+
+	input := (*pricingMock.GetProductsPagesInputs)[0]
+	h.Equals(t, "CapacityBlock", getProductsFilterValue(input, "marketoption"))
+	h.Equals(t, "US East (Ohio)", getProductsFilterValue(input, "location"))
+	h.Equals(t, "p5.48xlarge", getProductsFilterValue(input, "instanceType"))
+}
+
+func TestGetCapacityBlockInstanceTypePrice_NotOffered(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	// on-demand products are not capacity blocks
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: setupMock(t, getProductsPages, "m5_large.json"),
+		AWSSession:    &sess,
+	}
+	_, err := ec2pricingClient.GetCapacityBlockInstanceTypePrice("m5.large", 1)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoCapacityBlockPrice), "Expected ErrNoCapacityBlockPrice, got %v", err)
+}
+
+func TestGetCapacityBlockInstanceTypePrice_Durations(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-2"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: setupMock(t, getProductsPages, "p5_48xlarge_capacity_block.json"),
+		AWSSession:    &sess,
+	}
+	for _, durationDays := range []int{1, 13, 14, 21, 182} {
+		_, err := ec2pricingClient.GetCapacityBlockInstanceTypePrice("p5.48xlarge", durationDays)
+		h.Ok(t, err)
+	}
+	for _, durationDays := range []int{-7, 0, 15, 20, 189} {
+		_, err := ec2pricingClient.GetCapacityBlockInstanceTypePrice("p5.48xlarge", durationDays)
+		h.Nok(t, err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Capacity statuses which on-demand prices can be retrieved for
+const (
+	// CapacityStatusUsed is the price of running instances which are not in a capacity reservation
+	CapacityStatusUsed = "used"
+	// CapacityStatusUnusedCapacityReservation is the price of the unused capacity of a capacity reservation
+	CapacityStatusUnusedCapacityReservation = "unusedcapacityreservation"
+	// CapacityStatusAllocatedCapacityReservation is the price of instances running in a capacity reservation
+	CapacityStatusAllocatedCapacityReservation = "allocatedcapacityreservation"
+)
+
+// capacityStatuses maps each supported capacity status to the value of the Pricing API's capacitystatus attribute
+var capacityStatuses = map[string]string{
+	CapacityStatusUsed:                         "Used",
+	CapacityStatusUnusedCapacityReservation:    "UnusedCapacityReservation",
+	CapacityStatusAllocatedCapacityReservation: "AllocatedCapacityReservation",
+}
+
+// SupportedCapacityStatuses returns the capacity statuses which can be passed to SetCapacityStatus sorted alpha-numerically
+func SupportedCapacityStatuses() []string {
+	supported := []string{}
+	for capacityStatus := range capacityStatuses {
+		supported = append(supported, capacityStatus)
+	}
+	sort.Strings(supported)
+	return supported
+}
+
+// WithCapacityStatus sets the capacity status which on-demand prices are retrieved for, capacity statuses which are not one of the
+// SupportedCapacityStatuses are ignored and the used capacity status is used instead
+func WithCapacityStatus(capacityStatus string) Option {
+	return func(p *EC2Pricing) {
+		if err := p.SetCapacityStatus(capacityStatus); err != nil {
+			p.warnOption("%v, using the %s capacity status", err, CapacityStatusUsed)
+		}
+	}
+}
+
+// SetCapacityStatus sets the capacity status which on-demand prices are retrieved for (Example: "UnusedCapacityReservation")
+// Changing the capacity status clears the on-demand cache since it holds the previous capacity status's prices, spot prices are not affected
+// Mac metal instance types are always priced as allocated dedicated hosts regardless of the capacity status
+// An error is returned if the capacity status is not one of the SupportedCapacityStatuses
+func (p *EC2Pricing) SetCapacityStatus(capacityStatus string) error {
+	capacityStatus = strings.ToLower(capacityStatus)
+	if _, ok := capacityStatuses[capacityStatus]; !ok {
+		return fmt.Errorf("capacity status %q is not supported, it must be one of: %s", capacityStatus, strings.Join(SupportedCapacityStatuses(), ", "))
+	}
+	if capacityStatus == p.CapacityStatus() {
+		return nil
+	}
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+	p.capacityStatus = capacityStatus
+	p.onDemandCache = nil
+	p.lastOnDemandCacheUTC = nil
+	return nil
+}
+
+// CapacityStatus returns the capacity status which on-demand prices are retrieved for, which is used by default
+func (p *EC2Pricing) CapacityStatus() string {
+	if p.capacityStatus == "" {
+		return CapacityStatusUsed
+	}
+	return p.capacityStatus
+}
+
+// pricingAPICapacityStatus returns the value of the Pricing API's capacitystatus attribute for the current capacity status
+func (p *EC2Pricing) pricingAPICapacityStatus() string {
+	return capacityStatuses[p.CapacityStatus()]
+}
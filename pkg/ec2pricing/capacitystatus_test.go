@@ -0,0 +1,67 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+func TestSetCapacityStatus(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	pricingMock.GetProductsPagesInputs = &[]*pricing.GetProductsInput{}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	h.Equals(t, ec2pricing.CapacityStatusUsed, ec2pricingClient.CapacityStatus())
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Equals(t, "Used", getProductsFilterValue((*pricingMock.GetProductsPagesInputs)[0], "capacitystatus"))
+
+	h.Ok(t, ec2pricingClient.SetCapacityStatus("UnusedCapacityReservation"))
+	h.Equals(t, ec2pricing.CapacityStatusUnusedCapacityReservation, ec2pricingClient.CapacityStatus())
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() == nil, "Changing the capacity status should clear the on-demand cache")
+	_, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, "UnusedCapacityReservation", getProductsFilterValue((*pricingMock.GetProductsPagesInputs)[2], "capacitystatus"))
+
+	h.Ok(t, ec2pricingClient.SetCapacityStatus(ec2pricing.CapacityStatusAllocatedCapacityReservation))
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Equals(t, "AllocatedCapacityReservation", getProductsFilterValue((*pricingMock.GetProductsPagesInputs)[3], "capacitystatus"))
+	// mac metal instance types are still priced as allocated hosts
+	h.Equals(t, "AllocatedHost", getProductsFilterValue((*pricingMock.GetProductsPagesInputs)[4], "capacitystatus"))
+}
+
+func TestSetCapacityStatus_Unsupported(t *testing.T) {
+	ec2pricingClient := ec2pricing.EC2Pricing{}
+	h.Nok(t, ec2pricingClient.SetCapacityStatus("AllocatedHost"))
+	h.Equals(t, ec2pricing.CapacityStatusUsed, ec2pricingClient.CapacityStatus())
+	h.Equals(t, []string{"allocatedcapacityreservation", "unusedcapacityreservation", "used"}, ec2pricing.SupportedCapacityStatuses())
+}
+
+func TestWithCapacityStatus(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	h.Equals(t, ec2pricing.CapacityStatusUnusedCapacityReservation, ec2pricing.New(sess, ec2pricing.WithCapacityStatus("UnusedCapacityReservation")).CapacityStatus())
+	h.Equals(t, ec2pricing.CapacityStatusUsed, ec2pricing.New(sess, ec2pricing.WithCapacityStatus("reserved")).CapacityStatus())
+}
@@ -0,0 +1,86 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"go.uber.org/multierr"
+)
+
+// GetCheapestRegionForOndemand queries the on-demand price of the instance type in each of the regions and returns the region with the
+// lowest price along with the price in the OndemandCurrency
+// The regions are priced with the PricingClient and the current OperatingSystem, Tenancy, and OndemandCurrency, but the on-demand cache
+// is not used since it only holds the prices of the current AWSSession's region
+// Regions with the same price are ordered by name, so the same region is returned regardless of the order of the regions
+// Regions whose price cannot be retrieved are skipped and their errors are combined into the returned error, and regions without a
+// price for the instance type are skipped. An ErrNoOndemandPrice error is combined into the returned error if none of the regions have a price.
+func (p *EC2Pricing) GetCheapestRegionForOndemand(instanceType string, regions []string) (string, float64, error) {
+	return p.GetCheapestRegionForOndemandWithContext(context.Background(), instanceType, regions)
+}
+
+// GetCheapestRegionForOndemandWithContext is like GetCheapestRegionForOndemand but the Pricing API requests are canceled when the
+// context is done
+func (p *EC2Pricing) GetCheapestRegionForOndemandWithContext(ctx context.Context, instanceType string, regions []string) (string, float64, error) {
+	cheapestRegion := ""
+	cheapestPrice := float64(-1)
+	var errs error
+	for _, region := range regions {
+		price, err := p.forRegion(region).GetOndemandInstanceTypePriceWithContext(ctx, instanceType)
+		if errors.Is(err, ErrNoOndemandPrice) {
+			p.log().Debugf("no on-demand price was found for instance type %s in region %s", instanceType, region)
+			continue
+		}
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("unable to retrieve the on-demand price of instance type %s in region %s: %w", instanceType, region, err))
+			continue
+		}
+		if cheapestRegion == "" || isCheaper(price.AmountPerHour, region, cheapestPrice, cheapestRegion) {
+			cheapestRegion = region
+			cheapestPrice = price.AmountPerHour
+		}
+	}
+	if cheapestRegion == "" {
+		return "", -1, multierr.Append(errs, fmt.Errorf("%w for instance type %s in regions %v", ErrNoOndemandPrice, instanceType, regions))
+	}
+	return cheapestRegion, cheapestPrice, errs
+}
+
+// isCheaper returns true if the candidate's price is lower than the cheapest price found so far
+// Ties are broken by name so that the cheapest candidate does not depend on the order the candidates are compared in
+func isCheaper(price float64, name string, cheapestPrice float64, cheapestName string) bool {
+	if price != cheapestPrice {
+		return price < cheapestPrice
+	}
+	return name < cheapestName
+}
+
+// forRegion returns an EC2Pricing with the same clients and configuration whose AWSSession is a copy of the current one in the region,
+// and whose caches are empty. API calls planned by the copy during a dry run are recorded on p
+func (p *EC2Pricing) forRegion(region string) *EC2Pricing {
+	regionConfig := aws.NewConfig().WithRegion(region)
+	sess := &session.Session{Config: regionConfig}
+	if p.AWSSession != nil {
+		sess = p.AWSSession.Copy(regionConfig)
+	}
+	regional := NewFromClients(p.PricingClient, p.EC2Client, sess)
+	regional.SavingsPlansClient = p.SavingsPlansClient
+	p.copyConfig(regional)
+	regional.dryRunParent = p
+	return regional
+}
@@ -0,0 +1,104 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// regionStateFields are the EC2Pricing fields forRegion does not copy since they are clients, sessions, or state rather than configuration
+var regionStateFields = map[string]bool{
+	"PricingClient":                true,
+	"EC2Client":                    true,
+	"AWSSession":                   true,
+	"SavingsPlansClient":           true,
+	"onDemandCache":                true,
+	"onDemandPriceOverrides":       true,
+	"spotCache":                    true,
+	"lastOnDemandCacheUTC":         true,
+	"lastSpotCacheUTC":             true,
+	"spotCacheEndTime":             true,
+	"spotCacheDays":                true,
+	"spotCacheProductDescriptions": true,
+	"spotCacheInstanceTypes":       true,
+	"cacheMu":                      true,
+	"refreshMu":                    true,
+	"onDemandLRU":                  true,
+	"spotAdvisorFeed":              true,
+	"lastSpotAdvisorUTC":           true,
+	"spotAdvisorMu":                true,
+	"plannedAPICalls":              true,
+	"dryRunMu":                     true,
+	"dryRunParent":                 true,
+	"optionWarnings":               true,
+}
+
+func TestForRegion_CopiesConfig(t *testing.T) {
+	p := &EC2Pricing{
+		AWSSession:                     &session.Session{Config: aws.NewConfig().WithRegion("us-east-1")},
+		operatingSystem:                OperatingSystemWindows,
+		spotProductDescriptionOverride: "Windows (Amazon VPC)",
+		tenancy:                        "Dedicated",
+		capacityStatus:                 "AllocatedCapacityReservation",
+		MaxCacheEntries:                10,
+		CacheTTL:                       time.Hour,
+		pricingEndpointRegion:          "ap-south-1",
+		sdkClientConfig:                aws.NewConfig().WithMaxRetries(5),
+		spotPriceHistoryPageSize:       100,
+		ondemandCurrency:               "CNY",
+		spotCurrency:                   "EUR",
+		spotUSDExchangeRate:            0.9,
+		Clock:                          time.Now,
+		SpotGapThreshold:               time.Hour,
+		InterpolateSpotGaps:            true,
+		OfflineMode:                    true,
+		MinOndemandPrice:               0.001,
+		PricingThrottleMaxAttempts:     5,
+		PricingThrottleBaseDelay:       time.Second,
+		EmptyPriceListRetryDelay:       time.Second,
+		SpotInterruptionRate:           func(instanceType string, availabilityZone string) (float64, error) { return 0.05, nil },
+		SpotAdvisorURL:                 "http://localhost/spot-advisor-data.json",
+		SpotAdvisorHTTPClient:          &http.Client{},
+		DryRun:                         true,
+		logger:                         noopLogger{},
+		observer:                       noopObserver{},
+	}
+	regional := p.forRegion("us-west-2")
+	h.Equals(t, "us-west-2", *regional.AWSSession.Config.Region)
+	h.Assert(t, regional.dryRunParent == p, "Expected the planned API calls of the copy to be recorded on the original")
+
+	original := reflect.ValueOf(p).Elem()
+	copied := reflect.ValueOf(regional).Elem()
+	for i := 0; i < original.NumField(); i++ {
+		name := original.Type().Field(i).Name
+		if regionStateFields[name] {
+			continue
+		}
+		// every configuration field is set above so that a field missing from copyConfig is caught
+		h.Assert(t, !original.Field(i).IsZero(), "Expected the test to set the configuration field %s", name)
+		if original.Field(i).Kind() == reflect.Func {
+			h.Assert(t, original.Field(i).Pointer() == copied.Field(i).Pointer(), "Expected forRegion to copy the configuration field %s", name)
+			continue
+		}
+		h.Equals(t, fmt.Sprintf("%#v", original.Field(i)), fmt.Sprintf("%#v", copied.Field(i)))
+	}
+}
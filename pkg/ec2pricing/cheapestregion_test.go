@@ -0,0 +1,132 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/pricing"
+	"github.com/aws/aws-sdk-go/service/pricing/pricingiface"
+	"go.uber.org/multierr"
+)
+
+// regionalPricing returns the price documents of the location filter of each GetProducts call
+type regionalPricing struct {
+	pricingiface.PricingAPI
+	// PriceLists are keyed by the Pricing API's location description
+	PriceLists map[string][]aws.JSONValue
+	// Errs are returned instead of the price list of the location description
+	Errs map[string]error
+}
+
+func (m regionalPricing) GetProductsPagesWithContext(ctx aws.Context, input *pricing.GetProductsInput, fn gpFn, opts ...request.Option) error {
+	location := getProductsFilterValue(input, "location")
+	if err, ok := m.Errs[location]; ok {
+		return err
+	}
+	fn(&pricing.GetProductsOutput{PriceList: m.PriceLists[location]}, true)
+	return nil
+}
+
+func TestGetCheapestRegionForOndemand(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: regionalPricing{PriceLists: map[string][]aws.JSONValue{
+			"US East (N. Virginia)": {productsPriceDoc(t, "m5.large", "0.0960000000")},
+			"US West (Oregon)":      {productsPriceDoc(t, "m5.large", "0.0910000000")},
+			"Europe (Ireland)":      {productsPriceDoc(t, "m5.large", "0.1070000000")},
+		}},
+		AWSSession: &sess,
+	}
+	region, price, err := ec2pricingClient.GetCheapestRegionForOndemand("m5.large", []string{"us-east-1", "us-west-2", "eu-west-1"})
+	h.Ok(t, err)
+	h.Equals(t, "us-west-2", region)
+	h.Equals(t, 0.091, price)
+
+	// the current session's region is not changed
+	h.Equals(t, "us-east-1", *ec2pricingClient.AWSSession.Config.Region)
+	region, price, err = ec2pricingClient.GetCheapestRegionForOndemand("m5.large", []string{"eu-west-1", "us-east-1"})
+	h.Ok(t, err)
+	h.Equals(t, "us-east-1", region)
+	h.Equals(t, 0.096, price)
+}
+
+func TestGetCheapestRegionForOndemand_Errors(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: regionalPricing{
+			PriceLists: map[string][]aws.JSONValue{
+				"US East (N. Virginia)": {productsPriceDoc(t, "m5.large", "0.0960000000")},
+				"Europe (Ireland)":      {productsPriceDoc(t, "m5.large", "0.1070000000")},
+			},
+			Errs: map[string]error{"Asia Pacific (Sydney)": errors.New("throttled")},
+		},
+		AWSSession: &sess,
+	}
+	// regions which fail are skipped and their errors are returned along with the cheapest of the other regions
+	region, price, err := ec2pricingClient.GetCheapestRegionForOndemand("m5.large", []string{"ap-southeast-2", "eu-west-1", "us-east-1", "cn-north-1"})
+	h.Nok(t, err)
+	h.Equals(t, 2, len(multierr.Errors(err)))
+	h.Equals(t, "us-east-1", region)
+	h.Equals(t, 0.096, price)
+
+	// regions without a price are skipped without an error
+	region, _, err = ec2pricingClient.GetCheapestRegionForOndemand("m5.large", []string{"ap-south-1", "eu-west-1"})
+	h.Ok(t, err)
+	h.Equals(t, "eu-west-1", region)
+
+	_, _, err = ec2pricingClient.GetCheapestRegionForOndemand("m5.large", []string{"ap-south-1", "ap-southeast-2"})
+	errs := multierr.Errors(err)
+	h.Equals(t, 2, len(errs))
+	h.Assert(t, errors.Is(errs[1], ec2pricing.ErrNoOndemandPrice), "Expected ErrNoOndemandPrice, got %v", errs[1])
+}
+
+func TestGetCheapestRegionForOndemand_Ties(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: regionalPricing{PriceLists: map[string][]aws.JSONValue{
+			"US East (N. Virginia)": {productsPriceDoc(t, "m5.large", "0.0960000000")},
+			"US East (Ohio)":        {productsPriceDoc(t, "m5.large", "0.0960000000")},
+			"Europe (Ireland)":      {productsPriceDoc(t, "m5.large", "0.1070000000")},
+		}},
+		AWSSession: &sess,
+	}
+	regions := []string{"us-east-2", "eu-west-1", "us-east-1"}
+	for i := 0; i < 100; i++ {
+		rand.Shuffle(len(regions), func(i, j int) { regions[i], regions[j] = regions[j], regions[i] })
+		region, price, err := ec2pricingClient.GetCheapestRegionForOndemand("m5.large", regions)
+		h.Ok(t, err)
+		h.Equals(t, "us-east-1", region)
+		h.Equals(t, 0.096, price)
+	}
+}
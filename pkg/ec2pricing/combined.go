@@ -0,0 +1,216 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+// enrichCostsConcurrency is the number of instance types whose costs are looked up concurrently by EnrichCosts
+const enrichCostsConcurrency = 10
+
+// CombinedCost is the on-demand and spot cost of an instance type
+type CombinedCost struct {
+	// OnDemand is the hourly on-demand price in the OndemandCurrency or -1 if the instance type has no on-demand price
+	OnDemand float64
+	// SpotAvg is the time weighted average hourly spot price across the availability zones in the SpotCurrency
+	// SpotAvg is -1 if the instance type has no spot price history in the availability zones
+	SpotAvg float64
+	// SpotPerAZ are the average hourly spot prices of each availability zone with spot price history in the SpotCurrency
+	SpotPerAZ map[string]float64
+	// SavingsPercent is the percentage saved by running as spot rather than on-demand, or 0 if either price is not available or
+	// the spot price cannot be converted to the OndemandCurrency
+	SavingsPercent float64
+}
+
+// EnrichCosts retrieves the on-demand price and the N day average spot price of each instance type, keyed by instance type
+// The on-demand and spot caches are hydrated concurrently if they have not been already, and the instance types are then looked up concurrently.
+// Instance types whose prices could not be retrieved are omitted from the result and their errors are combined into the returned error.
+// Passing an empty list for availabilityZones will retrieve spot prices for all AZs in the current AWSSession's region
+func (p *EC2Pricing) EnrichCosts(instanceTypes []string, availabilityZones []string, days int) (map[string]CombinedCost, error) {
+	return p.EnrichCostsWithContext(context.Background(), instanceTypes, availabilityZones, days)
+}
+
+// EnrichCostsWithContext is like EnrichCosts but the Pricing API and spot-pricing-history api requests are canceled when the
+// context is done
+func (p *EC2Pricing) EnrichCostsWithContext(ctx context.Context, instanceTypes []string, availabilityZones []string, days int) (map[string]CombinedCost, error) {
+	p.hydrateMissingCaches(ctx, days)
+
+	costs := make(map[string]CombinedCost, len(instanceTypes))
+	var errs error
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	instanceTypesCh := make(chan string)
+	for i := 0; i < enrichCostsConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for instanceType := range instanceTypesCh {
+				cost, err := p.combinedCost(ctx, instanceType, availabilityZones, days)
+				mu.Lock()
+				if err != nil {
+					errs = multierr.Append(errs, err)
+				} else {
+					costs[instanceType] = cost
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, instanceType := range instanceTypes {
+		instanceTypesCh <- instanceType
+	}
+	close(instanceTypesCh)
+	wg.Wait()
+	return costs, errs
+}
+
+// hydrateMissingCaches concurrently hydrates the on-demand and spot caches which have not been hydrated yet
+// Hydration failures are logged and the prices are then looked up individually
+func (p *EC2Pricing) hydrateMissingCaches(ctx context.Context, days int) {
+	wg := sync.WaitGroup{}
+	if p.LastOnDemandCacheUTC() == nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.HydrateOndemandCacheWithContext(ctx); err != nil {
+				p.log().Warnf("unable to hydrate the on-demand price cache: %v", err)
+			}
+		}()
+	}
+	if p.LastSpotCacheUTC() == nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.HydrateSpotCacheWithContext(ctx, days); err != nil {
+				p.log().Warnf("unable to hydrate the spot price cache: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// combinedCost retrieves the on-demand and spot cost of a single instance type
+func (p *EC2Pricing) combinedCost(ctx context.Context, instanceType string, availabilityZones []string, days int) (CombinedCost, error) {
+	onDemandPrice, err := p.GetOndemandInstanceTypeCostWithContext(ctx, instanceType)
+	if err != nil {
+		return CombinedCost{}, fmt.Errorf("unable to retrieve the on-demand price of instance type %s: %w", instanceType, err)
+	}
+	spotResult, err := p.GetSpotInstanceTypeNDayAvgCostDetailedWithContext(ctx, instanceType, availabilityZones, days)
+	if errors.Is(err, ErrNoSpotPriceHistory) {
+		return CombinedCost{OnDemand: onDemandPrice, SpotAvg: -1, SpotPerAZ: map[string]float64{}}, nil
+	}
+	if err != nil {
+		return CombinedCost{}, fmt.Errorf("unable to retrieve the spot price of instance type %s: %w", instanceType, err)
+	}
+	cost := CombinedCost{
+		OnDemand:  onDemandPrice,
+		SpotAvg:   spotResult.Avg,
+		SpotPerAZ: spotResult.ZoneAvgs,
+	}
+	if spotPrice, ok := p.spotPriceInOndemandCurrency(spotResult.Avg); ok && onDemandPrice > 0 {
+		cost.SavingsPercent = (onDemandPrice - spotPrice) / onDemandPrice * 100
+	}
+	return cost, nil
+}
+
+// GetSpotSavingsOverOndemand returns the percentage saved by running the instance type as spot rather than on-demand, which is
+// (onDemand - spotAvg) / onDemand * 100 using the N day average spot price across the availability zones
+// The percentage is negative when spot is more expensive than on-demand. The on-demand and spot caches are used if they are hydrated.
+// An ErrNoOndemandPrice error is returned if the instance type has no on-demand price, and an ErrNoSpotPriceHistory error is
+// returned if it has no spot price history in the availability zones
+// Passing an empty list for availabilityZones will retrieve the spot price for all AZs in the current AWSSession's region
+func (p *EC2Pricing) GetSpotSavingsOverOndemand(instanceType string, availabilityZones []string, days int) (float64, error) {
+	return p.GetSpotSavingsOverOndemandWithContext(context.Background(), instanceType, availabilityZones, days)
+}
+
+// GetSpotSavingsOverOndemandWithContext is like GetSpotSavingsOverOndemand but the Pricing API and spot-pricing-history api
+// requests are canceled when the context is done
+func (p *EC2Pricing) GetSpotSavingsOverOndemandWithContext(ctx context.Context, instanceType string, availabilityZones []string, days int) (float64, error) {
+	onDemandPrice, err := p.GetOndemandInstanceTypePriceWithContext(ctx, instanceType)
+	if err != nil {
+		return 0, fmt.Errorf("unable to retrieve the on-demand price of instance type %s: %w", instanceType, err)
+	}
+	if onDemandPrice.AmountPerHour <= 0 {
+		return 0, fmt.Errorf("the on-demand price of instance type %s is %f so the spot savings cannot be computed", instanceType, onDemandPrice.AmountPerHour)
+	}
+	spotPrice, err := p.GetSpotInstanceTypeNDayAvgCostWithContext(ctx, instanceType, availabilityZones, days)
+	if err != nil {
+		return 0, fmt.Errorf("unable to retrieve the spot price of instance type %s: %w", instanceType, err)
+	}
+	spotPrice, ok := p.spotPriceInOndemandCurrency(spotPrice)
+	if !ok {
+		return 0, fmt.Errorf("spot prices in %s cannot be compared with on-demand prices in %s", p.SpotCurrency(), p.OndemandCurrency())
+	}
+	return (onDemandPrice.AmountPerHour - spotPrice) / onDemandPrice.AmountPerHour * 100, nil
+}
+
+// InstanceTypePricing is the on-demand and spot pricing of an instance type along with the spot price history it was averaged from
+type InstanceTypePricing struct {
+	InstanceType string
+	// OnDemandHourly is the hourly on-demand price in the OndemandCurrency or -1 if it could not be retrieved
+	OnDemandHourly float64
+	// SpotAvgHourly is the N day time weighted average hourly spot price across the zones in the SpotCurrency or -1 if it could not be retrieved
+	SpotAvgHourly float64
+	// SpotSavingsPct is the percentage saved by running as spot rather than on-demand, or 0 if either price is not available or
+	// the spot price cannot be converted to the OndemandCurrency
+	SpotSavingsPct float64
+	// SpotEarliestSample and SpotLatestSample are the timestamps of the oldest and newest spot price samples which were averaged,
+	// they are zero if the spot price could not be retrieved
+	SpotEarliestSample time.Time
+	SpotLatestSample   time.Time
+}
+
+// GetInstanceTypePricing retrieves the on-demand price and the N day average spot price of the instance type with
+// GetOndemandInstanceTypeCost and GetSpotInstanceTypeNDayAvgCostDetailed, so the on-demand and spot caches are used if they are hydrated
+// The errors of both lookups are combined into the returned error. The pricing is returned along with the error if only one of the
+// prices could not be retrieved, in which case that price is -1, and nil is returned if neither could be retrieved.
+// Passing an empty list for zones will retrieve the spot price for all AZs in the current AWSSession's region
+func (p *EC2Pricing) GetInstanceTypePricing(instanceType string, zones []string, days int) (*InstanceTypePricing, error) {
+	return p.GetInstanceTypePricingWithContext(context.Background(), instanceType, zones, days)
+}
+
+// GetInstanceTypePricingWithContext is like GetInstanceTypePricing but the Pricing API and spot-pricing-history api requests are
+// canceled when the context is done
+func (p *EC2Pricing) GetInstanceTypePricingWithContext(ctx context.Context, instanceType string, zones []string, days int) (*InstanceTypePricing, error) {
+	instanceTypePricing := &InstanceTypePricing{InstanceType: instanceType, OnDemandHourly: -1, SpotAvgHourly: -1}
+	var errs error
+	onDemandPrice, err := p.GetOndemandInstanceTypeCostWithContext(ctx, instanceType)
+	if err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("unable to retrieve the on-demand price of instance type %s: %w", instanceType, err))
+	} else {
+		instanceTypePricing.OnDemandHourly = onDemandPrice
+	}
+	spotResult, err := p.GetSpotInstanceTypeNDayAvgCostDetailedWithContext(ctx, instanceType, zones, days)
+	if err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("unable to retrieve the spot price of instance type %s: %w", instanceType, err))
+	} else {
+		instanceTypePricing.SpotAvgHourly = spotResult.Avg
+		instanceTypePricing.SpotEarliestSample = spotResult.EarliestSample
+		instanceTypePricing.SpotLatestSample = spotResult.LatestSample
+	}
+	if instanceTypePricing.OnDemandHourly < 0 && instanceTypePricing.SpotAvgHourly < 0 {
+		return nil, errs
+	}
+	if spotPrice, ok := p.spotPriceInOndemandCurrency(instanceTypePricing.SpotAvgHourly); ok && instanceTypePricing.SpotAvgHourly >= 0 && instanceTypePricing.OnDemandHourly > 0 {
+		instanceTypePricing.SpotSavingsPct = (instanceTypePricing.OnDemandHourly - spotPrice) / instanceTypePricing.OnDemandHourly * 100
+	}
+	return instanceTypePricing, errs
+}
@@ -0,0 +1,119 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/pricing"
+	"go.uber.org/multierr"
+)
+
+func TestEnrichCosts(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large.json")
+	costs, err := ec2pricingClient.EnrichCosts([]string{"m5.large"}, []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() != nil, "Expected the on-demand cache to be hydrated")
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() != nil, "Expected the spot cache to be hydrated")
+
+	cost, ok := costs["m5.large"]
+	h.Assert(t, ok, "Expected the cost of m5.large")
+	h.Equals(t, float64(0.096), cost.OnDemand)
+	h.Equals(t, float64(0.04148843143974511), cost.SpotAvg)
+	h.Equals(t, map[string]float64{"us-east-1a": 0.04148843143974511}, cost.SpotPerAZ)
+	h.Assert(t, math.Abs(cost.SavingsPercent-(0.096-0.04148843143974511)/0.096*100) < 1e-9, "Unexpected savings percent %f", cost.SavingsPercent)
+}
+
+func TestEnrichCosts_NoSpotHistory(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large.json")
+	costs, err := ec2pricingClient.EnrichCosts([]string{"m5.large"}, []string{"us-west-2a"}, 30)
+	h.Ok(t, err)
+	cost := costs["m5.large"]
+	h.Equals(t, float64(0.096), cost.OnDemand)
+	h.Equals(t, float64(-1), cost.SpotAvg)
+	h.Equals(t, map[string]float64{}, cost.SpotPerAZ)
+	h.Equals(t, float64(0), cost.SavingsPercent)
+}
+
+func TestGetSpotSavingsOverOndemand(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large.json")
+	savings, err := ec2pricingClient.GetSpotSavingsOverOndemand("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(savings-(0.096-0.04148843143974511)/0.096*100) < 1e-9, "Unexpected savings percent %f", savings)
+
+	_, err = ec2pricingClient.GetSpotSavingsOverOndemand("m5.large", []string{"us-west-2a"}, 30)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotPriceHistory), "Expected ErrNoSpotPriceHistory, got %v", err)
+}
+
+func TestGetSpotSavingsOverOndemand_NoOndemandPrice(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large.json")
+	ec2pricingClient.PricingClient = mockedPricing{
+		GetProductsPagesRespPages: []pricing.GetProductsOutput{{PriceList: []aws.JSONValue{productsPriceDoc(t, "m5.large", "0.0960000000")}}},
+	}
+	// the -1 on-demand sentinel is not used to compute the savings
+	_, err := ec2pricingClient.GetSpotSavingsOverOndemand("z1d.large", []string{"us-east-1a"}, 30)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoOndemandPrice), "Expected ErrNoOndemandPrice, got %v", err)
+}
+
+func TestGetInstanceTypePricing(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large.json")
+	instanceTypePricing, err := ec2pricingClient.GetInstanceTypePricing("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, "m5.large", instanceTypePricing.InstanceType)
+	h.Equals(t, float64(0.096), instanceTypePricing.OnDemandHourly)
+	h.Equals(t, float64(0.04148843143974511), instanceTypePricing.SpotAvgHourly)
+	h.Assert(t, math.Abs(instanceTypePricing.SpotSavingsPct-(0.096-0.04148843143974511)/0.096*100) < 1e-9, "Unexpected savings percent %f", instanceTypePricing.SpotSavingsPct)
+	h.Assert(t, !instanceTypePricing.SpotEarliestSample.IsZero(), "Expected the earliest spot sample")
+	h.Assert(t, instanceTypePricing.SpotEarliestSample.Before(instanceTypePricing.SpotLatestSample), "Expected the earliest spot sample to be before the latest")
+	h.Assert(t, !instanceTypePricing.SpotLatestSample.After(fixtureClock()), "Expected the latest spot sample to be within the window")
+}
+
+func TestGetInstanceTypePricing_Errors(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large.json")
+	// the on-demand price is returned along with the spot error
+	instanceTypePricing, err := ec2pricingClient.GetInstanceTypePricing("m5.large", []string{"us-west-2a"}, 30)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotPriceHistory), "Expected ErrNoSpotPriceHistory, got %v", err)
+	h.Equals(t, float64(0.096), instanceTypePricing.OnDemandHourly)
+	h.Equals(t, float64(-1), instanceTypePricing.SpotAvgHourly)
+	h.Equals(t, float64(0), instanceTypePricing.SpotSavingsPct)
+
+	// both errors are combined when neither price can be retrieved
+	_, err = ec2pricingClient.GetInstanceTypePricing("m5.large", []string{"us-west-2a"}, 0)
+	h.Nok(t, err)
+	ec2pricingClient.PricingClient = mockedPricing{GetProductsPagesErr: errors.New("throttled")}
+	ec2pricingClient.AWSSession.Config.Region = aws.String("ap-southeast-2")
+	instanceTypePricing, err = ec2pricingClient.GetInstanceTypePricing("r5.large", []string{"us-west-2a"}, 30)
+	h.Assert(t, instanceTypePricing == nil, "Expected no pricing when neither price can be retrieved")
+	h.Equals(t, 2, len(multierr.Errors(err)))
+}
+
+func TestEnrichCostsWithContext_Canceled(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large.json")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	costs, err := ec2pricingClient.EnrichCostsWithContext(ctx, []string{"m5.large"}, []string{"us-east-1a"}, 30)
+	h.Nok(t, err)
+	h.Equals(t, 0, len(costs))
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() == nil, "Expected the on-demand cache to not be hydrated")
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() == nil, "Expected the spot cache to not be hydrated")
+	for _, lookupErr := range multierr.Errors(err) {
+		h.Assert(t, errors.Is(lookupErr, context.Canceled), "Expected the lookup to be canceled, got %v", lookupErr)
+	}
+}
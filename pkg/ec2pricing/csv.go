@@ -0,0 +1,82 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var spotPriceHistoryCSVHeader = []string{"timestamp", "availability_zone", "spot_price"}
+
+type zoneSpotPricingEntry struct {
+	zone string
+	SpotPricingEntry
+}
+
+// WriteSpotPriceHistoryCSV writes the raw spot price history for an instance type from the past N days to w in CSV format
+// Each row contains the sample timestamp (RFC3339), the availability zone, and the hourly spot price, sorted by timestamp in ascending order
+// Passing an empty list for availabilityZones will write the samples for all AZs in the current AWSSession's region
+func (p *EC2Pricing) WriteSpotPriceHistoryCSV(w io.Writer, instanceType string, availabilityZones []string, days int) error {
+	return p.WriteSpotPriceHistoryCSVWithContext(context.Background(), w, instanceType, availabilityZones, days)
+}
+
+// WriteSpotPriceHistoryCSVWithContext is like WriteSpotPriceHistoryCSV but the spot-pricing-history api requests are canceled when
+// the context is done
+func (p *EC2Pricing) WriteSpotPriceHistoryCSVWithContext(ctx context.Context, w io.Writer, instanceType string, availabilityZones []string, days int) error {
+	zoneToPriceEntries, _, err := p.getSpotPricingEntries(ctx, instanceType, days)
+	if err != nil {
+		return err
+	}
+	requestedZones := map[string]bool{}
+	for _, zone := range availabilityZones {
+		requestedZones[zone] = true
+	}
+	rows := []zoneSpotPricingEntry{}
+	for zone, priceEntries := range zoneToPriceEntries {
+		if len(requestedZones) != 0 && !requestedZones[zone] {
+			continue
+		}
+		for _, entry := range priceEntries {
+			rows = append(rows, zoneSpotPricingEntry{zone: zone, SpotPricingEntry: entry})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Timestamp.Equal(rows[j].Timestamp) {
+			return rows[i].zone < rows[j].zone
+		}
+		return rows[i].Timestamp.Before(rows[j].Timestamp)
+	})
+
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(spotPriceHistoryCSVHeader); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{
+			row.Timestamp.UTC().Format(time.RFC3339),
+			row.zone,
+			strconv.FormatFloat(row.SpotPrice, 'f', -1, 64),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
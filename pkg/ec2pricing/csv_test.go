@@ -0,0 +1,114 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func TestWriteSpotPriceHistoryCSV(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	buf := new(bytes.Buffer)
+	err := ec2pricingClient.WriteSpotPriceHistoryCSV(buf, "m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+
+	records, err := csv.NewReader(buf).ReadAll()
+	h.Ok(t, err)
+	h.Equals(t, []string{"timestamp", "availability_zone", "spot_price"}, records[0])
+	h.Equals(t, 49, len(records))
+	lastTimestamp := time.Time{}
+	for _, record := range records[1:] {
+		h.Equals(t, "us-east-1a", record[1])
+		timestamp, err := time.Parse(time.RFC3339, record[0])
+		h.Ok(t, err)
+		h.Assert(t, !timestamp.Before(lastTimestamp), "CSV rows should be sorted by timestamp in ascending order")
+		lastTimestamp = timestamp
+	}
+	h.Equals(t, []string{"2021-02-08T23:58:38Z", "us-east-1a", "0.0423"}, records[len(records)-1])
+}
+
+func TestWriteSpotPriceHistoryCSV_AllZones(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	buf := new(bytes.Buffer)
+	err := ec2pricingClient.WriteSpotPriceHistoryCSV(buf, "m5.large", []string{}, 30)
+	h.Ok(t, err)
+
+	records, err := csv.NewReader(buf).ReadAll()
+	h.Ok(t, err)
+	h.Equals(t, 251, len(records))
+}
+
+func TestWriteSpotPriceHistoryCSV_Error(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client: mockedPricing{
+			DescribeSpotPriceHistoryPagesErr: errors.New("error"),
+		},
+		AWSSession: &sess,
+	}
+	buf := new(bytes.Buffer)
+	err := ec2pricingClient.WriteSpotPriceHistoryCSV(buf, "m5.large", []string{}, 30)
+	h.Nok(t, err)
+	h.Equals(t, 0, buf.Len())
+}
+
+func TestWriteSpotPriceHistoryCSVWithContext_Canceled(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client:  setupMock(t, describeSpotPriceHistoryPages, "m5_large.json"),
+		AWSSession: &sess,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buf := new(bytes.Buffer)
+	err := ec2pricingClient.WriteSpotPriceHistoryCSVWithContext(ctx, buf, "m5.large", []string{}, 30)
+	h.Equals(t, context.Canceled, err)
+	h.Equals(t, 0, buf.Len())
+}
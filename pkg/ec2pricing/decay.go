@@ -0,0 +1,89 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// GetSpotInstanceTypeDecayWeightedAvgCost retrieves the spot price history from the past N days and averages it like
+// GetSpotInstanceTypeNDayAvgCost, except that each moment of the history is weighted by an exponential decay of its age
+// The weight of the price in effect at age a before the end of the window is 2^(-a / halfLife), so a price from one halfLife ago counts
+// half as much as the current price. Each sample's price is in effect from its timestamp until the next sample (or the end of the window),
+// which is the age range [a1, a2], so its weight is the integral of the decay over that range:
+//
+//	w = (halfLife / ln 2) * (2^(-a1 / halfLife) - 2^(-a2 / halfLife))
+//
+// The average of a zone is sum(price * w) / sum(w) and tends to the time weighted average as the halfLife grows. The zones' averages are
+// averaged with equal weight and converted to the SpotCurrency. Gaps in the history are not interpolated.
+// Passing an empty list for zones will average all AZs in the current AWSSession's region
+// An error is returned if the halfLife is not greater than 0, and an ErrNoSpotPriceHistory error is returned if none of the zones have spot price history
+func (p *EC2Pricing) GetSpotInstanceTypeDecayWeightedAvgCost(instanceType string, zones []string, days int, halfLife time.Duration) (float64, error) {
+	return p.GetSpotInstanceTypeDecayWeightedAvgCostWithContext(context.Background(), instanceType, zones, days, halfLife)
+}
+
+// GetSpotInstanceTypeDecayWeightedAvgCostWithContext is like GetSpotInstanceTypeDecayWeightedAvgCost but the spot-pricing-history
+// api requests are canceled when the context is done
+func (p *EC2Pricing) GetSpotInstanceTypeDecayWeightedAvgCostWithContext(ctx context.Context, instanceType string, zones []string, days int, halfLife time.Duration) (float64, error) {
+	if halfLife <= 0 {
+		return float64(-1), fmt.Errorf("the half-life of the decay must be greater than 0 but was %s", halfLife)
+	}
+	zoneToPriceEntries, endTime, err := p.getSpotPricingEntries(ctx, instanceType, days)
+	if err != nil {
+		return float64(-1), err
+	}
+	selectedZones := newZoneSelection(zones)
+	zoneAvgSum := float64(0)
+	zoneCount := 0
+	for _, zone := range sortedZones(zoneToPriceEntries) {
+		if !selectedZones.isSelected(zone) || len(zoneToPriceEntries[zone]) == 0 {
+			continue
+		}
+		zoneAvgSum += decayWeightedAvg(zoneToPriceEntries[zone], endTime, halfLife)
+		zoneCount++
+	}
+	if zoneCount == 0 {
+		return float64(-1), fmt.Errorf("%w for instance type %s in zones %v", ErrNoSpotPriceHistory, instanceType, zones)
+	}
+	return zoneAvgSum / float64(zoneCount) * p.spotExchangeRate(), nil
+}
+
+// decayWeightedAvg returns the exponential decay weighted average of the spot price entries of a single zone
+// The constant halfLife / ln 2 factor of each weight is left out since it cancels out of the average
+func decayWeightedAvg(spotPriceEntries []SpotPricingEntry, endTime time.Time, halfLife time.Duration) float64 {
+	entries := sortSpotEntriesNewestFirst(spotPriceEntries)
+	if endTime.Before(entries[0].Timestamp) {
+		endTime = entries[0].Timestamp
+	}
+	decay := func(t time.Time) float64 {
+		return math.Exp2(-float64(endTime.Sub(t)) / float64(halfLife))
+	}
+	priceSum := float64(0)
+	weightSum := float64(0)
+	newerTime := endTime
+	for _, entry := range entries {
+		weight := decay(newerTime) - decay(entry.Timestamp)
+		priceSum += entry.SpotPrice * weight
+		weightSum += weight
+		newerTime = entry.Timestamp
+	}
+	if weightSum == 0 {
+		// every sample is at the end of the window, so the most recent price is in effect
+		return entries[0].SpotPrice
+	}
+	return priceSum / weightSum
+}
@@ -0,0 +1,88 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// spikePricing returns an EC2Pricing whose m5.large spot price in us-east-1a is 0.05 since 2021-01-15 except for a single day at 0.10,
+// and whose clock is at 2021-02-13
+func spikePricing(spikeStart time.Time) ec2pricing.EC2Pricing {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	sample := func(timestamp time.Time, price string) *ec2.SpotPrice {
+		return &ec2.SpotPrice{
+			AvailabilityZone:   aws.String("us-east-1a"),
+			InstanceType:       aws.String("m5.large"),
+			ProductDescription: aws.String("Linux/UNIX"),
+			SpotPrice:          aws.String(price),
+			Timestamp:          aws.Time(timestamp),
+		}
+	}
+	return ec2pricing.EC2Pricing{
+		Clock: func() time.Time { return time.Date(2021, 2, 13, 0, 0, 0, 0, time.UTC) },
+		EC2Client: mockedPricing{DescribeSpotPriceHistoryPagesResp: ec2.DescribeSpotPriceHistoryOutput{SpotPriceHistory: []*ec2.SpotPrice{
+			sample(time.Date(2021, 1, 15, 0, 0, 0, 0, time.UTC), "0.050000"),
+			sample(spikeStart, "0.100000"),
+			sample(spikeStart.Add(24*time.Hour), "0.050000"),
+		}}},
+		AWSSession: &sess,
+	}
+}
+
+func TestGetSpotInstanceTypeDecayWeightedAvgCost(t *testing.T) {
+	oldSpike := spikePricing(time.Date(2021, 1, 22, 0, 0, 0, 0, time.UTC))
+	recentSpike := spikePricing(time.Date(2021, 2, 11, 0, 0, 0, 0, time.UTC))
+
+	// the time weighted averages of the spikes are the same regardless of when they happened
+	oldAvg, err := oldSpike.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	recentAvg, err := recentSpike.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(oldAvg-recentAvg) < 1e-12, "Expected equal time weighted averages, got %f and %f", oldAvg, recentAvg)
+
+	halfLife := 7 * 24 * time.Hour
+	oldDecayAvg, err := oldSpike.GetSpotInstanceTypeDecayWeightedAvgCost("m5.large", []string{}, 30, halfLife)
+	h.Ok(t, err)
+	recentDecayAvg, err := recentSpike.GetSpotInstanceTypeDecayWeightedAvgCost("m5.large", []string{}, 30, halfLife)
+	h.Ok(t, err)
+	h.Assert(t, recentDecayAvg > recentAvg, "Expected the recent spike to raise the decay weighted average above %f, got %f", recentAvg, recentDecayAvg)
+	h.Assert(t, oldDecayAvg < oldAvg, "Expected the old spike to count less than in the time weighted average %f, got %f", oldAvg, oldDecayAvg)
+
+	// a long half-life weights the history nearly evenly
+	longDecayAvg, err := recentSpike.GetSpotInstanceTypeDecayWeightedAvgCost("m5.large", []string{}, 30, 100000*halfLife)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(longDecayAvg-recentAvg) < 1e-6, "Expected the time weighted average %f, got %f", recentAvg, longDecayAvg)
+}
+
+func TestGetSpotInstanceTypeDecayWeightedAvgCost_Errors(t *testing.T) {
+	ec2pricingClient := spikePricing(time.Date(2021, 2, 11, 0, 0, 0, 0, time.UTC))
+	_, err := ec2pricingClient.GetSpotInstanceTypeDecayWeightedAvgCost("m5.large", []string{}, 30, 0)
+	h.Nok(t, err)
+	_, err = ec2pricingClient.GetSpotInstanceTypeDecayWeightedAvgCost("m5.large", []string{"us-east-1b"}, 30, time.Hour)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotPriceHistory), "Expected ErrNoSpotPriceHistory, got %v", err)
+}
@@ -0,0 +1,54 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import "errors"
+
+// ErrDryRun is returned by getters which would have called an API when DryRun is set
+var ErrDryRun = errors.New("the API call was skipped because DryRun is set")
+
+// PlannedAPICall is an API call which was skipped because DryRun is set
+type PlannedAPICall struct {
+	// API is one of the APIs passed to an Observer (Example: APIGetProducts)
+	API string
+	// Input is the request input the API would have been called with, such as a *pricing.GetProductsInput, or the URL of the
+	// Spot Instance Advisor feed for APISpotAdvisor
+	Input interface{}
+}
+
+// PlannedAPICalls returns the API calls which were skipped because DryRun is set, in the order they would have been made
+// Paginated APIs are recorded once per call rather than once per page since the number of pages is not known without calling them
+func (p *EC2Pricing) PlannedAPICalls() []PlannedAPICall {
+	p.dryRunMu.Lock()
+	defer p.dryRunMu.Unlock()
+	plannedAPICalls := make([]PlannedAPICall, len(p.plannedAPICalls))
+	copy(plannedAPICalls, p.plannedAPICalls)
+	return plannedAPICalls
+}
+
+// planAPICall records the API call and logs it instead of making it when DryRun is set
+// It returns true if the call should be skipped
+func (p *EC2Pricing) planAPICall(api string, input interface{}) bool {
+	if !p.DryRun {
+		return false
+	}
+	if p.dryRunParent != nil {
+		return p.dryRunParent.planAPICall(api, input)
+	}
+	p.dryRunMu.Lock()
+	defer p.dryRunMu.Unlock()
+	p.plannedAPICalls = append(p.plannedAPICalls, PlannedAPICall{API: api, Input: input})
+	p.log().Infof("dry run: skipping the %s call with input %v", api, input)
+	return true
+}
@@ -0,0 +1,127 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/pricing"
+	"go.uber.org/multierr"
+)
+
+func TestDryRun(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	pricingMock.GetProductsPagesCalls = new(int)
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	spotInputs := []*ec2.DescribeSpotPriceHistoryInput{}
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &spotInputs
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:         fixtureClock,
+		PricingClient: pricingMock,
+		EC2Client:     ec2Mock,
+		AWSSession:    &sess,
+		DryRun:        true,
+	}
+
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() == nil, "The on-demand cache should not be hydrated in a dry run")
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() == nil, "The spot cache should not be hydrated in a dry run")
+
+	// m5.large is in the static price list, which should not hide the skipped call
+	_, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Assert(t, errors.Is(err, ec2pricing.ErrDryRun), "Expected ErrDryRun, got %v", err)
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 7)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrDryRun), "Expected ErrDryRun, got %v", err)
+
+	// none of the APIs are called
+	h.Equals(t, 0, *pricingMock.GetProductsPagesCalls)
+	h.Equals(t, 0, len(spotInputs))
+
+	planned := ec2pricingClient.PlannedAPICalls()
+	h.Equals(t, 5, len(planned))
+	// the on-demand cache is hydrated with one query for mac metal instances and one for the rest
+	for _, call := range planned[:2] {
+		h.Equals(t, ec2pricing.APIGetProducts, call.API)
+		productsInput := call.Input.(*pricing.GetProductsInput)
+		h.Equals(t, "US East (N. Virginia)", getProductsFilterValue(productsInput, "location"))
+		h.Equals(t, "", getProductsFilterValue(productsInput, "instanceType"))
+	}
+	h.Equals(t, "Linux", getProductsFilterValue(planned[0].Input.(*pricing.GetProductsInput), "operatingSystem"))
+	h.Equals(t, "MacOS", getProductsFilterValue(planned[1].Input.(*pricing.GetProductsInput), "operatingSystem"))
+
+	h.Equals(t, ec2pricing.APIDescribeSpotPriceHistory, planned[2].API)
+	hydrateInput := planned[2].Input.(*ec2.DescribeSpotPriceHistoryInput)
+	h.Assert(t, hydrateInput.InstanceTypes == nil, "Expected the spot cache to be hydrated for all instance types")
+	h.Equals(t, 30*24*time.Hour, hydrateInput.EndTime.Sub(*hydrateInput.StartTime))
+
+	h.Equals(t, ec2pricing.APIGetProducts, planned[3].API)
+	h.Equals(t, "m5.large", getProductsFilterValue(planned[3].Input.(*pricing.GetProductsInput), "instanceType"))
+
+	h.Equals(t, ec2pricing.APIDescribeSpotPriceHistory, planned[4].API)
+	spotInput := planned[4].Input.(*ec2.DescribeSpotPriceHistoryInput)
+	h.Equals(t, "m5.large", *spotInput.InstanceTypes[0])
+	h.Equals(t, 7*24*time.Hour, spotInput.EndTime.Sub(*spotInput.StartTime))
+
+	// the APIs are called once DryRun is unset
+	ec2pricingClient.DryRun = false
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+	h.Equals(t, 1, *pricingMock.GetProductsPagesCalls)
+	h.Equals(t, 5, len(ec2pricingClient.PlannedAPICalls()))
+}
+
+func TestDryRun_CheapestRegion(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	pricingMock.GetProductsPagesCalls = new(int)
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+		DryRun:        true,
+	}
+
+	_, _, err := ec2pricingClient.GetCheapestRegionForOndemand("m5.large", []string{"us-east-1", "us-west-2"})
+	h.Nok(t, err)
+	// each region's lookup is skipped and the combined error ends with the ErrNoOndemandPrice of the regions
+	errs := multierr.Errors(err)
+	h.Equals(t, 3, len(errs))
+	for _, regionErr := range errs[:2] {
+		h.Assert(t, errors.Is(regionErr, ec2pricing.ErrDryRun), "Expected ErrDryRun, got %v", regionErr)
+	}
+	h.Equals(t, 0, *pricingMock.GetProductsPagesCalls)
+
+	// the per-region lookups are recorded on the EC2Pricing they were made with
+	planned := ec2pricingClient.PlannedAPICalls()
+	h.Equals(t, 2, len(planned))
+	h.Equals(t, "US East (N. Virginia)", getProductsFilterValue(planned[0].Input.(*pricing.GetProductsInput), "location"))
+	h.Equals(t, "US West (Oregon)", getProductsFilterValue(planned[1].Input.(*pricing.GetProductsInput), "location"))
+}
@@ -1,39 +1,154 @@
 package ec2pricing
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"math"
+	"net/http"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"go.uber.org/multierr"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/pricing"
 	"github.com/aws/aws-sdk-go/service/pricing/pricingiface"
+	"github.com/aws/aws-sdk-go/service/savingsplans"
+	"github.com/aws/aws-sdk-go/service/savingsplans/savingsplansiface"
 )
 
 const (
 	defaultSpotDaysBack = 30
 	productDescription  = "Linux/UNIX (Amazon VPC)"
+	amazonVPCSuffix     = " (Amazon VPC)"
 	serviceCode         = "AmazonEC2"
+
+	defaultPricingEndpointRegion = "us-east-1"
+
+	defaultEmptyPriceListRetryDelay = 500 * time.Millisecond
+
+	// minSpotPriceHistoryPageSize and maxSpotPriceHistoryPageSize are the MaxResults DescribeSpotPriceHistory accepts
+	minSpotPriceHistoryPageSize = 5
+	maxSpotPriceHistoryPageSize = 1000
+
+	// usdCurrency is the currency the spot price history is reported in and the default currency of on-demand prices
+	usdCurrency = "USD"
 )
 
+// ErrNoOndemandPrice is returned when the Pricing API does not have an on-demand price for an instance type
+var ErrNoOndemandPrice = errors.New("no on-demand price found")
+
+// errEmptyPriceList signals that the Pricing API returned no price documents, which can happen transiently after a price change
+var errEmptyPriceList = errors.New("the Pricing API returned an empty price list")
+
+// pricingEndpointRegions are the only regions which host a Pricing API endpoint
+var pricingEndpointRegions = []string{"us-east-1", "ap-south-1"}
+
 // EC2Pricing is the public struct to interface with AWS pricing APIs
 type EC2Pricing struct {
-	PricingClient        pricingiface.PricingAPI
-	EC2Client            ec2iface.EC2API
-	AWSSession           *session.Session
-	onDemandCache        map[string]float64
-	spotCache            map[string]map[string][]spotPricingEntry
-	lastOnDemandCacheUTC *time.Time // Updated on successful cache write
-	lastSpotCacheUTC     *time.Time // Updated on successful cache write
+	PricingClient pricingiface.PricingAPI
+	EC2Client     ec2iface.EC2API
+	AWSSession    *session.Session
+	onDemandCache map[string]float64
+	// SavingsPlansClient retrieves savings plan rates, see GetSavingsPlanInstanceTypeRate
+	SavingsPlansClient savingsplansiface.SavingsPlansAPI
+	// onDemandPriceOverrides are caller provided on-demand prices which take precedence over the onDemandCache and the Pricing API
+	onDemandPriceOverrides map[string]float64
+	spotCache              map[string]map[string]map[string][]SpotPricingEntry // keyed by product description, instance type, and then zone
+	lastOnDemandCacheUTC   *time.Time                                          // Updated on successful cache write
+	lastSpotCacheUTC       *time.Time                                          // Updated on successful cache write
+	spotCacheEndTime       time.Time                                           // End of the spot price history window held in the spotCache
+	// spotCacheDays and spotCacheProductDescriptions are the parameters the spotCache was last hydrated with, to refresh it the same way
+	spotCacheDays                int
+	spotCacheProductDescriptions []string
+	// spotCacheInstanceTypes are the only instance types the spotCache holds when it was hydrated by HydrateSpotCacheForTypes,
+	// it is nil when the spotCache holds every instance type
+	spotCacheInstanceTypes []string
+	// cacheMu guards the caches, their timestamps, and the onDemandPriceOverrides so that lookups are safe to run concurrently with hydration
+	cacheMu sync.RWMutex
+	// refreshMu ensures only one lookup refreshes an expired cache at a time
+	refreshMu sync.Mutex
+	// operatingSystem is the operating system prices are retrieved for, see SetOperatingSystem
+	operatingSystem string
+	// spotProductDescriptionOverride replaces the operating system's spot product description when not empty, see SetSpotProductDescription
+	spotProductDescriptionOverride string
+	// tenancy is the tenancy on-demand prices are retrieved for, see SetTenancy
+	tenancy string
+	// capacityStatus is the capacity status on-demand prices are retrieved for, see SetCapacityStatus
+	capacityStatus string
+	// MaxCacheEntries bounds the number of instance types in the on-demand cache by evicting the least recently used entries
+	// Instance types looked up individually are added to the on-demand cache when it is bounded, and a zero value disables eviction
+	MaxCacheEntries int
+	// onDemandLRU is the order the on-demand cache entries were used in when the MaxCacheEntries is set
+	onDemandLRU onDemandCacheLRU
+	// CacheTTL is how long the on-demand and spot caches are used for after they are hydrated
+	// Lookups re-hydrate an expired cache before using it, and a zero value means the caches never expire
+	CacheTTL time.Duration
+	// pricingEndpointRegion is the region of the Pricing API endpoint the PricingClient is created in by New
+	pricingEndpointRegion string
+	// sdkClientConfig is the retry config the options passed to New apply to the SDK clients, see clientConfig
+	sdkClientConfig *aws.Config
+	// spotPriceHistoryPageSize is the MaxResults of each DescribeSpotPriceHistory page, the API default is used when 0
+	spotPriceHistoryPageSize int64
+	// ondemandCurrency is the currency on-demand prices are read in, see WithOndemandCurrency
+	ondemandCurrency string
+	// spotCurrency and spotUSDExchangeRate convert spot prices from USD, spot prices are not converted when the rate is 0
+	spotCurrency        string
+	spotUSDExchangeRate float64
+	// Clock returns the current time and is used as the end of the spot price history window
+	// time.Now is used when Clock is nil
+	Clock func() time.Time
+	// SpotGapThreshold is the duration between consecutive spot price samples above which the interval is reported as a gap
+	// A zero value disables gap detection
+	SpotGapThreshold time.Duration
+	// InterpolateSpotGaps linearly interpolates the spot price across detected gaps instead of carrying the older sample's price forward
+	InterpolateSpotGaps bool
+	// OfflineMode looks up on-demand prices which are not cached in the static price list bundled into the binary instead of the Pricing API
+	// The static price list is also used when the Pricing API returns an error, regardless of the OfflineMode
+	OfflineMode bool
+	// MinOndemandPrice is the floor of the on-demand prices parsed from the Pricing API, price documents with a price below it are skipped
+	// Prices of 0 are always skipped since the Pricing API lists them for new or misconfigured SKUs, which is the default when the floor is 0
+	MinOndemandPrice float64
+	// PricingThrottleMaxAttempts is how many times a Pricing API query is attempted when the Pricing API throttles it, and
+	// PricingThrottleBaseDelay is the base of the exponential backoff with jitter between the attempts (New defaults them to 3 and 250ms)
+	// A query is only attempted once when PricingThrottleMaxAttempts is less than 2
+	PricingThrottleMaxAttempts int
+	PricingThrottleBaseDelay   time.Duration
+	// EmptyPriceListRetryDelay is how long to wait before retrying an on-demand price lookup which returned an empty price list
+	EmptyPriceListRetryDelay time.Duration
+	// SpotInterruptionRate returns the fraction (0 to 1) of spot instances of the instance type in the availability zone which are
+	// interrupted in a month and is used to compute the BreakEvenSpotHours
+	SpotInterruptionRate func(instanceType string, availabilityZone string) (float64, error)
+	// SpotAdvisorURL is the URL of the Spot Instance Advisor interruption-rate feed, the public feed is used when empty
+	SpotAdvisorURL string
+	// SpotAdvisorHTTPClient fetches the Spot Instance Advisor feed, a client which times out after 30 seconds is used when it is nil
+	SpotAdvisorHTTPClient *http.Client
+	// spotAdvisorFeed is the interruption-rate feed fetched at lastSpotAdvisorUTC, both are guarded by the spotAdvisorMu
+	spotAdvisorFeed    *spotAdvisorFeed
+	lastSpotAdvisorUTC *time.Time
+	spotAdvisorMu      sync.Mutex
+	// DryRun records the API calls which lookups and hydration would make instead of making them, see PlannedAPICalls
+	// Hydration leaves the caches untouched and getters which would have called an API return an ErrDryRun error
+	DryRun bool
+	// plannedAPICalls are the API calls skipped because DryRun is set, they are guarded by the dryRunMu
+	plannedAPICalls []PlannedAPICall
+	dryRunMu        sync.Mutex
+	// dryRunParent is the EC2Pricing a per-region copy was made from, which its planned API calls are recorded on instead
+	dryRunParent *EC2Pricing
+	// logger receives diagnostic messages, see SetLogger
+	logger Logger
+	// optionWarnings are the warnings of the options passed to the constructor, see warnOption
+	optionWarnings []string
+	// observer receives cache and API call events, see SetObserver
+	observer Observer
 }
 
 // EC2PricingIface is the EC2Pricing interface mainly used to mock out ec2pricing during testing
@@ -48,149 +163,699 @@ type EC2PricingIface interface {
 	LastSpotCacheUTC() *time.Time
 }
 
-type spotPricingEntry struct {
+// SpotPricingEntry is a single sample of an instance type's spot price history in an availability zone
+// The SpotPrice is in USD and is in effect from the Timestamp until the next sample
+type SpotPricingEntry struct {
 	Timestamp time.Time
 	SpotPrice float64
 }
 
+// Price is the hourly price of an instance type
+type Price struct {
+	InstanceType  string
+	AmountPerHour float64
+	Currency      string
+	// Stale is true when the price was read from the static price list bundled into the binary, which may be out of date
+	Stale bool
+}
+
+// Option configures an EC2Pricing created with New
+type Option func(*EC2Pricing)
+
+// WithSpotPriceHistoryPageSize sets the number of results (MaxResults) requested in each page of spot price history
+// Smaller pages reduce the payload of each call while larger pages reduce the number of calls
+// Valid page sizes are 5 to 1000, which the API accepts, and a page size of 0 uses the API default
+// Page sizes outside of the range are clamped to it, and negative page sizes use the API default
+func WithSpotPriceHistoryPageSize(pageSize int64) Option {
+	return func(p *EC2Pricing) {
+		switch {
+		case pageSize < 0:
+			p.warnOption("the spot price history page size %d is negative, using the API default", pageSize)
+			pageSize = 0
+		case pageSize > 0 && pageSize < minSpotPriceHistoryPageSize:
+			p.warnOption("the spot price history page size %d is below the minimum, using %d", pageSize, minSpotPriceHistoryPageSize)
+			pageSize = minSpotPriceHistoryPageSize
+		case pageSize > maxSpotPriceHistoryPageSize:
+			p.warnOption("the spot price history page size %d is above the maximum, using %d", pageSize, maxSpotPriceHistoryPageSize)
+			pageSize = maxSpotPriceHistoryPageSize
+		}
+		p.spotPriceHistoryPageSize = pageSize
+	}
+}
+
+// WithSpotCurrency converts spot prices from USD to the currency using the exchange rate, the amount of the currency one USD buys
+// On-demand prices are still returned in the OndemandCurrency, so on-demand and spot prices should not be compared directly when a currency is set
+// A rate of 0 or less leaves spot prices in USD
+// Example: WithSpotCurrency("EUR", 0.92)
+func WithSpotCurrency(currency string, usdExchangeRate float64) Option {
+	return func(p *EC2Pricing) {
+		p.spotCurrency = currency
+		p.spotUSDExchangeRate = usdExchangeRate
+	}
+}
+
+// WithPricingEndpointRegion sets the region of the Pricing API endpoint the PricingClient is created in (Example: "ap-south-1")
+// The endpoint region only affects latency, prices are still retrieved for the region of the EC2 session
+// Regions which are not one of the SupportedPricingRegions are ignored and us-east-1 is used instead
+func WithPricingEndpointRegion(region string) Option {
+	return func(p *EC2Pricing) {
+		for _, endpointRegion := range pricingEndpointRegions {
+			if region == endpointRegion {
+				p.pricingEndpointRegion = region
+				return
+			}
+		}
+		p.warnOption("the Pricing API is not available in %s, using %s", region, defaultPricingEndpointRegion)
+	}
+}
+
+// WithOndemandCurrency reads on-demand prices in the currency from the Pricing API's price documents instead of USD (Example: "CNY")
+// Prices are not converted, so looking up an instance type whose price document does not list a price in the currency returns an error
+func WithOndemandCurrency(currency string) Option {
+	return func(p *EC2Pricing) {
+		p.ondemandCurrency = currency
+	}
+}
+
+// WithMaxRetries sets the maximum number of times the PricingClient, EC2Client, and SavingsPlansClient created by New retry a throttled or failed request
+func WithMaxRetries(maxRetries int) Option {
+	return func(p *EC2Pricing) {
+		p.clientConfig().WithMaxRetries(maxRetries)
+	}
+}
+
+// WithRetryer sets the retryer the PricingClient, EC2Client, and SavingsPlansClient created by New use to decide whether and when to retry a request
+// The retryer takes precedence over WithMaxRetries
+func WithRetryer(retryer request.Retryer) Option {
+	return func(p *EC2Pricing) {
+		request.WithRetryer(p.clientConfig(), retryer)
+	}
+}
+
+// clientConfig returns the config applied to the PricingClient, EC2Client, and SavingsPlansClient created by New
+func (p *EC2Pricing) clientConfig() *aws.Config {
+	if p.sdkClientConfig == nil {
+		p.sdkClientConfig = aws.NewConfig()
+	}
+	return p.sdkClientConfig
+}
+
 // New creates an instance of instance-selector EC2Pricing
-func New(sess *session.Session) *EC2Pricing {
-	return &EC2Pricing{
-		// use us-east-1 since pricing only has endpoints in us-east-1 and ap-south-1
-		PricingClient:        pricing.New(sess.Copy(aws.NewConfig().WithRegion("us-east-1"))),
-		EC2Client:            ec2.New(sess),
-		AWSSession:           sess,
-		lastOnDemandCacheUTC: nil,
-		lastSpotCacheUTC:     nil,
+func New(sess *session.Session, opts ...Option) *EC2Pricing {
+	return NewWithSessions(sess, sess, opts...)
+}
+
+// NewWithSessions creates an instance of instance-selector EC2Pricing which uses separate sessions for the EC2 and Pricing clients
+// This allows the Pricing API to be queried with credentials from a different account than spot price history is retrieved with
+// The ec2Session's region is the region being priced
+func NewWithSessions(ec2Session *session.Session, pricingSession *session.Session, opts ...Option) *EC2Pricing {
+	pricingClient := newEC2Pricing(ec2Session, opts)
+	pricingClient.EC2Client = ec2.New(ec2Session, pricingClient.clientConfig())
+	pricingClient.PricingClient = pricing.New(pricingSession.Copy(aws.NewConfig().WithRegion(pricingClient.pricingEndpointRegion)), pricingClient.clientConfig())
+	// savings plans only have a global endpoint in us-east-1
+	pricingClient.SavingsPlansClient = savingsplans.New(pricingSession.Copy(aws.NewConfig().WithRegion(defaultPricingEndpointRegion)), pricingClient.clientConfig())
+	return pricingClient
+}
+
+// NewFromClients creates an instance of instance-selector EC2Pricing which uses the pricingClient and ec2Client instead of creating them
+// from a session, such as to inject fakes in tests. The sess's region is the region being priced.
+// The SavingsPlansClient is not created, and WithMaxRetries and WithRetryer have no effect since the clients are already configured
+func NewFromClients(pricingClient pricingiface.PricingAPI, ec2Client ec2iface.EC2API, sess *session.Session, opts ...Option) *EC2Pricing {
+	ec2Pricing := newEC2Pricing(sess, opts)
+	ec2Pricing.PricingClient = pricingClient
+	ec2Pricing.EC2Client = ec2Client
+	return ec2Pricing
+}
+
+// newEC2Pricing creates an EC2Pricing with the defaults and the options applied but without any clients
+func newEC2Pricing(sess *session.Session, opts []Option) *EC2Pricing {
+	ec2Pricing := &EC2Pricing{
+		AWSSession:               sess,
+		lastOnDemandCacheUTC:     nil,
+		lastSpotCacheUTC:         nil,
+		EmptyPriceListRetryDelay: defaultEmptyPriceListRetryDelay,
+		// the Pricing API is rate limited more aggressively than the retries of the SDK clients handle
+		PricingThrottleMaxAttempts: defaultPricingThrottleMaxAttempts,
+		PricingThrottleBaseDelay:   defaultPricingThrottleBaseDelay,
+		// use us-east-1 by default since pricing only has endpoints in us-east-1 and ap-south-1
+		pricingEndpointRegion: defaultPricingEndpointRegion,
+	}
+	for _, opt := range opts {
+		opt(ec2Pricing)
+	}
+	// the warnings are logged once every option has been applied so that they reach the logger of WithLogger regardless of its position
+	for _, warning := range ec2Pricing.optionWarnings {
+		ec2Pricing.log().Warnf("%s", warning)
+	}
+	ec2Pricing.optionWarnings = nil
+	return ec2Pricing
+}
+
+// copyConfig copies the configuration of p onto dst, which is every field except the clients, the AWSSession, and the state of the
+// caches, the Spot Instance Advisor feed, and dry runs
+// Fields added to EC2Pricing should be copied here unless they are state, so that copies such as forRegion are configured the same way
+func (p *EC2Pricing) copyConfig(dst *EC2Pricing) {
+	dst.operatingSystem = p.operatingSystem
+	dst.spotProductDescriptionOverride = p.spotProductDescriptionOverride
+	dst.tenancy = p.tenancy
+	dst.capacityStatus = p.capacityStatus
+	dst.MaxCacheEntries = p.MaxCacheEntries
+	dst.CacheTTL = p.CacheTTL
+	dst.pricingEndpointRegion = p.pricingEndpointRegion
+	dst.sdkClientConfig = p.sdkClientConfig
+	dst.spotPriceHistoryPageSize = p.spotPriceHistoryPageSize
+	dst.ondemandCurrency = p.ondemandCurrency
+	dst.spotCurrency = p.spotCurrency
+	dst.spotUSDExchangeRate = p.spotUSDExchangeRate
+	dst.Clock = p.Clock
+	dst.SpotGapThreshold = p.SpotGapThreshold
+	dst.InterpolateSpotGaps = p.InterpolateSpotGaps
+	dst.OfflineMode = p.OfflineMode
+	dst.MinOndemandPrice = p.MinOndemandPrice
+	dst.PricingThrottleMaxAttempts = p.PricingThrottleMaxAttempts
+	dst.PricingThrottleBaseDelay = p.PricingThrottleBaseDelay
+	dst.EmptyPriceListRetryDelay = p.EmptyPriceListRetryDelay
+	dst.SpotInterruptionRate = p.SpotInterruptionRate
+	dst.SpotAdvisorURL = p.SpotAdvisorURL
+	dst.SpotAdvisorHTTPClient = p.SpotAdvisorHTTPClient
+	dst.DryRun = p.DryRun
+	dst.logger = p.logger
+	dst.observer = p.observer
+}
+
+// SupportedPricingRegions returns the regions which host a Pricing API endpoint
+// The Pricing API client must be created in one of these regions regardless of the region being priced
+func SupportedPricingRegions() []string {
+	regions := make([]string, len(pricingEndpointRegions))
+	copy(regions, pricingEndpointRegions)
+	return regions
+}
+
+// IsRegionPriceable returns true if on-demand prices for instance types in the region can be retrieved from the Pricing API
+// The Pricing API only lists prices for regions within the standard aws partition (not GovCloud or China)
+func IsRegionPriceable(region string) bool {
+	partitions := endpoints.DefaultResolver().(endpoints.EnumPartitions).Partitions()
+	for _, partition := range partitions {
+		if _, ok := partition.Regions()[region]; ok {
+			return partition.ID() == endpoints.AwsPartitionID
+		}
 	}
+	return false
+}
+
+// now returns the current time in UTC from the Clock
+func (p *EC2Pricing) now() time.Time {
+	if p.Clock != nil {
+		return p.Clock().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// OndemandCurrency returns the currency on-demand prices are returned in, which is USD by default
+func (p *EC2Pricing) OndemandCurrency() string {
+	if p.ondemandCurrency == "" {
+		return usdCurrency
+	}
+	return p.ondemandCurrency
+}
+
+// spotPriceInOndemandCurrency converts a spot price in the SpotCurrency to the OndemandCurrency so that the two can be compared
+// false is returned if the spot price cannot be converted because neither currency is USD and they are different
+func (p *EC2Pricing) spotPriceInOndemandCurrency(spotPrice float64) (float64, bool) {
+	switch p.OndemandCurrency() {
+	case p.SpotCurrency():
+		return spotPrice, true
+	case usdCurrency:
+		return spotPrice / p.spotExchangeRate(), true
+	}
+	return 0, false
+}
+
+// SpotCurrency returns the currency spot prices are returned in
+func (p *EC2Pricing) SpotCurrency() string {
+	if p.spotUSDExchangeRate <= 0 || p.spotCurrency == "" {
+		return usdCurrency
+	}
+	return p.spotCurrency
+}
+
+// spotExchangeRate returns the rate which USD spot prices are multiplied by to convert them to the SpotCurrency
+func (p *EC2Pricing) spotExchangeRate() float64 {
+	if p.SpotCurrency() == usdCurrency {
+		return 1
+	}
+	return p.spotUSDExchangeRate
 }
 
 // LastOnDemandCacheUTC returns the UTC timestamp when the onDemandCache was last refreshed
 // Returns nil if the onDemandCache has not been initialized
 func (p *EC2Pricing) LastOnDemandCacheUTC() *time.Time {
+	p.cacheMu.RLock()
+	defer p.cacheMu.RUnlock()
 	return p.lastOnDemandCacheUTC
 }
 
 // LastSpotCacheUTC returns the UTC timestamp when the spotCache was last refreshed
 // Returns nil if the spotCache has not been initialized
 func (p *EC2Pricing) LastSpotCacheUTC() *time.Time {
+	p.cacheMu.RLock()
+	defer p.cacheMu.RUnlock()
 	return p.lastSpotCacheUTC
 }
 
+// ClearCaches drops the on-demand and spot caches and the cached spot advisor feed to release their memory
+// LastOnDemandCacheUTC and LastSpotCacheUTC return nil afterwards, and subsequent getters query the APIs again until the caches are
+// hydrated again. Prices set with SetOndemandPriceOverride are kept since they are not cached prices.
+func (p *EC2Pricing) ClearCaches() {
+	p.cacheMu.Lock()
+	p.onDemandCache = nil
+	p.lastOnDemandCacheUTC = nil
+	p.onDemandLRU = onDemandCacheLRU{}
+	p.spotCache = nil
+	p.lastSpotCacheUTC = nil
+	p.spotCacheEndTime = time.Time{}
+	p.spotCacheDays = 0
+	p.spotCacheProductDescriptions = nil
+	p.spotCacheInstanceTypes = nil
+	p.cacheMu.Unlock()
+
+	p.spotAdvisorMu.Lock()
+	defer p.spotAdvisorMu.Unlock()
+	p.spotAdvisorFeed = nil
+	p.lastSpotAdvisorUTC = nil
+	p.log().Debugf("cleared the pricing caches")
+}
+
+// ListOndemandInstanceTypes returns the instance types in the on-demand cache sorted alpha-numerically
+// On-demand price overrides which are not cached are not included
+func (p *EC2Pricing) ListOndemandInstanceTypes() []string {
+	p.cacheMu.RLock()
+	instanceTypes := make([]string, 0, len(p.onDemandCache))
+	for instanceType := range p.onDemandCache {
+		instanceTypes = append(instanceTypes, instanceType)
+	}
+	p.cacheMu.RUnlock()
+	sort.Strings(instanceTypes)
+	return instanceTypes
+}
+
+// ListSpotInstanceTypes returns the instance types in the spot cache for any of the product descriptions it was hydrated with
+// sorted alpha-numerically
+func (p *EC2Pricing) ListSpotInstanceTypes() []string {
+	instanceTypes := []string{}
+	seen := map[string]bool{}
+	p.cacheMu.RLock()
+	for _, instanceTypeEntries := range p.spotCache {
+		for instanceType := range instanceTypeEntries {
+			if !seen[instanceType] {
+				seen[instanceType] = true
+				instanceTypes = append(instanceTypes, instanceType)
+			}
+		}
+	}
+	p.cacheMu.RUnlock()
+	sort.Strings(instanceTypes)
+	return instanceTypes
+}
+
 // GetSpotInstanceTypeNDayAvgCost retrieves the spot price history for a given AZ from the past N days and averages the price
+// An error is returned if days is not greater than 0
 // Passing an empty list for availabilityZones will retrieve avg cost for all AZs in the current AWSSession's region
 func (p *EC2Pricing) GetSpotInstanceTypeNDayAvgCost(instanceType string, availabilityZones []string, days int) (float64, error) {
-	endTime := time.Now().UTC()
-	startTime := endTime.Add(time.Hour * time.Duration(24*-1*days))
+	return p.GetSpotInstanceTypeNDayAvgCostWithContext(context.Background(), instanceType, availabilityZones, days)
+}
 
-	spotPriceHistInput := ec2.DescribeSpotPriceHistoryInput{
-		ProductDescriptions: []*string{aws.String(productDescription)},
-		StartTime:           &startTime,
-		EndTime:             &endTime,
-		InstanceTypes:       []*string{&instanceType},
+// GetSpotInstanceTypeAvgCost is like GetSpotInstanceTypeNDayAvgCost with the spot price history of the past 30 days
+func (p *EC2Pricing) GetSpotInstanceTypeAvgCost(instanceType string, availabilityZones []string) (float64, error) {
+	return p.GetSpotInstanceTypeNDayAvgCost(instanceType, availabilityZones, defaultSpotDaysBack)
+}
+
+// GetSpotInstanceTypeNDayAvgCostWithContext is like GetSpotInstanceTypeNDayAvgCost but the spot-pricing-history api request is
+// canceled when the context is done
+func (p *EC2Pricing) GetSpotInstanceTypeNDayAvgCostWithContext(ctx context.Context, instanceType string, availabilityZones []string, days int) (float64, error) {
+	result, err := p.GetSpotInstanceTypeNDayAvgCostDetailedWithContext(ctx, instanceType, availabilityZones, days)
+	if err != nil {
+		return float64(-1), err
 	}
-	zoneToPriceEntries := make(map[string][]spotPricingEntry)
+	return result.Avg, nil
+}
 
-	if _, ok := p.spotCache[instanceType]; !ok {
-		var processingErr error
-		errAPI := p.EC2Client.DescribeSpotPriceHistoryPages(&spotPriceHistInput, func(dspho *ec2.DescribeSpotPriceHistoryOutput, b bool) bool {
-			for _, history := range dspho.SpotPriceHistory {
-				var spotPrice float64
-				spotPrice, errParse := strconv.ParseFloat(*history.SpotPrice, 64)
-				if errParse != nil {
-					processingErr = multierr.Append(processingErr, errParse)
-					continue
+// getSpotPricingEntries retrieves the spot price history of the SpotProductDescription for an instance type from the past N days keyed by availability zone
+// along with the end time of the history window
+// The spotCache is used if it contains the instance type, otherwise the spot-pricing-history api is queried
+func (p *EC2Pricing) getSpotPricingEntries(ctx context.Context, instanceType string, days int) (map[string][]SpotPricingEntry, time.Time, error) {
+	spotProductDescription := p.SpotProductDescription()
+	productToZoneEntries, endTime, err := p.getSpotPricingEntriesByProduct(ctx, instanceType, []string{spotProductDescription}, days)
+	if err != nil {
+		return nil, endTime, err
+	}
+	return productToZoneEntries[spotProductDescription], endTime, nil
+}
+
+// getSpotPricingEntriesByProduct retrieves the spot price history for an instance type from the past N days keyed by
+// product description and then by availability zone, along with the end time of the history window
+// The spotCache is used if it contains the instance type for every product description and was hydrated with at least N days of
+// history, otherwise the spot-pricing-history api is queried once for all of the product descriptions
+// When the spotCache holds a longer history than N days, only the cached entries within the past N days of its window are returned
+func (p *EC2Pricing) getSpotPricingEntriesByProduct(ctx context.Context, instanceType string, productDescriptions []string, days int) (map[string]map[string][]SpotPricingEntry, time.Time, error) {
+	if err := validateSpotDays(days); err != nil {
+		return nil, time.Time{}, err
+	}
+	p.refreshExpiredSpotCache(ctx)
+	productToZoneEntries := make(map[string]map[string][]SpotPricingEntry)
+	isCached := true
+	p.cacheMu.RLock()
+	spotCacheEndTime := p.spotCacheEndTime
+	coversWindow := days <= p.spotCacheDays
+	windowStart := spotCacheEndTime.Add(time.Hour * time.Duration(24*-1*days))
+	filterWindow := days < p.spotCacheDays
+	for _, product := range productDescriptions {
+		cachedZoneEntries, ok := p.spotCache[product][instanceType]
+		if !ok || !coversWindow {
+			isCached = false
+			break
+		}
+		zoneToPriceEntries := make(map[string][]SpotPricingEntry)
+		for zone, priceEntries := range cachedZoneEntries {
+			if !filterWindow {
+				zoneToPriceEntries[zone] = append(zoneToPriceEntries[zone], priceEntries...)
+				continue
+			}
+			for _, priceEntry := range priceEntries {
+				if !priceEntry.Timestamp.Before(windowStart) {
+					zoneToPriceEntries[zone] = append(zoneToPriceEntries[zone], priceEntry)
 				}
-				zone := *history.AvailabilityZone
-				zoneToPriceEntries[zone] = append(zoneToPriceEntries[zone], spotPricingEntry{
-					Timestamp: *history.Timestamp,
-					SpotPrice: spotPrice,
-				})
 			}
-			return true
-		})
-		if errAPI != nil {
-			return float64(-1), errAPI
 		}
-		if processingErr != nil {
-			return float64(-1), processingErr
+		productToZoneEntries[product] = zoneToPriceEntries
+	}
+	p.cacheMu.RUnlock()
+	if isCached {
+		p.log().Debugf("spot price cache hit for instance type %s", instanceType)
+		p.observe().OnCacheHit(CacheKindSpot, instanceType)
+		return productToZoneEntries, spotCacheEndTime, nil
+	}
+	p.log().Debugf("spot price cache miss for instance type %s, querying the spot price history", instanceType)
+	p.observe().OnCacheMiss(CacheKindSpot, instanceType)
+	return p.querySpotPricingEntries(ctx, instanceType, productDescriptions, days)
+}
+
+// querySpotPricingEntries queries the spot-pricing-history api for the spot price history of an instance type from the past N days
+// keyed by product description and then by availability zone, along with the end time of the history window
+func (p *EC2Pricing) querySpotPricingEntries(ctx context.Context, instanceType string, productDescriptions []string, days int) (map[string]map[string][]SpotPricingEntry, time.Time, error) {
+	productToZoneEntries := make(map[string]map[string][]SpotPricingEntry)
+	for _, product := range productDescriptions {
+		productToZoneEntries[product] = make(map[string][]SpotPricingEntry)
+	}
+	endTime := p.now()
+	startTime := endTime.Add(time.Hour * time.Duration(24*-1*days))
+	spotPriceHistInput := ec2.DescribeSpotPriceHistoryInput{
+		ProductDescriptions: aws.StringSlice(productDescriptions),
+		StartTime:           &startTime,
+		EndTime:             &endTime,
+		InstanceTypes:       []*string{&instanceType},
+	}
+	p.setSpotPriceHistoryPageSize(&spotPriceHistInput)
+	if p.planAPICall(APIDescribeSpotPriceHistory, &spotPriceHistInput) {
+		return nil, endTime, fmt.Errorf("%w for the spot price history of instance type %s", ErrDryRun, instanceType)
+	}
+	var processingErr error
+	var errCtx error
+	apiCallStart := time.Now()
+	errAPI := p.EC2Client.DescribeSpotPriceHistoryPagesWithContext(ctx, &spotPriceHistInput, func(dspho *ec2.DescribeSpotPriceHistoryOutput, b bool) bool {
+		if err := ctx.Err(); err != nil {
+			errCtx = err
+			return false
 		}
-	} else {
-		for zone, priceEntries := range p.spotCache[instanceType] {
-			for _, entry := range priceEntries {
-				zoneToPriceEntries[zone] = append(zoneToPriceEntries[zone], spotPricingEntry{
-					Timestamp: entry.Timestamp,
-					SpotPrice: entry.SpotPrice,
-				})
+		for _, history := range dspho.SpotPriceHistory {
+			spotPrice, errParse := strconv.ParseFloat(*history.SpotPrice, 64)
+			if errParse != nil {
+				p.log().Warnf("unable to parse the spot price of instance type %s: %v", instanceType, errParse)
+				processingErr = multierr.Append(processingErr, errParse)
+				continue
+			}
+			product, ok := matchProductDescription(productDescriptions, history.ProductDescription)
+			if !ok {
+				continue
 			}
+			zone := *history.AvailabilityZone
+			productToZoneEntries[product][zone] = append(productToZoneEntries[product][zone], SpotPricingEntry{
+				Timestamp: *history.Timestamp,
+				SpotPrice: spotPrice,
+			})
 		}
+		return true
+	})
+	p.observe().OnAPICall(APIDescribeSpotPriceHistory, time.Since(apiCallStart))
+	if errAPI == nil {
+		errAPI = errCtx
 	}
+	if errAPI != nil {
+		p.log().Warnf("unable to retrieve the spot price history of instance type %s: %v", instanceType, errAPI)
+		return nil, endTime, errAPI
+	}
+	if processingErr != nil {
+		return nil, endTime, processingErr
+	}
+	return productToZoneEntries, endTime, nil
+}
 
-	aggregateZonePriceSum := float64(0)
-	numOfZones := 0
-	for zone, priceEntries := range zoneToPriceEntries {
-		if len(availabilityZones) != 0 {
-			if !strings.Contains(strings.Join(availabilityZones, " "), zone) {
-				continue
-			}
+// matchProductDescription returns which of the requested product descriptions a spot price history entry's product description belongs to
+// The spot-pricing-history api can return a product description without the " (Amazon VPC)" suffix of the requested one
+func matchProductDescription(productDescriptions []string, historyProductDescription *string) (string, bool) {
+	if historyProductDescription == nil {
+		if len(productDescriptions) == 1 {
+			return productDescriptions[0], true
 		}
-		numOfZones++
-		aggregateZonePriceSum += p.calculateSpotAggregate(priceEntries)
+		return "", false
 	}
+	for _, product := range productDescriptions {
+		if product == *historyProductDescription || strings.TrimSuffix(product, amazonVPCSuffix) == *historyProductDescription {
+			return product, true
+		}
+	}
+	return "", false
+}
+
+// setSpotPriceHistoryPageSize sets MaxResults on the spot price history input if a page size was configured
+func (p *EC2Pricing) setSpotPriceHistoryPageSize(spotPriceHistInput *ec2.DescribeSpotPriceHistoryInput) {
+	if p.spotPriceHistoryPageSize > 0 {
+		spotPriceHistInput.MaxResults = aws.Int64(p.spotPriceHistoryPageSize)
+	}
+}
+
+// TimeWeightedSpotAvg returns the time weighted average of the spot price entries of a single zone until now, which is the algorithm
+// GetSpotInstanceTypeNDayAvgCost uses, so spot price history from other sources can be averaged without an EC2Pricing client
+// The entries do not need to be sorted and are not modified. 0 is returned if there are no entries
+func TimeWeightedSpotAvg(entries []SpotPricingEntry) float64 {
+	return TimeWeightedSpotAvgUntil(entries, time.Now().UTC())
+}
 
-	return aggregateZonePriceSum / float64(numOfZones), nil
+// TimeWeightedSpotAvgUntil is like TimeWeightedSpotAvg but the window ends at the endTime rather than now
+// Each price is weighted by how long it was in effect, from its timestamp until the timestamp of the next entry, so the most recent price
+// is in effect until the endTime, or until its own timestamp if the endTime is earlier
+func TimeWeightedSpotAvgUntil(entries []SpotPricingEntry, endTime time.Time) float64 {
+	avg, _ := timeWeightedSpotAvg(entries, endTime, 0, false)
+	return avg
 }
 
-func (p *EC2Pricing) calculateSpotAggregate(spotPriceEntries []spotPricingEntry) float64 {
+// calculateSpotAggregate returns the time weighted average of the spot price entries for a single zone
+// along with any intervals between consecutive entries which exceed the SpotGapThreshold
+// Each price is weighted by how long it was in effect, so the most recent price covers the span from its timestamp to the endTime
+func (p *EC2Pricing) calculateSpotAggregate(spotPriceEntries []SpotPricingEntry, endTime time.Time) (float64, []SpotPriceGap) {
+	return timeWeightedSpotAvg(spotPriceEntries, endTime, p.SpotGapThreshold, p.InterpolateSpotGaps)
+}
+
+// timeWeightedSpotAvg returns the time weighted average of the spot price entries from the oldest entry until the endTime, or the
+// most recent entry if it is later, along with any intervals between consecutive entries which exceed the gapThreshold
+// The price of a gap is the midpoint of the prices around it when interpolateGaps is true, a gapThreshold of 0 disables gap detection
+func timeWeightedSpotAvg(spotPriceEntries []SpotPricingEntry, endTime time.Time, gapThreshold time.Duration, interpolateGaps bool) (float64, []SpotPriceGap) {
 	if len(spotPriceEntries) == 0 {
-		return 0.0
+		return 0.0, nil
 	}
-	// Sort slice by timestamp in decending order from the end time (most likely, now)
-	sort.Slice(spotPriceEntries, func(i, j int) bool {
-		return spotPriceEntries[i].Timestamp.After(spotPriceEntries[j].Timestamp)
-	})
+	// sorted in descending order back from the end time (most likely, now)
+	entries := sortSpotEntriesNewestFirst(spotPriceEntries)
 
-	endTime := spotPriceEntries[0].Timestamp
-	startTime := spotPriceEntries[len(spotPriceEntries)-1].Timestamp
+	if endTime.Before(entries[0].Timestamp) {
+		endTime = entries[0].Timestamp
+	}
+	startTime := entries[len(entries)-1].Timestamp
 	totalDuration := endTime.Sub(startTime).Minutes()
+	if totalDuration == 0 {
+		// every entry, including a single one, has the same timestamp at the end of the window, so the most recent price is in effect
+		return entries[0].SpotPrice, nil
+	}
 
+	var gaps []SpotPriceGap
 	priceSum := float64(0)
-	for i, entry := range spotPriceEntries {
-		duration := spotPriceEntries[int(math.Max(float64(i-1), 0))].Timestamp.Sub(entry.Timestamp).Minutes()
-		priceSum += duration * entry.SpotPrice
+	for i, entry := range entries {
+		if i == 0 {
+			// the most recent price is in effect until the end of the window
+			priceSum += endTime.Sub(entry.Timestamp).Minutes() * entry.SpotPrice
+			continue
+		}
+		newerEntry := entries[i-1]
+		interval := newerEntry.Timestamp.Sub(entry.Timestamp)
+		price := entry.SpotPrice
+		if gapThreshold > 0 && interval > gapThreshold {
+			gaps = append(gaps, SpotPriceGap{Start: entry.Timestamp, End: newerEntry.Timestamp})
+			if interpolateGaps {
+				price = (entry.SpotPrice + newerEntry.SpotPrice) / 2
+			}
+		}
+		priceSum += interval.Minutes() * price
+	}
+	return priceSum / totalDuration, gaps
+}
+
+// SetOndemandPriceOverride sets the on-demand hourly cost of an instance type, taking precedence over the price from the Pricing API.
+// This can be used to fill in prices of instance types which are missing from the pricing catalog.
+// Overrides are kept when the on-demand cache is hydrated.
+func (p *EC2Pricing) SetOndemandPriceOverride(instanceType string, price float64) {
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+	if p.onDemandPriceOverrides == nil {
+		p.onDemandPriceOverrides = map[string]float64{}
 	}
-	return priceSum / totalDuration
+	p.onDemandPriceOverrides[instanceType] = price
 }
 
 // GetOndemandInstanceTypeCost retrieves the on-demand hourly cost for the specified instance type
+// Prices set with SetOndemandPriceOverride are returned instead of the Pricing API's price
+// -1 is returned without an error if the Pricing API does not have a price for the instance type, see GetOndemandInstanceTypePrice
+// to tell the two apart
 func (p *EC2Pricing) GetOndemandInstanceTypeCost(instanceType string) (float64, error) {
+	return p.GetOndemandInstanceTypeCostWithContext(context.Background(), instanceType)
+}
+
+// GetOndemandInstanceTypeCostWithContext is like GetOndemandInstanceTypeCost but the Pricing API request, and the wait before retrying
+// an empty price list, are canceled when the context is done
+func (p *EC2Pricing) GetOndemandInstanceTypeCostWithContext(ctx context.Context, instanceType string) (float64, error) {
+	price, err := p.GetOndemandInstanceTypePriceWithContext(ctx, instanceType)
+	if errors.Is(err, ErrNoOndemandPrice) {
+		return -1, nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return price.AmountPerHour, nil
+}
+
+// GetOndemandInstanceTypePrice retrieves the on-demand hourly price for the specified instance type along with its currency
+// Prices set with SetOndemandPriceOverride are returned instead of the Pricing API's price
+// The price is read from the static price list bundled into the binary, and marked as Stale, in OfflineMode or when the Pricing API
+// returns an error for an instance type the static price list has
+// An ErrNoOndemandPrice error is returned if the Pricing API does not have a price for the instance type
+func (p *EC2Pricing) GetOndemandInstanceTypePrice(instanceType string) (*Price, error) {
+	return p.GetOndemandInstanceTypePriceWithContext(context.Background(), instanceType)
+}
+
+// GetOndemandInstanceTypePriceWithContext is like GetOndemandInstanceTypePrice but the Pricing API request, and the wait before retrying
+// an empty price list, are canceled when the context is done
+func (p *EC2Pricing) GetOndemandInstanceTypePriceWithContext(ctx context.Context, instanceType string) (*Price, error) {
+	p.cacheMu.RLock()
+	price, ok := p.onDemandPriceOverrides[instanceType]
+	p.cacheMu.RUnlock()
+	if ok {
+		p.log().Debugf("using the on-demand price override for instance type %s", instanceType)
+		return p.ondemandPrice(instanceType, price), nil
+	}
+	if !p.OfflineMode {
+		p.refreshExpiredOndemandCache(ctx)
+	}
 	// Check cache first and return it if available
-	if price, ok := p.onDemandCache[instanceType]; ok {
-		return price, nil
+	p.cacheMu.RLock()
+	price, ok = p.onDemandCache[instanceType]
+	p.cacheMu.RUnlock()
+	if ok {
+		p.log().Debugf("on-demand price cache hit for instance type %s", instanceType)
+		p.observe().OnCacheHit(CacheKindOnDemand, instanceType)
+		if p.MaxCacheEntries > 0 {
+			p.cacheMu.Lock()
+			p.touchOndemandCacheEntry(instanceType)
+			p.cacheMu.Unlock()
+		}
+		return p.ondemandPrice(instanceType, price), nil
+	}
+	p.log().Debugf("on-demand price cache miss for instance type %s, querying the Pricing API", instanceType)
+	p.observe().OnCacheMiss(CacheKindOnDemand, instanceType)
+	if p.OfflineMode {
+		if staticPrice, ok := p.staticOndemandPrice(instanceType); ok {
+			return staticPrice, nil
+		}
+		return nil, fmt.Errorf("%w for instance type %s in the static price list", ErrNoOndemandPrice, instanceType)
 	}
 
-	regionDescription := p.getRegionForPricingAPI()
-	// TODO: mac.metal instances cannot be found with the below filters
-	productInput := pricing.GetProductsInput{
-		ServiceCode: aws.String(serviceCode),
-		Filters: []*pricing.Filter{
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("ServiceCode"), Value: aws.String(serviceCode)},
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("operatingSystem"), Value: aws.String("linux")},
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("location"), Value: aws.String(regionDescription)},
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("capacitystatus"), Value: aws.String("used")},
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("preInstalledSw"), Value: aws.String("NA")},
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("tenancy"), Value: aws.String("shared")},
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("instanceType"), Value: aws.String(instanceType)},
-		},
-	}
-
-	pricePerUnitInUSD := float64(-1)
+	price, err := p.getOndemandInstanceTypeCost(ctx, instanceType)
+	if err == errEmptyPriceList {
+		// an empty price list may be transient while the catalog is updated, so retry once before treating it as not found
+		p.log().Infof("the Pricing API returned an empty price list for instance type %s, retrying in %s", instanceType, p.EmptyPriceListRetryDelay)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(p.EmptyPriceListRetryDelay):
+		}
+		price, err = p.getOndemandInstanceTypeCost(ctx, instanceType)
+	}
+	if err == errEmptyPriceList {
+		p.log().Warnf("no on-demand price was found for instance type %s", instanceType)
+		return nil, fmt.Errorf("%w for instance type %s", ErrNoOndemandPrice, instanceType)
+	}
+	if err != nil {
+		if staticPrice, ok := p.staticOndemandPrice(instanceType); ok && ctx.Err() == nil && !errors.Is(err, ErrDryRun) {
+			p.log().Warnf("unable to retrieve the on-demand price of instance type %s, using the static price list: %v", instanceType, err)
+			return staticPrice, nil
+		}
+		p.log().Warnf("unable to retrieve the on-demand price of instance type %s: %v", instanceType, err)
+		return nil, err
+	}
+	if p.MaxCacheEntries > 0 {
+		p.cacheMu.Lock()
+		p.insertOndemandCacheEntry(instanceType, price)
+		p.cacheMu.Unlock()
+	}
+	return p.ondemandPrice(instanceType, price), nil
+}
+
+// ondemandPrice returns the Price of an on-demand hourly cost in the OndemandCurrency
+func (p *EC2Pricing) ondemandPrice(instanceType string, amountPerHour float64) *Price {
+	return &Price{InstanceType: instanceType, AmountPerHour: amountPerHour, Currency: p.OndemandCurrency()}
+}
+
+// getOndemandInstanceTypeCost queries the Pricing API for the on-demand hourly cost of the specified instance type
+// errEmptyPriceList is returned if the Pricing API did not return any price documents
+func (p *EC2Pricing) getOndemandInstanceTypeCost(ctx context.Context, instanceType string) (float64, error) {
+	productInput, err := p.getOndemandProductsInput(instanceType)
+	if err != nil {
+		return -1, err
+	}
+	return p.queryOndemandInstanceTypeCost(ctx, instanceType, productInput)
+}
+
+// queryOndemandInstanceTypeCost queries the Pricing API with the products input for the on-demand hourly cost of the specified instance type
+func (p *EC2Pricing) queryOndemandInstanceTypeCost(ctx context.Context, instanceType string, productInput pricing.GetProductsInput) (float64, error) {
+	if p.planAPICall(APIGetProducts, &productInput) {
+		return -1, fmt.Errorf("%w for the on-demand price of instance type %s", ErrDryRun, instanceType)
+	}
+	pricePerUnit := float64(-1)
+	priceDocCount := 0
 	var processingErr error
-	errAPI := p.PricingClient.GetProductsPages(&productInput, func(pricingOutput *pricing.GetProductsOutput, nextPage bool) bool {
+	var errCtx error
+	apiCallStart := time.Now()
+	errAPI := p.getProductsPages(ctx, &productInput, func(pricingOutput *pricing.GetProductsOutput, nextPage bool) bool {
+		if err := ctx.Err(); err != nil {
+			errCtx = err
+			return false
+		}
 		var errParse error
 		for _, priceDoc := range pricingOutput.PriceList {
-			_, pricePerUnitInUSD, errParse = parseOndemandUnitPrice(priceDoc)
+			priceDocCount++
+			_, pricePerUnit, errParse = parseOndemandUnitPrice(priceDoc, p.OndemandCurrency())
+			if errParse == nil {
+				errParse = p.validateOndemandPrice(instanceType, pricePerUnit)
+			}
 			if errParse != nil {
+				p.log().Warnf("unable to parse an on-demand price document of instance type %s: %v", instanceType, errParse)
 				processingErr = multierr.Append(processingErr, errParse)
 				// keep going through pages if we can't parse the pricing doc
 				return true
@@ -198,158 +863,416 @@ func (p *EC2Pricing) GetOndemandInstanceTypeCost(instanceType string) (float64,
 		}
 		return false
 	})
+	p.observe().OnAPICall(APIGetProducts, time.Since(apiCallStart))
+	if errAPI == nil {
+		errAPI = errCtx
+	}
 	if errAPI != nil {
 		return -1, errAPI
 	}
 	if processingErr != nil {
 		return -1, processingErr
 	}
-	return pricePerUnitInUSD, nil
+	if priceDocCount == 0 {
+		return -1, errEmptyPriceList
+	}
+	return pricePerUnit, nil
+}
+
+// getOndemandProductsInput returns the Pricing API query for the on-demand products of the specified instance type in the current AWSSession's region
+func (p *EC2Pricing) getOndemandProductsInput(instanceType string) (pricing.GetProductsInput, error) {
+	regionDescription, err := p.getRegionForPricingAPI()
+	if err != nil {
+		return pricing.GetProductsInput{}, err
+	}
+	filters := p.ondemandProductFilters(regionDescription, isMacMetalInstanceType(instanceType))
+	filters = append(filters, &pricing.Filter{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("instanceType"), Value: aws.String(instanceType)})
+	return pricing.GetProductsInput{
+		ServiceCode: aws.String(serviceCode),
+		Filters:     filters,
+	}, nil
+}
+
+// validateOndemandPrice returns an error if the on-demand price parsed from the Pricing API is 0 or below the MinOndemandPrice
+func (p *EC2Pricing) validateOndemandPrice(instanceType string, price float64) error {
+	if price <= 0 {
+		return fmt.Errorf("the on-demand price of instance type %s is %f", instanceType, price)
+	}
+	if price < p.MinOndemandPrice {
+		return fmt.Errorf("the on-demand price %f of instance type %s is below the minimum price %f", price, instanceType, p.MinOndemandPrice)
+	}
+	return nil
+}
+
+// ondemandProductFilters returns the Pricing API filters matching the on-demand products of the OperatingSystem and Tenancy in the region
+// mac metal instances can only run macOS on Dedicated Hosts, so their products are instead matched regardless of the OperatingSystem
+// and Tenancy, see macMetalOperatingSystem
+func (p *EC2Pricing) ondemandProductFilters(regionDescription string, macMetal bool) []*pricing.Filter {
+	operatingSystem, tenancy, capacityStatus := p.operatingSystemPricing().pricingAPIValue, p.pricingAPITenancy(), p.pricingAPICapacityStatus()
+	if macMetal {
+		operatingSystem, tenancy, capacityStatus = macMetalOperatingSystem, macMetalTenancy, macMetalCapacityStatus
+	}
+	return []*pricing.Filter{
+		{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("ServiceCode"), Value: aws.String(serviceCode)},
+		{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("operatingSystem"), Value: aws.String(operatingSystem)},
+		{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("location"), Value: aws.String(regionDescription)},
+		{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("capacitystatus"), Value: aws.String(capacityStatus)},
+		{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("preInstalledSw"), Value: aws.String("NA")},
+		{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("tenancy"), Value: aws.String(tenancy)},
+	}
 }
 
 // HydrateSpotCache makes a bulk request to the spot-pricing-history api to retrieve all instance type pricing and stores them in a local cache
 // If HydrateSpotCache is called more than once, the cache will be fully refreshed
-// There is no TTL on cache entries
+// A *PartialHydrationError is returned along with the cache if some of the samples could not be parsed
+// An error is returned if days is not greater than 0
+// Cache entries expire after the CacheTTL, they never expire by default
 // You'll only want to use this if you don't mind a long startup time (around 30 seconds) and will query the cache often after that.
 func (p *EC2Pricing) HydrateSpotCache(days int) error {
-	newCache := make(map[string]map[string][]spotPricingEntry)
+	return p.HydrateSpotCacheWithContext(context.Background(), days)
+}
+
+// HydrateDefaultSpotCache is like HydrateSpotCache with the spot price history of the past 30 days
+func (p *EC2Pricing) HydrateDefaultSpotCache() error {
+	return p.HydrateSpotCache(defaultSpotDaysBack)
+}
+
+// HydrateSpotCacheWithContext is like HydrateSpotCache but the spot-pricing-history api requests are canceled when the context is done
+// The existing cache is kept if hydration is canceled, and no further pages are retrieved once the context is done
+func (p *EC2Pricing) HydrateSpotCacheWithContext(ctx context.Context, days int) error {
+	return p.HydrateSpotCacheForProductDescriptionsWithContext(ctx, days, []string{p.SpotProductDescription()})
+}
+
+// HydrateSpotCacheForProductDescriptions is like HydrateSpotCache but caches the spot price history of each of the product descriptions
+// (Example: "Linux/UNIX (Amazon VPC)" or "Red Hat Enterprise Linux (Amazon VPC)") so that they can be compared without further requests
+func (p *EC2Pricing) HydrateSpotCacheForProductDescriptions(days int, productDescriptions []string) error {
+	return p.HydrateSpotCacheForProductDescriptionsWithContext(context.Background(), days, productDescriptions)
+}
+
+// HydrateSpotCacheForProductDescriptionsWithContext is like HydrateSpotCacheForProductDescriptions but the spot-pricing-history api
+// requests are canceled when the context is done
+func (p *EC2Pricing) HydrateSpotCacheForProductDescriptionsWithContext(ctx context.Context, days int, productDescriptions []string) error {
+	if err := validateSpotDays(days); err != nil {
+		return err
+	}
+	if err := validateSpotProductDescriptions(productDescriptions); err != nil {
+		return err
+	}
+	return p.hydrateSpotCache(ctx, days, productDescriptions, nil)
+}
+
+// HydrateSpotCacheForTypes is like HydrateSpotCache but only retrieves the spot price history of the instance types, which takes
+// far fewer pages than the history of every instance type
+// The instance types are merged into an existing spot cache, replacing their entries and leaving the rest of the cache, its window,
+// and the LastSpotCacheUTC untouched, so the days should match the days the cache was hydrated with. The history is retrieved for
+// the product descriptions the cache was hydrated with, or for the SpotProductDescription if the cache has not been hydrated yet,
+// in which case the cache only holds the instance types and is refreshed with only them once it expires.
+func (p *EC2Pricing) HydrateSpotCacheForTypes(instanceTypes []string, days int) error {
+	return p.HydrateSpotCacheForTypesWithContext(context.Background(), instanceTypes, days)
+}
+
+// HydrateSpotCacheForTypesWithContext is like HydrateSpotCacheForTypes but the spot-pricing-history api requests are canceled when
+// the context is done
+func (p *EC2Pricing) HydrateSpotCacheForTypesWithContext(ctx context.Context, instanceTypes []string, days int) error {
+	if err := validateSpotDays(days); err != nil {
+		return err
+	}
+	if len(instanceTypes) == 0 {
+		return fmt.Errorf("at least one instance type must be specified")
+	}
+	if p.LastSpotCacheUTC() == nil {
+		return p.hydrateSpotCache(ctx, days, []string{p.SpotProductDescription()}, instanceTypes)
+	}
+	p.cacheMu.RLock()
+	productDescriptions := p.spotCacheProductDescriptions
+	p.cacheMu.RUnlock()
+	newCache, _, err := p.querySpotCache(ctx, days, productDescriptions, instanceTypes)
+	if newCache == nil {
+		return err
+	}
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+	if p.spotCache == nil {
+		p.spotCache = make(map[string]map[string]map[string][]SpotPricingEntry)
+	}
+	for product, instanceTypeEntries := range newCache {
+		if p.spotCache[product] == nil {
+			p.spotCache[product] = make(map[string]map[string][]SpotPricingEntry)
+		}
+		for _, instanceType := range instanceTypes {
+			// instance types without history are removed so that looking them up queries the api instead of finding no zones
+			if zoneToPriceEntries, ok := instanceTypeEntries[instanceType]; ok {
+				p.spotCache[product][instanceType] = zoneToPriceEntries
+			} else {
+				delete(p.spotCache[product], instanceType)
+			}
+		}
+	}
+	// a cache of every instance type stays unrestricted when it is refreshed
+	if p.spotCacheInstanceTypes != nil {
+		p.spotCacheInstanceTypes = mergeInstanceTypes(p.spotCacheInstanceTypes, instanceTypes)
+	}
+	p.log().Infof("merged %d days of spot price history of %d instance types into the spot price cache", days, len(instanceTypes))
+	return err
+}
+
+// mergeInstanceTypes returns the sorted union of the instance types
+func mergeInstanceTypes(instanceTypes []string, otherInstanceTypes []string) []string {
+	merged := []string{}
+	seen := map[string]bool{}
+	for _, instanceType := range append(append([]string{}, instanceTypes...), otherInstanceTypes...) {
+		if !seen[instanceType] {
+			seen[instanceType] = true
+			merged = append(merged, instanceType)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
 
-	endTime := time.Now().UTC()
+// hydrateSpotCache replaces the spot cache with the past N days of spot price history of the product descriptions
+// The history is restricted to the instance types unless they are nil, in which case every instance type is cached
+func (p *EC2Pricing) hydrateSpotCache(ctx context.Context, days int, productDescriptions []string, instanceTypes []string) error {
+	newCache, endTime, err := p.querySpotCache(ctx, days, productDescriptions, instanceTypes)
+	if newCache == nil {
+		return err
+	}
+	p.log().Infof("hydrated the spot price cache with %d days of spot price history", days)
+	cTime := p.now()
+	p.cacheMu.Lock()
+	p.spotCache = newCache
+	p.spotCacheEndTime = endTime
+	p.spotCacheDays = days
+	p.spotCacheProductDescriptions = productDescriptions
+	p.spotCacheInstanceTypes = instanceTypes
+	p.lastSpotCacheUTC = &cTime
+	p.cacheMu.Unlock()
+	return err
+}
+
+// querySpotCache queries the spot-pricing-history api for the past N days of spot price history of the product descriptions keyed
+// by product description, instance type, and then zone, along with the end time of the history window
+// The history is restricted to the instance types unless they are nil. The returned cache is nil if the history could not be retrieved
+// or the query was skipped because DryRun is set, otherwise the error is a *PartialHydrationError if some samples could not be parsed.
+func (p *EC2Pricing) querySpotCache(ctx context.Context, days int, productDescriptions []string, instanceTypes []string) (map[string]map[string]map[string][]SpotPricingEntry, time.Time, error) {
+	newCache := make(map[string]map[string]map[string][]SpotPricingEntry)
+	for _, product := range productDescriptions {
+		newCache[product] = make(map[string]map[string][]SpotPricingEntry)
+	}
+
+	endTime := p.now()
 	startTime := endTime.Add(time.Hour * time.Duration(24*-1*days))
 	spotPriceHistInput := ec2.DescribeSpotPriceHistoryInput{
-		ProductDescriptions: []*string{aws.String(productDescription)},
+		ProductDescriptions: aws.StringSlice(productDescriptions),
 		StartTime:           &startTime,
 		EndTime:             &endTime,
 	}
+	if instanceTypes != nil {
+		spotPriceHistInput.InstanceTypes = aws.StringSlice(instanceTypes)
+	}
+	p.setSpotPriceHistoryPageSize(&spotPriceHistInput)
+	if p.planAPICall(APIDescribeSpotPriceHistory, &spotPriceHistInput) {
+		return nil, endTime, nil
+	}
 	var processingErr error
-	errAPI := p.EC2Client.DescribeSpotPriceHistoryPages(&spotPriceHistInput, func(dspho *ec2.DescribeSpotPriceHistoryOutput, b bool) bool {
+	var errCtx error
+	apiCallStart := time.Now()
+	errAPI := p.EC2Client.DescribeSpotPriceHistoryPagesWithContext(ctx, &spotPriceHistInput, func(dspho *ec2.DescribeSpotPriceHistoryOutput, b bool) bool {
+		if err := ctx.Err(); err != nil {
+			errCtx = err
+			return false
+		}
 		for _, history := range dspho.SpotPriceHistory {
 			spotPrice, errFloat := strconv.ParseFloat(*history.SpotPrice, 64)
 			if errFloat != nil {
+				p.log().Warnf("unable to parse the spot price of instance type %s: %v", aws.StringValue(history.InstanceType), errFloat)
 				processingErr = multierr.Append(processingErr, errFloat)
 				continue
 			}
+			product, ok := matchProductDescription(productDescriptions, history.ProductDescription)
+			if !ok {
+				continue
+			}
 			instanceType := *history.InstanceType
 			zone := *history.AvailabilityZone
-			if _, ok := newCache[instanceType]; !ok {
-				newCache[instanceType] = make(map[string][]spotPricingEntry)
+			if _, ok := newCache[product][instanceType]; !ok {
+				newCache[product][instanceType] = make(map[string][]SpotPricingEntry)
 			}
-			newCache[instanceType][zone] = append(newCache[instanceType][zone], spotPricingEntry{
+			newCache[product][instanceType][zone] = append(newCache[product][instanceType][zone], SpotPricingEntry{
 				Timestamp: *history.Timestamp,
 				SpotPrice: spotPrice,
 			})
 		}
 		return true
 	})
+	p.observe().OnAPICall(APIDescribeSpotPriceHistory, time.Since(apiCallStart))
+	if errAPI == nil {
+		errAPI = errCtx
+	}
 	if errAPI != nil {
-		return errAPI
+		p.log().Warnf("unable to hydrate the spot price cache: %v", errAPI)
+		return nil, endTime, errAPI
 	}
-	cTime := time.Now().UTC()
-	p.spotCache = newCache
-	p.lastSpotCacheUTC = &cTime
-	return processingErr
+	return newCache, endTime, partialHydrationError(CacheKindSpot, processingErr)
 }
 
 // HydrateOndemandCache makes a bulk request to the pricing api to retrieve all instance type pricing and stores them in a local cache
 // If HydrateOndemandCache is called more than once, the cache will be fully refreshed
-// There is no TTL on cache entries
+// A *PartialHydrationError is returned along with the cache if some of the price documents could not be parsed
+// Cache entries expire after the CacheTTL, they never expire by default
 func (p *EC2Pricing) HydrateOndemandCache() error {
+	return p.HydrateOndemandCacheWithContext(context.Background())
+}
+
+// HydrateOndemandCacheWithContext is like HydrateOndemandCache but the Pricing API requests are canceled when the context is done
+// The existing cache is kept if hydration is canceled, and no further pages are retrieved once the context is done
+func (p *EC2Pricing) HydrateOndemandCacheWithContext(ctx context.Context) error {
 	newOnDemandCache := make(map[string]float64)
 
-	regionDescription := p.getRegionForPricingAPI()
-	productInput := pricing.GetProductsInput{
-		ServiceCode: aws.String(serviceCode),
-		Filters: []*pricing.Filter{
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("ServiceCode"), Value: aws.String(serviceCode)},
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("operatingSystem"), Value: aws.String("linux")},
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("location"), Value: aws.String(regionDescription)},
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("capacitystatus"), Value: aws.String("used")},
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("preInstalledSw"), Value: aws.String("NA")},
-			{Type: aws.String(pricing.FilterTypeTermMatch), Field: aws.String("tenancy"), Value: aws.String("shared")},
-		},
+	regionDescription, err := p.getRegionForPricingAPI()
+	if err != nil {
+		p.log().Warnf("unable to hydrate the on-demand price cache: %v", err)
+		return err
 	}
 	var processingErr error
-	errAPI := p.PricingClient.GetProductsPages(&productInput, func(pricingOutput *pricing.GetProductsOutput, nextPage bool) bool {
-		for _, priceDoc := range pricingOutput.PriceList {
-			instanceTypeName, price, errParse := parseOndemandUnitPrice(priceDoc)
-			if errParse != nil {
-				processingErr = multierr.Append(processingErr, errParse)
-				continue
+	// mac metal products do not match the filters of the other instance types so they are queried separately
+	for _, macMetal := range []bool{false, true} {
+		productInput := pricing.GetProductsInput{
+			ServiceCode: aws.String(serviceCode),
+			Filters:     p.ondemandProductFilters(regionDescription, macMetal),
+		}
+		if p.planAPICall(APIGetProducts, &productInput) {
+			continue
+		}
+		var errCtx error
+		apiCallStart := time.Now()
+		errAPI := p.getProductsPages(ctx, &productInput, func(pricingOutput *pricing.GetProductsOutput, nextPage bool) bool {
+			if err := ctx.Err(); err != nil {
+				errCtx = err
+				return false
+			}
+			for _, priceDoc := range pricingOutput.PriceList {
+				instanceTypeName, price, errParse := parseOndemandUnitPrice(priceDoc, p.OndemandCurrency())
+				if errParse != nil {
+					p.log().Warnf("unable to parse an on-demand price document: %v", errParse)
+					processingErr = multierr.Append(processingErr, errParse)
+					continue
+				}
+				if isMacMetalInstanceType(instanceTypeName) != macMetal {
+					continue
+				}
+				if errPrice := p.validateOndemandPrice(instanceTypeName, price); errPrice != nil {
+					p.log().Warnf("skipping an on-demand price document: %v", errPrice)
+					processingErr = multierr.Append(processingErr, errPrice)
+					continue
+				}
+				newOnDemandCache[instanceTypeName] = price
 			}
-			newOnDemandCache[instanceTypeName] = price
+			return true
+		})
+		p.observe().OnAPICall(APIGetProducts, time.Since(apiCallStart))
+		if errAPI == nil {
+			errAPI = errCtx
 		}
-		return true
-	})
-	if errAPI != nil {
-		return errAPI
+		if errAPI != nil {
+			p.log().Warnf("unable to hydrate the on-demand price cache: %v", errAPI)
+			return errAPI
+		}
+	}
+	if p.DryRun {
+		return nil
 	}
-	cTime := time.Now().UTC()
+	p.log().Infof("hydrated the on-demand price cache with %d instance types", len(newOnDemandCache))
+	cTime := p.now()
+	p.cacheMu.Lock()
 	p.onDemandCache = newOnDemandCache
 	p.lastOnDemandCacheUTC = &cTime
-	return processingErr
+	p.evictOndemandCacheEntries()
+	p.cacheMu.Unlock()
+	return partialHydrationError(CacheKindOnDemand, processingErr)
+}
+
+// HydrateCaches hydrates the on-demand cache and the spot cache with the past N days of spot price history concurrently
+// The two hydrations only write their own cache and timestamp, so a failure of one does not prevent the other from being hydrated
+// The errors of both hydrations are combined into the returned error
+func (p *EC2Pricing) HydrateCaches(days int) error {
+	return p.HydrateCachesWithContext(context.Background(), days)
+}
+
+// HydrateCachesWithContext is like HydrateCaches but the Pricing API and spot-pricing-history api requests are canceled when the
+// context is done
+func (p *EC2Pricing) HydrateCachesWithContext(ctx context.Context, days int) error {
+	var onDemandErr, spotErr error
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		onDemandErr = p.HydrateOndemandCacheWithContext(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		spotErr = p.HydrateSpotCacheWithContext(ctx, days)
+	}()
+	wg.Wait()
+	return multierr.Append(onDemandErr, spotErr)
 }
 
 // getRegionForPricingAPI attempts to retrieve the region description based on the AWS session used to create
 // the ec2pricing struct. It then uses the endpoints package in the aws sdk to retrieve the region description
 // This is necessary because the pricing API uses the region description rather than a region ID
-func (p *EC2Pricing) getRegionForPricingAPI() string {
+// An error is returned for regions outside of the standard aws partition (GovCloud and China) since the Pricing API does not list their prices,
+// and for regions which are not known to the endpoints package so that a mistyped region is not priced as another region
+func (p *EC2Pricing) getRegionForPricingAPI() (string, error) {
 	endpointResolver := endpoints.DefaultResolver()
 	partitions := endpointResolver.(endpoints.EnumPartitions).Partitions()
 
-	// use us-east-1 as the default
-	regionDescription := "US East (N. Virginia)"
+	sessionRegion := p.region()
+	if sessionRegion == "" {
+		return "", fmt.Errorf("unable to retrieve on-demand prices without a region in the AWS session")
+	}
 	for _, partition := range partitions {
 		regions := partition.Regions()
-		if region, ok := regions[*p.AWSSession.Config.Region]; ok {
-			regionDescription = region.Description()
+		if region, ok := regions[sessionRegion]; ok {
+			if partition.ID() != endpoints.AwsPartitionID {
+				return "", fmt.Errorf("on-demand pricing API not available for partition %s of region %s", partition.ID(), sessionRegion)
+			}
+			return region.Description(), nil
 		}
 	}
-	return regionDescription
+	return "", fmt.Errorf("unable to find the description of region %s for the on-demand pricing API, it is not a known region", sessionRegion)
 }
 
 // parseOndemandUnitPrice takes a priceList from the pricing API and parses its weirdness
-func parseOndemandUnitPrice(priceList aws.JSONValue) (string, float64, error) {
-	// TODO: this could probably be cleaned up a bit by adding a couple structs with json tags
-	//       We still need to some weird for-loops to get at elements under json keys that are IDs...
-	//       But it would probably be cleaner than this.
-	attributes, ok := priceList["product"].(map[string]interface{})["attributes"]
-	if !ok {
-		return "", float64(-1.0), fmt.Errorf("Unable to find product attributes")
-	}
-	instanceTypeName, ok := attributes.(map[string]interface{})["instanceType"].(string)
-	if !ok {
-		return "", float64(-1.0), fmt.Errorf("Unable to find instance type name from product attributes")
-	}
-	terms, ok := priceList["terms"]
-	if !ok {
+// The price per unit is read in the currency, an error is returned if the price document does not list a price in it
+func parseOndemandUnitPrice(priceList aws.JSONValue, currency string) (string, float64, error) {
+	doc, err := decodePriceListDoc(priceList)
+	if err != nil {
+		return "", float64(-1.0), err
+	}
+	instanceTypeName, err := doc.instanceType()
+	if err != nil {
+		return "", float64(-1.0), err
+	}
+	if doc.Terms == nil {
 		return instanceTypeName, float64(-1.0), fmt.Errorf("Unable to find pricing terms")
 	}
-	ondemandTerms, ok := terms.(map[string]interface{})["OnDemand"]
-	if !ok {
+	if doc.Terms.OnDemand == nil {
 		return instanceTypeName, float64(-1.0), fmt.Errorf("Unable to find on-demand pricing terms")
 	}
-	for _, priceDimensions := range ondemandTerms.(map[string]interface{}) {
-		dim, ok := priceDimensions.(map[string]interface{})["priceDimensions"]
-		if !ok {
-			return instanceTypeName, float64(-1.0), fmt.Errorf("Unable to find on-demand pricing dimensions")
+	// terms are keyed by IDs, so a document with more than one on-demand term is ambiguous
+	if len(doc.Terms.OnDemand) > 1 {
+		return instanceTypeName, float64(-1.0), fmt.Errorf("Unable to choose between %d on-demand pricing terms", len(doc.Terms.OnDemand))
+	}
+	for _, ondemandTerm := range doc.Terms.OnDemand {
+		dimension, err := ondemandTerm.ondemandHourlyDimension()
+		if err != nil {
+			return instanceTypeName, float64(-1.0), err
 		}
-		for _, dimension := range dim.(map[string]interface{}) {
-			dims := dimension.(map[string]interface{})
-			pricePerUnit, ok := dims["pricePerUnit"]
-			if !ok {
-				return instanceTypeName, float64(-1.0), fmt.Errorf("Unable to find on-demand price per unit in pricing dimensions")
-			}
-			pricePerUnitInUSDStr, ok := pricePerUnit.(map[string]interface{})["USD"]
-			if !ok {
-				return instanceTypeName, float64(-1.0), fmt.Errorf("Unable to find on-demand price per unit in USD")
-			}
-			var err error
-			pricePerUnitInUSD, err := strconv.ParseFloat(pricePerUnitInUSDStr.(string), 64)
-			if err != nil {
-				return instanceTypeName, float64(-1.0), fmt.Errorf("Could not convert price per unit in USD to a float64")
-			}
-			return instanceTypeName, pricePerUnitInUSD, nil
+		pricePerUnitInCurrency, err := dimension.price(currency)
+		if err != nil {
+			return instanceTypeName, float64(-1.0), fmt.Errorf("Unable to parse on-demand price: %w", err)
 		}
+		return instanceTypeName, pricePerUnitInCurrency, nil
 	}
 	return instanceTypeName, float64(-1.0), fmt.Errorf("Unable to parse pricing doc")
 }
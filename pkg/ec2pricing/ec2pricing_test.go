@@ -1,27 +1,44 @@
 package ec2pricing_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
 	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/pricing"
 	"github.com/aws/aws-sdk-go/service/pricing/pricingiface"
+	"github.com/aws/aws-sdk-go/service/savingsplans"
+	"github.com/aws/aws-sdk-go/service/savingsplans/savingsplansiface"
 )
 
 const (
-	getProductsPages              = "GetProductsPages"
-	describeSpotPriceHistoryPages = "DescribeSpotPriceHistoryPages"
-	mockFilesPath                 = "../../test/static"
+	getProductsPages                  = "GetProductsPages"
+	describeSpotPriceHistoryPages     = "DescribeSpotPriceHistoryPages"
+	describeSavingsPlansOfferingRates = "DescribeSavingsPlansOfferingRates"
+	mockFilesPath                     = "../../test/static"
 )
 
+// fixtureClock returns a time shortly after the newest sample in the DescribeSpotPriceHistoryPages/m5_large.json fixture
+func fixtureClock() time.Time {
+	return time.Date(2021, 2, 9, 2, 0, 0, 0, time.UTC)
+}
+
 // Mocking helpers
 
 type gpFn = func(page *pricing.GetProductsOutput, lastPage bool) bool
@@ -30,22 +47,181 @@ type dspFn = func(page *ec2.DescribeSpotPriceHistoryOutput, lastPage bool) bool
 type mockedPricing struct {
 	pricingiface.PricingAPI
 	ec2iface.EC2API
-	GetProductsPagesResp              pricing.GetProductsOutput
-	GetProductsPagesErr               error
+	savingsplansiface.SavingsPlansAPI
+	GetProductsPagesResp         pricing.GetProductsOutput
+	GetProductsPagesRespSequence []pricing.GetProductsOutput
+	// GetProductsPagesRespPages are returned as separate pages when not empty
+	// Only the price documents matching the input's instanceType filter are returned if it has one
+	GetProductsPagesRespPages []pricing.GetProductsOutput
+	GetProductsPagesCalls     *int
+	GetProductsPagesErr       error
+	// GetProductsPagesInputs records the input of each GetProductsPages call when not nil
+	GetProductsPagesInputs            *[]*pricing.GetProductsInput
 	DescribeSpotPriceHistoryPagesResp ec2.DescribeSpotPriceHistoryOutput
-	DescribeSpotPriceHistoryPagesErr  error
+	// DescribeSpotPriceHistoryPagesRespPages are returned as separate pages instead of the DescribeSpotPriceHistoryPagesResp when not empty
+	DescribeSpotPriceHistoryPagesRespPages []ec2.DescribeSpotPriceHistoryOutput
+	DescribeSpotPriceHistoryPagesErr       error
+	// DescribeSpotPriceHistoryPagesInputs records the input of each DescribeSpotPriceHistoryPages call when not nil
+	DescribeSpotPriceHistoryPagesInputs *[]*ec2.DescribeSpotPriceHistoryInput
+	// DescribeSavingsPlansOfferingRatesResp are returned as separate pages, filtered by the input's plan types and payment options
+	DescribeSavingsPlansOfferingRatesResp []savingsplans.DescribeSavingsPlansOfferingRatesOutput
+	DescribeSavingsPlansOfferingRatesErr  error
+	// DescribeSavingsPlansOfferingRatesInputs records the input of each DescribeSavingsPlansOfferingRates call when not nil
+	DescribeSavingsPlansOfferingRatesInputs *[]*savingsplans.DescribeSavingsPlansOfferingRatesInput
 }
 
 func (m mockedPricing) GetProductsPages(input *pricing.GetProductsInput, fn gpFn) error {
+	if m.GetProductsPagesInputs != nil {
+		*m.GetProductsPagesInputs = append(*m.GetProductsPagesInputs, input)
+	}
+	if m.GetProductsPagesCalls != nil {
+		*m.GetProductsPagesCalls++
+	}
+	if len(m.GetProductsPagesRespPages) > 0 {
+		instanceType := ""
+		for _, filter := range input.Filters {
+			if aws.StringValue(filter.Field) == "instanceType" {
+				instanceType = aws.StringValue(filter.Value)
+			}
+		}
+		for i, page := range m.GetProductsPagesRespPages {
+			filteredPage := pricing.GetProductsOutput{PriceList: []aws.JSONValue{}}
+			for _, priceDoc := range page.PriceList {
+				attributes := priceDoc["product"].(map[string]interface{})["attributes"].(map[string]interface{})
+				if instanceType == "" || attributes["instanceType"] == instanceType {
+					filteredPage.PriceList = append(filteredPage.PriceList, priceDoc)
+				}
+			}
+			if instanceType != "" && len(filteredPage.PriceList) == 0 {
+				continue
+			}
+			if !fn(&filteredPage, i == len(m.GetProductsPagesRespPages)-1) {
+				break
+			}
+		}
+		return m.GetProductsPagesErr
+	}
+	if len(m.GetProductsPagesRespSequence) > 0 {
+		call := *m.GetProductsPagesCalls - 1
+		if call >= len(m.GetProductsPagesRespSequence) {
+			call = len(m.GetProductsPagesRespSequence) - 1
+		}
+		fn(&m.GetProductsPagesRespSequence[call], true)
+		return m.GetProductsPagesErr
+	}
 	fn(&m.GetProductsPagesResp, true)
 	return m.GetProductsPagesErr
 }
 
+func (m mockedPricing) GetProductsPagesWithContext(ctx aws.Context, input *pricing.GetProductsInput, fn gpFn, opts ...request.Option) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.GetProductsPages(input, fn)
+}
+
+func (m mockedPricing) DescribeSpotPriceHistoryPagesWithContext(ctx aws.Context, input *ec2.DescribeSpotPriceHistoryInput, fn dspFn, opts ...request.Option) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.DescribeSpotPriceHistoryPages(input, fn)
+}
+
 func (m mockedPricing) DescribeSpotPriceHistoryPages(input *ec2.DescribeSpotPriceHistoryInput, fn dspFn) error {
-	fn(&m.DescribeSpotPriceHistoryPagesResp, true)
+	if m.DescribeSpotPriceHistoryPagesInputs != nil {
+		*m.DescribeSpotPriceHistoryPagesInputs = append(*m.DescribeSpotPriceHistoryPagesInputs, input)
+	}
+	if len(m.DescribeSpotPriceHistoryPagesRespPages) > 0 {
+		for i := range m.DescribeSpotPriceHistoryPagesRespPages {
+			page := filterSpotPriceHistory(m.DescribeSpotPriceHistoryPagesRespPages[i], input)
+			if !fn(&page, i == len(m.DescribeSpotPriceHistoryPagesRespPages)-1) {
+				break
+			}
+		}
+		return m.DescribeSpotPriceHistoryPagesErr
+	}
+	page := filterSpotPriceHistory(m.DescribeSpotPriceHistoryPagesResp, input)
+	fn(&page, true)
 	return m.DescribeSpotPriceHistoryPagesErr
 }
 
+// filterSpotPriceHistory returns the spot price history of the page which is for the input's instance types, if it has any
+func filterSpotPriceHistory(page ec2.DescribeSpotPriceHistoryOutput, input *ec2.DescribeSpotPriceHistoryInput) ec2.DescribeSpotPriceHistoryOutput {
+	if len(input.InstanceTypes) == 0 {
+		return page
+	}
+	instanceTypes := map[string]bool{}
+	for _, instanceType := range input.InstanceTypes {
+		instanceTypes[aws.StringValue(instanceType)] = true
+	}
+	filteredPage := ec2.DescribeSpotPriceHistoryOutput{}
+	for _, history := range page.SpotPriceHistory {
+		if instanceTypes[aws.StringValue(history.InstanceType)] {
+			filteredPage.SpotPriceHistory = append(filteredPage.SpotPriceHistory, history)
+		}
+	}
+	return filteredPage
+}
+
+func (m mockedPricing) DescribeSavingsPlansOfferingRatesWithContext(ctx aws.Context, input *savingsplans.DescribeSavingsPlansOfferingRatesInput, opts ...request.Option) (*savingsplans.DescribeSavingsPlansOfferingRatesOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if m.DescribeSavingsPlansOfferingRatesInputs != nil {
+		*m.DescribeSavingsPlansOfferingRatesInputs = append(*m.DescribeSavingsPlansOfferingRatesInputs, input)
+	}
+	if m.DescribeSavingsPlansOfferingRatesErr != nil {
+		return nil, m.DescribeSavingsPlansOfferingRatesErr
+	}
+	output := savingsplans.DescribeSavingsPlansOfferingRatesOutput{SearchResults: []*savingsplans.SavingsPlanOfferingRate{}}
+	if len(m.DescribeSavingsPlansOfferingRatesResp) == 0 {
+		return &output, nil
+	}
+	page := 0
+	if input.NextToken != nil {
+		page, _ = strconv.Atoi(*input.NextToken)
+	}
+	for _, offeringRate := range m.DescribeSavingsPlansOfferingRatesResp[page].SearchResults {
+		if containsString(input.SavingsPlanTypes, offeringRate.SavingsPlanOffering.PlanType) &&
+			containsString(input.SavingsPlanPaymentOptions, offeringRate.SavingsPlanOffering.PaymentOption) {
+			output.SearchResults = append(output.SearchResults, offeringRate)
+		}
+	}
+	if page+1 < len(m.DescribeSavingsPlansOfferingRatesResp) {
+		output.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return &output, nil
+}
+
+// containsString returns true if the values are empty or contain the value
+func containsString(values []*string, value *string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if aws.StringValue(v) == aws.StringValue(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// setupFixturePricing returns an EC2Pricing for us-east-1 whose Clock is the fixtureClock, whose spot price history is read from the
+// DescribeSpotPriceHistoryPages fixture file, and whose on-demand prices are read from the GetProductsPages m5_large.json fixture
+func setupFixturePricing(t *testing.T, spotFixture string) *ec2pricing.EC2Pricing {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	return &ec2pricing.EC2Pricing{
+		Clock:         fixtureClock,
+		PricingClient: setupMock(t, getProductsPages, "m5_large.json"),
+		EC2Client:     setupMock(t, describeSpotPriceHistoryPages, spotFixture),
+		AWSSession:    &sess,
+	}
+}
+
 func setupMock(t *testing.T, api string, file string) mockedPricing {
 	mockFilename := fmt.Sprintf("%s/%s/%s", mockFilesPath, api, file)
 	mockFile, err := ioutil.ReadFile(mockFilename)
@@ -68,6 +244,13 @@ func setupMock(t *testing.T, api string, file string) mockedPricing {
 		return mockedPricing{
 			DescribeSpotPriceHistoryPagesResp: dspho,
 		}
+	case describeSavingsPlansOfferingRates:
+		dspor := savingsplans.DescribeSavingsPlansOfferingRatesOutput{}
+		err = json.Unmarshal(mockFile, &dspor)
+		h.Assert(t, err == nil, "Error parsing mock json file contents"+mockFilename)
+		return mockedPricing{
+			DescribeSavingsPlansOfferingRatesResp: []savingsplans.DescribeSavingsPlansOfferingRatesOutput{dspor},
+		}
 
 	default:
 		h.Assert(t, false, "Unable to mock the provided API type "+api)
@@ -91,6 +274,238 @@ func TestGetOndemandInstanceTypeCost_m5large(t *testing.T) {
 	h.Equals(t, float64(0.096), price)
 }
 
+func TestGetOndemandInstanceTypeCost_TieredPriceDimensions(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large_tiered.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	// the first tier is the base hourly rate regardless of the order of the dimensions
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	price, err = ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+}
+
+func TestGetOndemandInstanceTypeCost_ZeroPrice(t *testing.T) {
+	// ap-southeast-2 is not in the static price list which would otherwise replace the rejected price
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("ap-southeast-2"),
+		},
+	}
+	zeroPriceDoc := setupMock(t, getProductsPages, "m5_large_zero_price.json").GetProductsPagesResp.PriceList[0]
+	pricingMock := mockedPricing{GetProductsPagesRespPages: []pricing.GetProductsOutput{
+		{PriceList: []aws.JSONValue{zeroPriceDoc, productsPriceDoc(t, "c5.large", "0.0850000000")}},
+	}}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	_, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Nok(t, err)
+
+	// the zero price is not cached with the rest of the price list
+	var partialErr *ec2pricing.PartialHydrationError
+	h.Assert(t, errors.As(ec2pricingClient.HydrateOndemandCache(), &partialErr), "Expected a PartialHydrationError")
+	h.Equals(t, "the on-demand price of instance type m5.large is 0.000000", partialErr.Err.Error())
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("c5.large")
+	h.Ok(t, err)
+	h.Equals(t, 0.085, price)
+	_, err = ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Nok(t, err)
+}
+
+func TestGetOndemandInstanceTypeCost_MinOndemandPrice(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("ap-southeast-2"),
+		},
+	}
+	_, pricingMock := setupBatchPricing(t)
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient:    pricingMock,
+		AWSSession:       &sess,
+		MinOndemandPrice: 0.09,
+	}
+	var partialErr *ec2pricing.PartialHydrationError
+	h.Assert(t, errors.As(ec2pricingClient.HydrateOndemandCache(), &partialErr), "Expected a PartialHydrationError")
+	h.Equals(t, "the on-demand price 0.085000 of instance type c5.large is below the minimum price 0.090000", partialErr.Err.Error())
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, 0.096, price)
+	_, err = ec2pricingClient.GetOndemandInstanceTypeCost("c5.large")
+	h.Nok(t, err)
+}
+
+func TestGetOndemandInstanceTypeCost_mac1metal(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "mac1_metal.json")
+	pricingMock.GetProductsPagesInputs = &[]*pricing.GetProductsInput{}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("mac1.metal")
+	h.Ok(t, err)
+	h.Equals(t, float64(1.083), price)
+	// mac metal instances are only priced as macOS on Dedicated Hosts regardless of the operating system and tenancy
+	productInput := (*pricingMock.GetProductsPagesInputs)[0]
+	h.Equals(t, "MacOS", getProductsFilterValue(productInput, "operatingSystem"))
+	h.Equals(t, "Host", getProductsFilterValue(productInput, "tenancy"))
+	h.Equals(t, "AllocatedHost", getProductsFilterValue(productInput, "capacitystatus"))
+	h.Equals(t, "mac1.metal", getProductsFilterValue(productInput, "instanceType"))
+}
+
+func TestSetOndemandPriceOverride(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	ec2pricingClient.SetOndemandPriceOverride("m5.large", 0.08)
+	ec2pricingClient.SetOndemandPriceOverride("x9.large", 1.5)
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.08), price)
+
+	// overrides are kept after hydrating the cache
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	price, err = ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.08), price)
+	price, err = ec2pricingClient.GetOndemandInstanceTypeCost("x9.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(1.5), price)
+}
+
+func TestGetOndemandInstanceTypeCost_EmptyThenPopulated(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	populated := setupMock(t, getProductsPages, "m5_large.json").GetProductsPagesResp
+	pricingMock := mockedPricing{
+		GetProductsPagesRespSequence: []pricing.GetProductsOutput{{}, populated},
+		GetProductsPagesCalls:        aws.Int(0),
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+	h.Equals(t, 2, *pricingMock.GetProductsPagesCalls)
+}
+
+func TestGetOndemandInstanceTypeCost_NoMatch(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := mockedPricing{
+		GetProductsPagesCalls: aws.Int(0),
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(-1), price)
+	// retried once before treating the empty price list as a genuine no-match
+	h.Equals(t, 2, *pricingMock.GetProductsPagesCalls)
+}
+
+func TestGetOndemandInstanceTypePrice(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: setupMock(t, getProductsPages, "m5_large.json"),
+		AWSSession:    &sess,
+	}
+	price, err := ec2pricingClient.GetOndemandInstanceTypePrice("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, &ec2pricing.Price{InstanceType: "m5.large", AmountPerHour: 0.096, Currency: "USD"}, price)
+
+	ec2pricingClient.SetOndemandPriceOverride("m5.xlarge", 0.15)
+	price, err = ec2pricingClient.GetOndemandInstanceTypePrice("m5.xlarge")
+	h.Ok(t, err)
+	h.Equals(t, &ec2pricing.Price{InstanceType: "m5.xlarge", AmountPerHour: 0.15, Currency: "USD"}, price)
+}
+
+func TestGetOndemandInstanceTypePrice_NoMatch(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: mockedPricing{GetProductsPagesCalls: aws.Int(0)},
+		AWSSession:    &sess,
+	}
+	price, err := ec2pricingClient.GetOndemandInstanceTypePrice("m5.large")
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoOndemandPrice), "Expected ErrNoOndemandPrice, got %v", err)
+	h.Assert(t, price == nil, "Expected no price, got %v", price)
+}
+
+func TestWithOndemandCurrency(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	ec2pricingClient := ec2pricing.New(sess, ec2pricing.WithOndemandCurrency("CNY"))
+	ec2pricingClient.PricingClient = setupMock(t, getProductsPages, "m5_large_multi_currency.json")
+	h.Equals(t, "CNY", ec2pricingClient.OndemandCurrency())
+	price, err := ec2pricingClient.GetOndemandInstanceTypePrice("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, &ec2pricing.Price{InstanceType: "m5.large", AmountPerHour: 0.62, Currency: "CNY"}, price)
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	cost, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, 0.62, cost)
+
+	// prices are read in USD by default
+	ec2pricingClient = ec2pricing.New(sess)
+	ec2pricingClient.PricingClient = setupMock(t, getProductsPages, "m5_large_multi_currency.json")
+	h.Equals(t, "USD", ec2pricingClient.OndemandCurrency())
+	cost, err = ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, 0.096, cost)
+}
+
+func TestWithOndemandCurrency_MissingCurrency(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	ec2pricingClient := ec2pricing.New(sess, ec2pricing.WithOndemandCurrency("CNY"))
+	ec2pricingClient.PricingClient = setupMock(t, getProductsPages, "m5_large.json")
+	price, err := ec2pricingClient.GetOndemandInstanceTypePrice("m5.large")
+	h.Nok(t, err)
+	h.Assert(t, strings.Contains(err.Error(), "CNY"), "Expected the error to name the missing currency, got %v", err)
+	h.Assert(t, price == nil, "Expected no price, got %v", price)
+	h.Nok(t, ec2pricingClient.HydrateOndemandCache())
+}
+
 func TestHydrateOndemandCache(t *testing.T) {
 	sess := session.Session{
 		Config: &aws.Config{
@@ -110,6 +525,276 @@ func TestHydrateOndemandCache(t *testing.T) {
 	h.Equals(t, float64(0.096), price)
 }
 
+func TestHydrateOndemandCache_MacMetal(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	macPriceDoc := setupMock(t, getProductsPages, "mac1_metal.json").GetProductsPagesResp.PriceList[0]
+	pricingMock := mockedPricing{
+		GetProductsPagesInputs: &[]*pricing.GetProductsInput{},
+		GetProductsPagesRespPages: []pricing.GetProductsOutput{
+			{PriceList: []aws.JSONValue{productsPriceDoc(t, "m5.large", "0.0960000000"), macPriceDoc}},
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Equals(t, 2, len(*pricingMock.GetProductsPagesInputs))
+	h.Equals(t, "Shared", getProductsFilterValue((*pricingMock.GetProductsPagesInputs)[0], "tenancy"))
+	h.Equals(t, "AllocatedHost", getProductsFilterValue((*pricingMock.GetProductsPagesInputs)[1], "capacitystatus"))
+	h.Equals(t, map[string]float64{"m5.large": 0.096, "mac1.metal": 1.083}, ec2pricingClient.OnDemandCacheSnapshot())
+
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("mac1.metal")
+	h.Ok(t, err)
+	h.Equals(t, float64(1.083), price)
+	h.Equals(t, 2, len(*pricingMock.GetProductsPagesInputs))
+}
+
+func TestHydrateOndemandCache_ConcurrentLookups(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+		}()
+		go func() {
+			defer wg.Done()
+			price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+			h.Ok(t, err)
+			h.Equals(t, float64(0.096), price)
+		}()
+	}
+	wg.Wait()
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() != nil, "Expected the on-demand cache to be hydrated")
+}
+
+func TestHydrateCaches(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:         fixtureClock,
+		PricingClient: setupMock(t, getProductsPages, "m5_large.json"),
+		EC2Client:     setupMock(t, describeSpotPriceHistoryPages, "m5_large.json"),
+		AWSSession:    &sess,
+	}
+	// lookups run alongside the combined hydration so that the race detector covers both caches
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			h.Ok(t, ec2pricingClient.HydrateCaches(30))
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+			h.Ok(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+			h.Ok(t, err)
+		}()
+	}
+	wg.Wait()
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() != nil, "Expected the on-demand cache to be hydrated")
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() != nil, "Expected the spot cache to be hydrated")
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+	spotPrice, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, float64(0.04148843143974511), spotPrice)
+}
+
+func TestHydrateCaches_Errors(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	ec2Mock.DescribeSpotPriceHistoryPagesErr = errors.New("spot price history is unavailable")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:         fixtureClock,
+		PricingClient: pricingMock,
+		EC2Client:     ec2Mock,
+		AWSSession:    &sess,
+	}
+	h.Nok(t, ec2pricingClient.HydrateCaches(30))
+	// the on-demand cache is still hydrated when the spot cache fails
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() != nil, "Expected the on-demand cache to be hydrated")
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() == nil, "Expected the spot cache to not be hydrated")
+}
+
+func TestHydrateOndemandCacheWithContext_Canceled(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := ec2pricingClient.HydrateOndemandCacheWithContext(ctx)
+	h.Equals(t, context.Canceled, err)
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() == nil, "The on-demand cache should not be hydrated when the context is canceled")
+
+	_, err = ec2pricingClient.GetOndemandInstanceTypeCostWithContext(ctx, "m5.large")
+	h.Equals(t, context.Canceled, err)
+
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCostWithContext(context.Background(), "m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+}
+
+func TestHydrateSpotCacheWithContext_Canceled(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := ec2pricingClient.HydrateSpotCacheWithContext(ctx, 30)
+	h.Equals(t, context.Canceled, err)
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() == nil, "The spot cache should not be hydrated when the context is canceled")
+
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCostWithContext(ctx, "m5.large", []string{"us-east-1a"}, 30)
+	h.Equals(t, context.Canceled, err)
+
+	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostWithContext(context.Background(), "m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, float64(0.04148843143974511), price)
+}
+
+// spotPriceHistoryPages splits the spot price history of the fixture into pageCount pages
+func spotPriceHistoryPages(t *testing.T, file string, pageCount int) []ec2.DescribeSpotPriceHistoryOutput {
+	history := setupMock(t, describeSpotPriceHistoryPages, file).DescribeSpotPriceHistoryPagesResp.SpotPriceHistory
+	spotPages := []ec2.DescribeSpotPriceHistoryOutput{}
+	pageSize := len(history)/pageCount + 1
+	for start := 0; start < len(history); start += pageSize {
+		end := start + pageSize
+		if end > len(history) {
+			end = len(history)
+		}
+		spotPages = append(spotPages, ec2.DescribeSpotPriceHistoryOutput{SpotPriceHistory: history[start:end]})
+	}
+	h.Equals(t, pageCount, len(spotPages))
+	return spotPages
+}
+
+// cancelingPages returns pages without checking the context between them and cancels it after the first page,
+// recording how many pages were passed to the callback
+type cancelingPages struct {
+	pricingiface.PricingAPI
+	ec2iface.EC2API
+	ProductsPages []pricing.GetProductsOutput
+	SpotPages     []ec2.DescribeSpotPriceHistoryOutput
+	Cancel        context.CancelFunc
+	PagesReturned *int
+}
+
+func (m cancelingPages) GetProductsPagesWithContext(ctx aws.Context, input *pricing.GetProductsInput, fn gpFn, opts ...request.Option) error {
+	for i := range m.ProductsPages {
+		*m.PagesReturned++
+		if !fn(&m.ProductsPages[i], i == len(m.ProductsPages)-1) {
+			break
+		}
+		m.Cancel()
+	}
+	return nil
+}
+
+func (m cancelingPages) DescribeSpotPriceHistoryPagesWithContext(ctx aws.Context, input *ec2.DescribeSpotPriceHistoryInput, fn dspFn, opts ...request.Option) error {
+	for i := range m.SpotPages {
+		*m.PagesReturned++
+		if !fn(&m.SpotPages[i], i == len(m.SpotPages)-1) {
+			break
+		}
+		m.Cancel()
+	}
+	return nil
+}
+
+func TestHydrateOndemandCacheWithContext_CanceledMidPagination(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pagesReturned := 0
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: cancelingPages{
+			ProductsPages: []pricing.GetProductsOutput{
+				{PriceList: []aws.JSONValue{productsPriceDoc(t, "m5.large", "0.0960000000")}},
+				{PriceList: []aws.JSONValue{productsPriceDoc(t, "c5.large", "0.0850000000")}},
+				{PriceList: []aws.JSONValue{productsPriceDoc(t, "r5.large", "0.1260000000")}},
+			},
+			Cancel:        cancel,
+			PagesReturned: &pagesReturned,
+		},
+		AWSSession: &sess,
+	}
+	err := ec2pricingClient.HydrateOndemandCacheWithContext(ctx)
+	h.Equals(t, context.Canceled, err)
+	// the second page is rejected and the third is never requested
+	h.Equals(t, 2, pagesReturned)
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() == nil, "The on-demand cache should not be hydrated when the context is canceled")
+}
+
+func TestHydrateSpotCacheWithContext_CanceledMidPagination(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	spotPages := spotPriceHistoryPages(t, "m5_large.json", 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pagesReturned := 0
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  cancelingPages{SpotPages: spotPages, Cancel: cancel, PagesReturned: &pagesReturned},
+		AWSSession: &sess,
+	}
+	err := ec2pricingClient.HydrateSpotCacheWithContext(ctx, 30)
+	h.Equals(t, context.Canceled, err)
+	h.Equals(t, 2, pagesReturned)
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() == nil, "The spot cache should not be hydrated when the context is canceled")
+}
+
 func TestGetSpotInstanceTypeNDayAvgCost(t *testing.T) {
 	sess := session.Session{
 		Config: &aws.Config{
@@ -118,12 +803,34 @@ func TestGetSpotInstanceTypeNDayAvgCost(t *testing.T) {
 	}
 	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
 	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
 		EC2Client:  ec2Mock,
 		AWSSession: &sess,
 	}
 	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
 	h.Ok(t, err)
-	h.Equals(t, float64(0.041486231229302666), price)
+	h.Equals(t, float64(0.04148843143974511), price)
+}
+
+func TestGetSpotInstanceTypeNDayAvgCost_NoHistory(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("eu-south-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  mockedPricing{DescribeSpotPriceHistoryPagesResp: ec2.DescribeSpotPriceHistoryOutput{}},
+		AWSSession: &sess,
+	}
+	_, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"eu-south-1a"}, 30)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotPriceHistory), "Expected ErrNoSpotPriceHistory, got %v", err)
+	h.Equals(t, "no spot price history found for instance type m5.large in zones [eu-south-1a]", err.Error())
+
+	// zones without spot price history are an error as well
+	ec2pricingClient.EC2Client = setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-west-2a"}, 30)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotPriceHistory), "Expected ErrNoSpotPriceHistory, got %v", err)
 }
 
 func TestHydrateSpotCache(t *testing.T) {
@@ -134,6 +841,7 @@ func TestHydrateSpotCache(t *testing.T) {
 	}
 	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
 	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
 		EC2Client:  ec2Mock,
 		AWSSession: &sess,
 	}
@@ -142,5 +850,320 @@ func TestHydrateSpotCache(t *testing.T) {
 
 	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
 	h.Ok(t, err)
-	h.Equals(t, float64(0.041486231229302666), price)
+	h.Equals(t, float64(0.04148843143974511), price)
+}
+
+func TestGetSpotInstanceTypeNDayAvgCost_InvalidDays(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	inputs := []*ec2.DescribeSpotPriceHistoryInput{}
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &inputs
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	_, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 0)
+	h.Nok(t, err)
+	h.Equals(t, "the days of spot price history must be greater than 0 but was 0", err.Error())
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, -1)
+	h.Nok(t, err)
+	h.Equals(t, 0, len(inputs))
+
+	// a single day is the shortest valid history window
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 1)
+	h.Ok(t, err)
+	h.Equals(t, 1, len(inputs))
+}
+
+func TestGetSpotInstanceTypeAvgCost(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	inputs := []*ec2.DescribeSpotPriceHistoryInput{}
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &inputs
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	price, err := ec2pricingClient.GetSpotInstanceTypeAvgCost("m5.large", []string{"us-east-1a"})
+	h.Ok(t, err)
+	h.Equals(t, float64(0.04148843143974511), price)
+	h.Equals(t, 1, len(inputs))
+	h.Equals(t, 30*24*time.Hour, inputs[0].EndTime.Sub(*inputs[0].StartTime))
+}
+
+func TestHydrateSpotCache_InvalidDays(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	inputs := []*ec2.DescribeSpotPriceHistoryInput{}
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &inputs
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	h.Nok(t, ec2pricingClient.HydrateSpotCache(0))
+	h.Nok(t, ec2pricingClient.HydrateSpotCache(-1))
+	h.Equals(t, 0, len(inputs))
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() == nil, "the spot cache should not be hydrated")
+	h.Nok(t, ec2pricingClient.RefreshSpotInstanceType("m5.large", 0))
+
+	h.Ok(t, ec2pricingClient.HydrateDefaultSpotCache())
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() != nil, "the spot cache should be hydrated")
+	price, err := ec2pricingClient.GetSpotInstanceTypeAvgCost("m5.large", []string{"us-east-1a"})
+	h.Ok(t, err)
+	h.Equals(t, float64(0.04148843143974511), price)
+	h.Equals(t, 1, len(inputs))
+}
+
+func TestSupportedPricingRegions(t *testing.T) {
+	regions := ec2pricing.SupportedPricingRegions()
+	h.Equals(t, []string{"us-east-1", "ap-south-1"}, regions)
+	regions[0] = "eu-west-1"
+	h.Equals(t, "us-east-1", ec2pricing.SupportedPricingRegions()[0])
+}
+
+func TestIsRegionPriceable(t *testing.T) {
+	h.Assert(t, ec2pricing.IsRegionPriceable("us-east-1"), "us-east-1 should be priceable")
+	h.Assert(t, ec2pricing.IsRegionPriceable("eu-west-1"), "eu-west-1 should be priceable")
+	h.Assert(t, !ec2pricing.IsRegionPriceable("us-gov-west-1"), "us-gov-west-1 should not be priceable")
+	h.Assert(t, !ec2pricing.IsRegionPriceable("cn-north-1"), "cn-north-1 should not be priceable")
+	h.Assert(t, !ec2pricing.IsRegionPriceable("not-a-region-1"), "not-a-region-1 should not be priceable")
+}
+
+func TestGetOndemandInstanceTypeCost_StandardRegionLocation(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("eu-west-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	pricingMock.GetProductsPagesInputs = &[]*pricing.GetProductsInput{}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	_, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, 1, len(*pricingMock.GetProductsPagesInputs))
+	h.Equals(t, "Europe (Ireland)", getProductsFilterValue((*pricingMock.GetProductsPagesInputs)[0], "location"))
+}
+
+func TestGetOndemandInstanceTypeCost_UnsupportedPartition(t *testing.T) {
+	for _, region := range []string{"us-gov-west-1", "cn-north-1"} {
+		sess := session.Session{
+			Config: &aws.Config{
+				Region: aws.String(region),
+			},
+		}
+		pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+		pricingMock.GetProductsPagesCalls = new(int)
+		ec2pricingClient := ec2pricing.EC2Pricing{
+			PricingClient: pricingMock,
+			AWSSession:    &sess,
+		}
+		// the Pricing API is not queried since it would fall back to the price of a different region
+		price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+		h.Nok(t, err)
+		h.Assert(t, strings.Contains(err.Error(), "on-demand pricing API not available for partition"), "Unexpected error for %s: %v", region, err)
+		h.Equals(t, float64(-1), price)
+		h.Nok(t, ec2pricingClient.HydrateOndemandCache())
+		h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() == nil, "Expected the on-demand cache to not be hydrated for %s", region)
+		h.Equals(t, 0, *pricingMock.GetProductsPagesCalls)
+	}
+}
+
+func TestGetOndemandInstanceTypeCost_UnknownRegion(t *testing.T) {
+	for _, region := range []string{"us-esat-1", ""} {
+		sess := session.Session{
+			Config: &aws.Config{
+				Region: aws.String(region),
+			},
+		}
+		pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+		pricingMock.GetProductsPagesCalls = new(int)
+		ec2pricingClient := ec2pricing.EC2Pricing{
+			PricingClient: pricingMock,
+			AWSSession:    &sess,
+		}
+		// the Virginia prices are not returned for a mistyped region
+		price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+		h.Nok(t, err)
+		h.Equals(t, float64(-1), price)
+		h.Nok(t, ec2pricingClient.HydrateOndemandCache())
+		h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() == nil, "Expected the on-demand cache to not be hydrated for %q", region)
+		h.Equals(t, 0, *pricingMock.GetProductsPagesCalls)
+	}
+}
+
+func TestListInstanceTypes(t *testing.T) {
+	ec2pricingClient, _ := setupBatchPricing(t)
+	h.Equals(t, []string{}, ec2pricingClient.ListOndemandInstanceTypes())
+	h.Equals(t, []string{}, ec2pricingClient.ListSpotInstanceTypes())
+
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Equals(t, []string{"c5.large", "c5.xlarge", "m5.large", "m5.xlarge", "r5.large"}, ec2pricingClient.ListOndemandInstanceTypes())
+
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "multi_type.json")
+	ec2pricingClient.EC2Client = ec2Mock
+	ec2pricingClient.Clock = fixtureClock
+	h.Ok(t, ec2pricingClient.HydrateSpotCacheForProductDescriptions(30, []string{"Linux/UNIX (Amazon VPC)", "Red Hat Enterprise Linux (Amazon VPC)"}))
+	h.Equals(t, []string{"c5.large", "m5.large", "r5.large"}, ec2pricingClient.ListSpotInstanceTypes())
+}
+
+func TestWithSpotPriceHistoryPageSize(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	inputs := []*ec2.DescribeSpotPriceHistoryInput{}
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &inputs
+
+	ec2pricingClient := ec2pricing.New(sess, ec2pricing.WithSpotPriceHistoryPageSize(100))
+	ec2pricingClient.EC2Client = ec2Mock
+	_, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	h.Equals(t, 2, len(inputs))
+	for _, input := range inputs {
+		h.Equals(t, int64(100), *input.MaxResults)
+	}
+
+	inputs = []*ec2.DescribeSpotPriceHistoryInput{}
+	ec2pricingClient = ec2pricing.New(sess)
+	ec2pricingClient.EC2Client = ec2Mock
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	h.Equals(t, 1, len(inputs))
+	h.Assert(t, inputs[0].MaxResults == nil, "MaxResults should not be set when no page size is configured")
+}
+
+func TestWithSpotPriceHistoryPageSize_OutOfRange(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	for pageSize, expected := range map[int64]*int64{
+		-1:   nil,
+		0:    nil,
+		1:    aws.Int64(5),
+		5:    aws.Int64(5),
+		1000: aws.Int64(1000),
+		5000: aws.Int64(1000),
+	} {
+		ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+		inputs := []*ec2.DescribeSpotPriceHistoryInput{}
+		ec2Mock.DescribeSpotPriceHistoryPagesInputs = &inputs
+		messages := []string{}
+		ec2pricingClient := ec2pricing.New(sess, ec2pricing.WithLogger(recordingLogger{messages: &messages}), ec2pricing.WithSpotPriceHistoryPageSize(pageSize))
+		ec2pricingClient.EC2Client = ec2Mock
+		h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+		h.Equals(t, 1, len(inputs))
+		h.Equals(t, expected, inputs[0].MaxResults)
+		// a warning is logged when the page size is changed
+		isChanged := pageSize != 0 && (expected == nil || *expected != pageSize)
+		h.Assert(t, isChanged == (countMessages(messages, "WARN") == 1), "Unexpected warnings for page size %d: %v", pageSize, messages)
+	}
+}
+
+func TestNewWithSessions(t *testing.T) {
+	ec2Session := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-west-2"),
+		Credentials: credentials.NewStaticCredentials("ec2-access-key", "ec2-secret-key", ""),
+	}))
+	pricingSession := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("eu-west-1"),
+		Credentials: credentials.NewStaticCredentials("pricing-access-key", "pricing-secret-key", ""),
+	}))
+	ec2pricingClient := ec2pricing.NewWithSessions(ec2Session, pricingSession)
+	h.Equals(t, ec2Session, ec2pricingClient.AWSSession)
+
+	ec2Client := ec2pricingClient.EC2Client.(*ec2.EC2)
+	ec2Credentials, err := ec2Client.Config.Credentials.Get()
+	h.Ok(t, err)
+	h.Equals(t, "ec2-access-key", ec2Credentials.AccessKeyID)
+	h.Equals(t, "us-west-2", *ec2Client.Config.Region)
+
+	// the Pricing client keeps the pricing session's credentials but always uses a Pricing API endpoint region
+	pricingClient := ec2pricingClient.PricingClient.(*pricing.Pricing)
+	pricingCredentials, err := pricingClient.Config.Credentials.Get()
+	h.Ok(t, err)
+	h.Equals(t, "pricing-access-key", pricingCredentials.AccessKeyID)
+	h.Equals(t, "us-east-1", *pricingClient.Config.Region)
+}
+
+func TestNewFromClients(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	productsInputs := []*pricing.GetProductsInput{}
+	pricingMock.GetProductsPagesInputs = &productsInputs
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	spotInputs := []*ec2.DescribeSpotPriceHistoryInput{}
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &spotInputs
+
+	ec2pricingClient := ec2pricing.NewFromClients(pricingMock, ec2Mock, sess, ec2pricing.WithSpotPriceHistoryPageSize(100))
+	ec2pricingClient.Clock = fixtureClock
+	h.Equals(t, sess, ec2pricingClient.AWSSession)
+
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+	h.Equals(t, 1, len(productsInputs))
+	h.Equals(t, "m5.large", getProductsFilterValue(productsInputs[0], "instanceType"))
+
+	spotPrice, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, float64(0.04148843143974511), spotPrice)
+	h.Equals(t, 1, len(spotInputs))
+	h.Equals(t, "m5.large", *spotInputs[0].InstanceTypes[0])
+	// the options are still applied to the requests of the injected clients
+	h.Equals(t, int64(100), *spotInputs[0].MaxResults)
+}
+
+func TestWithPricingEndpointRegion(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("ap-southeast-2")}))
+	pricingClient := ec2pricing.New(sess).PricingClient.(*pricing.Pricing)
+	h.Equals(t, "us-east-1", *pricingClient.Config.Region)
+
+	ec2pricingClient := ec2pricing.New(sess, ec2pricing.WithPricingEndpointRegion("ap-south-1"))
+	pricingClient = ec2pricingClient.PricingClient.(*pricing.Pricing)
+	h.Equals(t, "ap-south-1", *pricingClient.Config.Region)
+	// the pricing endpoint does not change the region being priced
+	h.Equals(t, "ap-southeast-2", *ec2pricingClient.AWSSession.Config.Region)
+
+	// regions without a Pricing API endpoint fall back to us-east-1
+	pricingClient = ec2pricing.New(sess, ec2pricing.WithPricingEndpointRegion("eu-west-1")).PricingClient.(*pricing.Pricing)
+	h.Equals(t, "us-east-1", *pricingClient.Config.Region)
+}
+
+func TestWithMaxRetries(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-west-2")}))
+	ec2pricingClient := ec2pricing.New(sess, ec2pricing.WithMaxRetries(7))
+	ec2Client := ec2pricingClient.EC2Client.(*ec2.EC2)
+	h.Equals(t, 7, ec2Client.Retryer.MaxRetries())
+	h.Equals(t, "us-west-2", *ec2Client.Config.Region)
+	pricingClient := ec2pricingClient.PricingClient.(*pricing.Pricing)
+	h.Equals(t, 7, pricingClient.Retryer.MaxRetries())
+	h.Equals(t, "us-east-1", *pricingClient.Config.Region)
+
+	// the SDK default is used without the option
+	ec2Client = ec2pricing.New(sess).EC2Client.(*ec2.EC2)
+	h.Equals(t, client.DefaultRetryerMaxNumRetries, ec2Client.Retryer.MaxRetries())
+}
+
+func TestWithRetryer(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-west-2")}))
+	retryer := client.DefaultRetryer{NumMaxRetries: 12, MinThrottleDelay: time.Second}
+	ec2pricingClient := ec2pricing.New(sess, ec2pricing.WithRetryer(retryer), ec2pricing.WithPricingEndpointRegion("ap-south-1"))
+	h.Equals(t, retryer, ec2pricingClient.EC2Client.(*ec2.EC2).Retryer)
+	pricingClient := ec2pricingClient.PricingClient.(*pricing.Pricing)
+	h.Equals(t, retryer, pricingClient.Retryer)
+	h.Equals(t, "ap-south-1", *pricingClient.Config.Region)
 }
@@ -0,0 +1,123 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/pricing"
+	"go.uber.org/multierr"
+)
+
+// GetOndemandInstanceTypeCostAsOf retrieves the on-demand hourly cost for the specified instance type which was effective on the specified date
+// The most recent on-demand term with an effectiveDate on or before the date is used. The Pricing API only publishes the terms of the
+// current price list, so an error is returned if none of them were effective yet on the date.
+// The onDemandCache is not used since it only holds current prices.
+func (p *EC2Pricing) GetOndemandInstanceTypeCostAsOf(instanceType string, date time.Time) (float64, error) {
+	return p.GetOndemandInstanceTypeCostAsOfWithContext(context.Background(), instanceType, date)
+}
+
+// GetOndemandInstanceTypeCostAsOfWithContext is like GetOndemandInstanceTypeCostAsOf but the Pricing API request is canceled when the context is done
+func (p *EC2Pricing) GetOndemandInstanceTypeCostAsOfWithContext(ctx context.Context, instanceType string, date time.Time) (float64, error) {
+	productInput, err := p.getOndemandProductsInput(instanceType)
+	if err != nil {
+		return -1, err
+	}
+
+	if p.planAPICall(APIGetProducts, &productInput) {
+		return -1, fmt.Errorf("%w for the on-demand price of instance type %s", ErrDryRun, instanceType)
+	}
+	pricePerUnit := float64(-1)
+	var effectiveDate *time.Time
+	var processingErr error
+	var errCtx error
+	apiCallStart := time.Now()
+	errAPI := p.getProductsPages(ctx, &productInput, func(pricingOutput *pricing.GetProductsOutput, nextPage bool) bool {
+		if err := ctx.Err(); err != nil {
+			errCtx = err
+			return false
+		}
+		for _, priceDoc := range pricingOutput.PriceList {
+			termEffectiveDate, termPrice, errParse := parseOndemandUnitPriceAsOf(priceDoc, date, p.OndemandCurrency())
+			if errParse != nil {
+				processingErr = multierr.Append(processingErr, errParse)
+				continue
+			}
+			if termEffectiveDate == nil {
+				continue
+			}
+			if effectiveDate == nil || termEffectiveDate.After(*effectiveDate) {
+				effectiveDate = termEffectiveDate
+				pricePerUnit = termPrice
+			}
+		}
+		return true
+	})
+	p.observe().OnAPICall(APIGetProducts, time.Since(apiCallStart))
+	if errAPI == nil {
+		errAPI = errCtx
+	}
+	if errAPI != nil {
+		return -1, errAPI
+	}
+	if processingErr != nil {
+		return -1, processingErr
+	}
+	if effectiveDate == nil {
+		return -1, fmt.Errorf("No on-demand price for instance type %s was effective as of %s", instanceType, date.UTC().Format(time.RFC3339))
+	}
+	return pricePerUnit, nil
+}
+
+// parseOndemandUnitPriceAsOf returns the effective date and price of the most recent on-demand term in the pricing doc which was effective on the date
+// A nil effective date is returned if none of the terms were effective on the date
+func parseOndemandUnitPriceAsOf(priceList aws.JSONValue, date time.Time, currency string) (*time.Time, float64, error) {
+	doc, err := decodePriceListDoc(priceList)
+	if err != nil {
+		return nil, float64(-1.0), err
+	}
+	if doc.Terms == nil {
+		return nil, float64(-1.0), fmt.Errorf("Unable to find pricing terms")
+	}
+	if doc.Terms.OnDemand == nil {
+		return nil, float64(-1.0), fmt.Errorf("Unable to find on-demand pricing terms")
+	}
+	var effectiveDate *time.Time
+	pricePerUnitInCurrency := float64(-1.0)
+	for _, ondemandTerm := range doc.Terms.OnDemand {
+		if ondemandTerm.EffectiveDate == "" {
+			return nil, float64(-1.0), fmt.Errorf("Unable to find effective date in on-demand pricing term")
+		}
+		termEffectiveDate, err := time.Parse(time.RFC3339, ondemandTerm.EffectiveDate)
+		if err != nil {
+			return nil, float64(-1.0), fmt.Errorf("Could not parse on-demand pricing term effective date %s", ondemandTerm.EffectiveDate)
+		}
+		if termEffectiveDate.After(date) || (effectiveDate != nil && !termEffectiveDate.After(*effectiveDate)) {
+			continue
+		}
+		dimension, err := ondemandTerm.ondemandHourlyDimension()
+		if err != nil {
+			return nil, float64(-1.0), err
+		}
+		pricePerUnitInCurrency, err = dimension.price(currency)
+		if err != nil {
+			return nil, float64(-1.0), fmt.Errorf("Unable to parse on-demand price: %w", err)
+		}
+		effectiveDate = &termEffectiveDate
+	}
+	return effectiveDate, pricePerUnitInCurrency, nil
+}
@@ -0,0 +1,85 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func TestGetOndemandInstanceTypeCostAsOf(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCostAsOf("m5.large", time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC))
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+
+	// the term becomes effective on 2021-02-01
+	price, err = ec2pricingClient.GetOndemandInstanceTypeCostAsOf("m5.large", time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC))
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+}
+
+func TestGetOndemandInstanceTypeCostAsOf_BeforeEffectiveDate(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCostAsOf("m5.large", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	h.Nok(t, err)
+	h.Equals(t, float64(-1), price)
+}
+
+func TestGetOndemandInstanceTypeCostAsOfWithContext_Canceled(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	date := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCostAsOfWithContext(ctx, "m5.large", date)
+	h.Equals(t, context.Canceled, err)
+	h.Equals(t, float64(-1), price)
+
+	price, err = ec2pricingClient.GetOndemandInstanceTypeCostAsOfWithContext(context.Background(), "m5.large", date)
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+}
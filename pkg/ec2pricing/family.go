@@ -0,0 +1,66 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GetOndemandFamilyAvgCost returns the average on-demand hourly cost of every size of the instance family (Example: "m5" for all m5.*
+// instance types) in the on-demand cache
+// Prices set with SetOndemandPriceOverride take precedence over the cached prices like in GetOndemandInstanceTypeCost, and overridden
+// instance types of the family are averaged even if they are not cached.
+// Only the on-demand cache is used, so an error is returned if it has not been hydrated, and an ErrNoOndemandPrice error is returned
+// if none of the cached instance types are in the family. When the MaxCacheEntries bounds the cache, only the sizes which are still
+// cached are averaged, so the average may not cover the whole family.
+func (p *EC2Pricing) GetOndemandFamilyAvgCost(family string) (float64, error) {
+	return p.GetOndemandFamilyAvgCostWithContext(context.Background(), family)
+}
+
+// GetOndemandFamilyAvgCostWithContext is like GetOndemandFamilyAvgCost but the on-demand cache refresh is canceled when the context is done
+func (p *EC2Pricing) GetOndemandFamilyAvgCostWithContext(ctx context.Context, family string) (float64, error) {
+	p.refreshExpiredOndemandCache(ctx)
+	p.cacheMu.RLock()
+	defer p.cacheMu.RUnlock()
+	if len(p.onDemandCache) == 0 {
+		return -1, fmt.Errorf("the on-demand price cache must be hydrated before the average cost of instance family %s can be computed", family)
+	}
+	familyPrices := map[string]float64{}
+	for instanceType, price := range p.onDemandCache {
+		if strings.HasPrefix(instanceType, family+".") {
+			familyPrices[instanceType] = price
+		}
+	}
+	for instanceType, price := range p.onDemandPriceOverrides {
+		if strings.HasPrefix(instanceType, family+".") {
+			familyPrices[instanceType] = price
+		}
+	}
+	if len(familyPrices) == 0 {
+		return -1, fmt.Errorf("%w for instance family %s", ErrNoOndemandPrice, family)
+	}
+	instanceTypes := make([]string, 0, len(familyPrices))
+	for instanceType := range familyPrices {
+		instanceTypes = append(instanceTypes, instanceType)
+	}
+	sort.Strings(instanceTypes)
+	priceSum := float64(0)
+	for _, instanceType := range instanceTypes {
+		priceSum += familyPrices[instanceType]
+	}
+	return priceSum / float64(len(instanceTypes)), nil
+}
@@ -0,0 +1,82 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+func TestGetOndemandFamilyAvgCost(t *testing.T) {
+	ec2pricingClient, _ := setupBatchPricing(t)
+	_, err := ec2pricingClient.GetOndemandFamilyAvgCost("m5")
+	h.Nok(t, err)
+
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	avg, err := ec2pricingClient.GetOndemandFamilyAvgCost("m5")
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(avg-(0.096+0.192)/2) < 1e-12, "Expected the average of m5.large and m5.xlarge, got %f", avg)
+	avg, err = ec2pricingClient.GetOndemandFamilyAvgCost("c5")
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(avg-(0.085+0.17)/2) < 1e-12, "Expected the average of c5.large and c5.xlarge, got %f", avg)
+	avg, err = ec2pricingClient.GetOndemandFamilyAvgCost("r5")
+	h.Ok(t, err)
+	h.Equals(t, 0.126, avg)
+
+	// the family must be the whole name before the size, so "m" does not match the m5 instance types
+	_, err = ec2pricingClient.GetOndemandFamilyAvgCost("m")
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoOndemandPrice), "Expected ErrNoOndemandPrice, got %v", err)
+	_, err = ec2pricingClient.GetOndemandFamilyAvgCost("t3")
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoOndemandPrice), "Expected ErrNoOndemandPrice, got %v", err)
+}
+
+func TestGetOndemandFamilyAvgCost_Overrides(t *testing.T) {
+	ec2pricingClient, _ := setupBatchPricing(t)
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	// the override of a cached size replaces its price and an uncached size is added to the family
+	ec2pricingClient.SetOndemandPriceOverride("m5.xlarge", 0.2)
+	ec2pricingClient.SetOndemandPriceOverride("m5.2xlarge", 0.384)
+	avg, err := ec2pricingClient.GetOndemandFamilyAvgCost("m5")
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(avg-(0.096+0.2+0.384)/3) < 1e-12, "Expected the average of the cached and overridden m5 sizes, got %f", avg)
+}
+
+func TestGetOndemandFamilyAvgCost_MaxCacheEntries(t *testing.T) {
+	ec2pricingClient, pricingMock := setupBatchPricing(t)
+	pricingMock.GetProductsPagesRespPages = []pricing.GetProductsOutput{
+		{PriceList: []aws.JSONValue{productsPriceDoc(t, "m5.large", "0.0960000000"), productsPriceDoc(t, "m5.xlarge", "0.1920000000")}},
+		{PriceList: []aws.JSONValue{productsPriceDoc(t, "m5.2xlarge", "0.3840000000")}},
+	}
+	ec2pricingClient.PricingClient = pricingMock
+	ec2pricingClient.MaxCacheEntries = 2
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	cached := ec2pricingClient.OnDemandCacheSnapshot()
+	h.Equals(t, 2, len(cached))
+
+	// only the two m5 sizes left in the bounded cache are averaged rather than all three
+	expected := float64(0)
+	for _, price := range cached {
+		expected += price / 2
+	}
+	avg, err := ec2pricingClient.GetOndemandFamilyAvgCost("m5")
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(avg-expected) < 1e-12, "Expected the average of the cached m5 sizes %v, got %f", cached, avg)
+	h.Assert(t, math.Abs(avg-(0.096+0.192+0.384)/3) > 1e-3, "Expected the evicted m5 size to be left out of the average, got %f", avg)
+}
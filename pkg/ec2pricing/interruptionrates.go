@@ -0,0 +1,170 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultSpotAdvisorURL is the public Spot Instance Advisor feed which is used when the SpotAdvisorURL is empty
+const defaultSpotAdvisorURL = "https://spot-bid-advisor.s3.amazonaws.com/spot-advisor-data.json"
+
+// defaultSpotAdvisorHTTPClient fetches the Spot Instance Advisor feed when the SpotAdvisorHTTPClient is nil
+// The timeout keeps a stalled endpoint from blocking lookups whose context has no deadline
+var defaultSpotAdvisorHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// Operating systems the Spot Instance Advisor reports interruption rates for
+const (
+	spotAdvisorLinux   = "Linux"
+	spotAdvisorWindows = "Windows"
+)
+
+// ErrNoSpotInterruptionRate is returned when the Spot Instance Advisor does not have an interruption rate for an instance type
+var ErrNoSpotInterruptionRate = errors.New("no spot interruption rate found")
+
+// SpotInterruptionRateBucket is a range of the monthly spot interruption frequency reported by the Spot Instance Advisor
+type SpotInterruptionRateBucket struct {
+	// Index orders the buckets, where 0 is the least frequently interrupted bucket
+	Index int
+	// Label describes the range, such as "<5%" or "5-10%"
+	Label string
+	// MaxPercent is the upper bound of the range in percent
+	MaxPercent int
+}
+
+// spotAdvisorFeed is the subset of the Spot Instance Advisor feed which is used to look up interruption rates
+type spotAdvisorFeed struct {
+	Ranges []spotAdvisorRange `json:"ranges"`
+	// SpotAdvisor is keyed by region, operating system, and then instance type
+	SpotAdvisor map[string]map[string]map[string]spotAdvisorInstanceType `json:"spot_advisor"`
+}
+
+// spotAdvisorRange is an interruption-rate bucket of the Spot Instance Advisor feed
+type spotAdvisorRange struct {
+	Index int    `json:"index"`
+	Label string `json:"label"`
+	Max   int    `json:"max"`
+}
+
+// spotAdvisorInstanceType is the savings over on-demand and the interruption-rate bucket index of an instance type in the Spot Instance Advisor feed
+type spotAdvisorInstanceType struct {
+	Savings int `json:"s"`
+	Range   int `json:"r"`
+}
+
+// GetSpotInterruptionRate returns the Spot Instance Advisor's interruption-rate bucket of the instance type in the region
+// for the OperatingSystem, where every operating system other than windows uses the linux interruption rates
+// The feed is fetched on first use and cached, and it is fetched again once it is older than the CacheTTL
+// An ErrNoSpotInterruptionRate error is returned if the feed does not have the instance type in the region
+func (p *EC2Pricing) GetSpotInterruptionRate(instanceType string, region string) (SpotInterruptionRateBucket, error) {
+	return p.GetSpotInterruptionRateWithContext(context.Background(), instanceType, region)
+}
+
+// GetSpotInterruptionRateWithContext is like GetSpotInterruptionRate but the request for the feed is canceled when the context is done
+func (p *EC2Pricing) GetSpotInterruptionRateWithContext(ctx context.Context, instanceType string, region string) (SpotInterruptionRateBucket, error) {
+	feed, err := p.getSpotAdvisorFeed(ctx)
+	if err != nil {
+		return SpotInterruptionRateBucket{}, err
+	}
+	instanceTypeAdvice, ok := feed.SpotAdvisor[region][p.spotAdvisorOperatingSystem()][instanceType]
+	if !ok {
+		return SpotInterruptionRateBucket{}, fmt.Errorf("%w for instance type %s in region %s", ErrNoSpotInterruptionRate, instanceType, region)
+	}
+	for _, rateRange := range feed.Ranges {
+		if rateRange.Index == instanceTypeAdvice.Range {
+			return SpotInterruptionRateBucket{Index: rateRange.Index, Label: rateRange.Label, MaxPercent: rateRange.Max}, nil
+		}
+	}
+	return SpotInterruptionRateBucket{}, fmt.Errorf("the interruption-rate bucket %d of instance type %s is not described by the spot advisor feed", instanceTypeAdvice.Range, instanceType)
+}
+
+// GetSpotInterruptionRateBucket returns the index of the instance type's interruption-rate bucket in the current AWSSession's region,
+// where 0 is the least frequently interrupted bucket, which lets EC2Pricing order instance types by their interruption rate
+func (p *EC2Pricing) GetSpotInterruptionRateBucket(instanceType string) (int, error) {
+	bucket, err := p.GetSpotInterruptionRate(instanceType, p.region())
+	if err != nil {
+		return -1, err
+	}
+	return bucket.Index, nil
+}
+
+// spotAdvisorOperatingSystem returns the operating system of the Spot Instance Advisor feed for the OperatingSystem
+func (p *EC2Pricing) spotAdvisorOperatingSystem() string {
+	if p.OperatingSystem() == OperatingSystemWindows {
+		return spotAdvisorWindows
+	}
+	return spotAdvisorLinux
+}
+
+// getSpotAdvisorFeed returns the cached Spot Instance Advisor feed, fetching it if it has not been fetched yet or is older than the CacheTTL
+// The expired feed is kept and used if it cannot be fetched again
+func (p *EC2Pricing) getSpotAdvisorFeed(ctx context.Context) (*spotAdvisorFeed, error) {
+	p.spotAdvisorMu.Lock()
+	defer p.spotAdvisorMu.Unlock()
+	if p.spotAdvisorFeed != nil && !p.isCacheExpired(p.lastSpotAdvisorUTC) {
+		return p.spotAdvisorFeed, nil
+	}
+	feed, err := p.fetchSpotAdvisorFeed(ctx)
+	if err != nil {
+		if p.spotAdvisorFeed != nil {
+			p.log().Warnf("unable to refresh the expired spot advisor feed: %v", err)
+			return p.spotAdvisorFeed, nil
+		}
+		return nil, err
+	}
+	fetchedAt := p.now()
+	p.spotAdvisorFeed = feed
+	p.lastSpotAdvisorUTC = &fetchedAt
+	return feed, nil
+}
+
+// fetchSpotAdvisorFeed downloads and parses the Spot Instance Advisor feed from the SpotAdvisorURL
+func (p *EC2Pricing) fetchSpotAdvisorFeed(ctx context.Context) (*spotAdvisorFeed, error) {
+	url := p.SpotAdvisorURL
+	if url == "" {
+		url = defaultSpotAdvisorURL
+	}
+	if p.planAPICall(APISpotAdvisor, url) {
+		return nil, fmt.Errorf("%w for the spot advisor feed", ErrDryRun)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the spot advisor feed request: %w", err)
+	}
+	apiCallStart := time.Now()
+	httpClient := p.SpotAdvisorHTTPClient
+	if httpClient == nil {
+		httpClient = defaultSpotAdvisorHTTPClient
+	}
+	resp, err := httpClient.Do(req)
+	p.observe().OnAPICall(APISpotAdvisor, time.Since(apiCallStart))
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the spot advisor feed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to retrieve the spot advisor feed, got non-200 status code: %d", resp.StatusCode)
+	}
+	feed := spotAdvisorFeed{}
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("unable to parse the spot advisor feed: %w", err)
+	}
+	p.log().Debugf("fetched the spot advisor feed from %s", url)
+	return &feed, nil
+}
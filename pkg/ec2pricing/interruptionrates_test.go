@@ -0,0 +1,180 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+const spotAdvisorFixture = "SpotAdvisor/spot-advisor-data.json"
+
+// spotAdvisorHTTPServer serves the spot advisor fixture and counts the requests it receives in requests
+// The server responds with a 500 status code while failing is true
+func spotAdvisorHTTPServer(t *testing.T, requests *int, failing *bool) *httptest.Server {
+	feed, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", mockFilesPath, spotAdvisorFixture))
+	h.Ok(t, err)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if *failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(feed)
+	}))
+}
+
+func TestGetSpotInterruptionRate(t *testing.T) {
+	requests, failing := 0, false
+	server := spotAdvisorHTTPServer(t, &requests, &failing)
+	defer server.Close()
+	ec2pricingClient := ec2pricing.EC2Pricing{SpotAdvisorURL: server.URL}
+
+	bucket, err := ec2pricingClient.GetSpotInterruptionRate("m5.large", "us-east-1")
+	h.Ok(t, err)
+	h.Equals(t, ec2pricing.SpotInterruptionRateBucket{Index: 0, Label: "<5%", MaxPercent: 5}, bucket)
+	bucket, err = ec2pricingClient.GetSpotInterruptionRate("c5.large", "us-east-1")
+	h.Ok(t, err)
+	h.Equals(t, ec2pricing.SpotInterruptionRateBucket{Index: 2, Label: "10-15%", MaxPercent: 16}, bucket)
+	bucket, err = ec2pricingClient.GetSpotInterruptionRate("m5.large", "us-west-2")
+	h.Ok(t, err)
+	h.Equals(t, "5-10%", bucket.Label)
+	// the feed is only fetched once
+	h.Equals(t, 1, requests)
+
+	_, err = ec2pricingClient.GetSpotInterruptionRate("m5.large", "eu-west-1")
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotInterruptionRate), "Expected ErrNoSpotInterruptionRate, got %v", err)
+	h.Equals(t, "no spot interruption rate found for instance type m5.large in region eu-west-1", err.Error())
+	_, err = ec2pricingClient.GetSpotInterruptionRate("p4d.24xlarge", "us-east-1")
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotInterruptionRate), "Expected ErrNoSpotInterruptionRate, got %v", err)
+}
+
+func TestGetSpotInterruptionRate_OperatingSystem(t *testing.T) {
+	requests, failing := 0, false
+	server := spotAdvisorHTTPServer(t, &requests, &failing)
+	defer server.Close()
+	ec2pricingClient := ec2pricing.EC2Pricing{SpotAdvisorURL: server.URL}
+
+	h.Ok(t, ec2pricingClient.SetOperatingSystem(ec2pricing.OperatingSystemWindows))
+	bucket, err := ec2pricingClient.GetSpotInterruptionRate("m5.large", "us-east-1")
+	h.Ok(t, err)
+	h.Equals(t, "15-20%", bucket.Label)
+	_, err = ec2pricingClient.GetSpotInterruptionRate("r5.large", "us-east-1")
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotInterruptionRate), "Expected ErrNoSpotInterruptionRate, got %v", err)
+
+	// operating systems other than windows use the linux interruption rates
+	h.Ok(t, ec2pricingClient.SetOperatingSystem(ec2pricing.OperatingSystemRHEL))
+	bucket, err = ec2pricingClient.GetSpotInterruptionRate("r5.large", "us-east-1")
+	h.Ok(t, err)
+	h.Equals(t, ">20%", bucket.Label)
+}
+
+func TestGetSpotInterruptionRate_CacheTTL(t *testing.T) {
+	requests, failing := 0, false
+	server := spotAdvisorHTTPServer(t, &requests, &failing)
+	defer server.Close()
+	now := time.Date(2021, 2, 9, 2, 0, 0, 0, time.UTC)
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		SpotAdvisorURL: server.URL,
+		CacheTTL:       time.Hour,
+		Clock:          func() time.Time { return now },
+	}
+	_, err := ec2pricingClient.GetSpotInterruptionRate("m5.large", "us-east-1")
+	h.Ok(t, err)
+	now = now.Add(30 * time.Minute)
+	_, err = ec2pricingClient.GetSpotInterruptionRate("m5.large", "us-east-1")
+	h.Ok(t, err)
+	h.Equals(t, 1, requests)
+
+	now = now.Add(time.Hour)
+	_, err = ec2pricingClient.GetSpotInterruptionRate("m5.large", "us-east-1")
+	h.Ok(t, err)
+	h.Equals(t, 2, requests)
+
+	// the expired feed is used when it cannot be fetched again
+	failing = true
+	now = now.Add(2 * time.Hour)
+	bucket, err := ec2pricingClient.GetSpotInterruptionRate("m5.large", "us-east-1")
+	h.Ok(t, err)
+	h.Equals(t, "<5%", bucket.Label)
+	h.Equals(t, 3, requests)
+}
+
+func TestGetSpotInterruptionRate_FeedUnavailable(t *testing.T) {
+	requests, failing := 0, true
+	server := spotAdvisorHTTPServer(t, &requests, &failing)
+	defer server.Close()
+	ec2pricingClient := ec2pricing.EC2Pricing{SpotAdvisorURL: server.URL}
+
+	_, err := ec2pricingClient.GetSpotInterruptionRate("m5.large", "us-east-1")
+	h.Nok(t, err)
+	h.Equals(t, "unable to retrieve the spot advisor feed, got non-200 status code: 500", err.Error())
+
+	// the feed is fetched again once it is available
+	failing = false
+	_, err = ec2pricingClient.GetSpotInterruptionRate("m5.large", "us-east-1")
+	h.Ok(t, err)
+	h.Equals(t, 2, requests)
+}
+
+func TestGetSpotInterruptionRateBucket(t *testing.T) {
+	requests, failing := 0, false
+	server := spotAdvisorHTTPServer(t, &requests, &failing)
+	defer server.Close()
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		AWSSession:     &sess,
+		SpotAdvisorURL: server.URL,
+	}
+	bucket, err := ec2pricingClient.GetSpotInterruptionRateBucket("r5.large")
+	h.Ok(t, err)
+	h.Equals(t, 4, bucket)
+	bucket, err = ec2pricingClient.GetSpotInterruptionRateBucket("t3.micro")
+	h.Ok(t, err)
+	h.Equals(t, 1, bucket)
+	_, err = ec2pricingClient.GetSpotInterruptionRateBucket("p4d.24xlarge")
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotInterruptionRate), "Expected ErrNoSpotInterruptionRate, got %v", err)
+}
+
+func TestGetSpotInterruptionRate_HTTPClientTimeout(t *testing.T) {
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stalled
+	}))
+	defer server.Close()
+	defer close(stalled)
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		SpotAdvisorURL:        server.URL,
+		SpotAdvisorHTTPClient: &http.Client{Timeout: 50 * time.Millisecond},
+	}
+
+	start := time.Now()
+	_, err := ec2pricingClient.GetSpotInterruptionRate("m5.large", "us-east-1")
+	h.Nok(t, err)
+	h.Assert(t, time.Since(start) < 5*time.Second, "Expected the stalled feed request to time out, took %s", time.Since(start))
+}
@@ -0,0 +1,58 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import "fmt"
+
+// Logger receives leveled diagnostic messages from EC2Pricing, such as cache hits and misses, API retries, and parse failures
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+// noopLogger discards all messages and is used when no Logger has been set
+type noopLogger struct{}
+
+func (noopLogger) Debugf(format string, args ...interface{}) {}
+func (noopLogger) Infof(format string, args ...interface{})  {}
+func (noopLogger) Warnf(format string, args ...interface{})  {}
+
+// SetLogger sets the Logger which EC2Pricing emits diagnostic messages to
+// Messages are discarded when the logger is nil, which is the default. The warnings of the options passed to the constructor are
+// only logged to a Logger set with WithLogger
+func (p *EC2Pricing) SetLogger(logger Logger) {
+	p.logger = logger
+}
+
+// WithLogger sets the Logger which EC2Pricing emits diagnostic messages to, including the warnings of the other options
+func WithLogger(logger Logger) Option {
+	return func(p *EC2Pricing) {
+		p.logger = logger
+	}
+}
+
+// warnOption records a warning of an option, which is logged by the constructor once all of the options have been applied
+// since the Logger may be set by an option after this one
+func (p *EC2Pricing) warnOption(format string, args ...interface{}) {
+	p.optionWarnings = append(p.optionWarnings, fmt.Sprintf(format, args...))
+}
+
+// log returns the Logger that was set or a no-op Logger if none was set
+func (p *EC2Pricing) log() Logger {
+	if p.logger == nil {
+		return noopLogger{}
+	}
+	return p.logger
+}
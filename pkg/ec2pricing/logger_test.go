@@ -0,0 +1,134 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+type recordingLogger struct {
+	messages *[]string
+}
+
+func (l recordingLogger) Debugf(format string, args ...interface{}) {
+	*l.messages = append(*l.messages, "DEBUG "+fmt.Sprintf(format, args...))
+}
+
+func (l recordingLogger) Infof(format string, args ...interface{}) {
+	*l.messages = append(*l.messages, "INFO "+fmt.Sprintf(format, args...))
+}
+
+func (l recordingLogger) Warnf(format string, args ...interface{}) {
+	*l.messages = append(*l.messages, "WARN "+fmt.Sprintf(format, args...))
+}
+
+func countMessages(messages []string, prefix string) int {
+	count := 0
+	for _, message := range messages {
+		if strings.HasPrefix(message, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSetLogger_OndemandCache(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	messages := []string{}
+	ec2pricingClient.SetLogger(recordingLogger{messages: &messages})
+
+	_, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, 1, countMessages(messages, "DEBUG on-demand price cache miss for instance type m5.large"))
+
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Equals(t, 1, countMessages(messages, "INFO hydrated the on-demand price cache"))
+	_, err = ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, 1, countMessages(messages, "DEBUG on-demand price cache hit for instance type m5.large"))
+}
+
+func TestSetLogger_SpotCache(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	messages := []string{}
+	ec2pricingClient.SetLogger(recordingLogger{messages: &messages})
+
+	_, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 1, countMessages(messages, "DEBUG spot price cache miss for instance type m5.large"))
+
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 1, countMessages(messages, "DEBUG spot price cache hit for instance type m5.large"))
+	h.Equals(t, 0, countMessages(messages, "WARN"))
+}
+
+func TestSetLogger_Nil(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	ec2pricingClient.SetLogger(nil)
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+}
+
+func TestWithLogger_OptionWarnings(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	messages := []string{}
+	// the warnings of the options before WithLogger are logged as well
+	ec2pricingClient := ec2pricing.New(sess,
+		ec2pricing.WithTenancy("shared-ish"),
+		ec2pricing.WithPricingEndpointRegion("eu-west-1"),
+		ec2pricing.WithLogger(recordingLogger{messages: &messages}),
+		ec2pricing.WithCapacityStatus("reserved"),
+	)
+	h.Equals(t, ec2pricing.TenancyShared, ec2pricingClient.Tenancy())
+	h.Equals(t, 3, countMessages(messages, "WARN"))
+	h.Assert(t, strings.Contains(messages[1], "eu-west-1"), "Expected a warning about the pricing endpoint region, got %s", messages[1])
+}
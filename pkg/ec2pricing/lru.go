@@ -0,0 +1,100 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"container/list"
+	"sort"
+)
+
+// onDemandCacheLRU tracks the order the on-demand cache entries were used in so that the least recently used entry can be evicted
+type onDemandCacheLRU struct {
+	// order holds instance types from the most recently used at the front to the least recently used at the back
+	order    *list.List
+	elements map[string]*list.Element
+}
+
+// touchOndemandCacheEntry marks the instance type as the most recently used on-demand cache entry
+// The cacheMu must be held for writing
+func (p *EC2Pricing) touchOndemandCacheEntry(instanceType string) {
+	if p.MaxCacheEntries <= 0 {
+		return
+	}
+	if p.onDemandLRU.order == nil {
+		p.onDemandLRU = onDemandCacheLRU{order: list.New(), elements: map[string]*list.Element{}}
+	}
+	if element, ok := p.onDemandLRU.elements[instanceType]; ok {
+		p.onDemandLRU.order.MoveToFront(element)
+		return
+	}
+	p.onDemandLRU.elements[instanceType] = p.onDemandLRU.order.PushFront(instanceType)
+}
+
+// insertOndemandCacheEntry adds the price of the instance type to the on-demand cache as the most recently used entry
+// and evicts the least recently used entries past the MaxCacheEntries
+// The cacheMu must be held for writing
+func (p *EC2Pricing) insertOndemandCacheEntry(instanceType string, price float64) {
+	if p.onDemandCache == nil {
+		p.onDemandCache = make(map[string]float64)
+	}
+	p.onDemandCache[instanceType] = price
+	p.touchOndemandCacheEntry(instanceType)
+	p.evictOndemandCacheEntries()
+}
+
+// evictOndemandCacheEntries removes the least recently used on-demand cache entries until there are at most MaxCacheEntries
+// Entries which were added without being used, such as by hydrating the cache, are evicted before any used entry in instance type order
+// The cacheMu must be held for writing
+func (p *EC2Pricing) evictOndemandCacheEntries() {
+	if p.MaxCacheEntries <= 0 {
+		return
+	}
+	p.syncOndemandCacheLRU()
+	for len(p.onDemandCache) > p.MaxCacheEntries {
+		leastRecentlyUsed := p.onDemandLRU.order.Back()
+		instanceType := leastRecentlyUsed.Value.(string)
+		p.onDemandLRU.order.Remove(leastRecentlyUsed)
+		delete(p.onDemandLRU.elements, instanceType)
+		delete(p.onDemandCache, instanceType)
+		p.log().Debugf("evicted the least recently used instance type %s from the on-demand price cache", instanceType)
+	}
+}
+
+// syncOndemandCacheLRU makes the LRU track exactly the instance types in the on-demand cache, which can change without the LRU
+// when the cache is hydrated, loaded, or cleared
+func (p *EC2Pricing) syncOndemandCacheLRU() {
+	if p.onDemandLRU.order == nil {
+		p.onDemandLRU = onDemandCacheLRU{order: list.New(), elements: map[string]*list.Element{}}
+	}
+	for instanceType, element := range p.onDemandLRU.elements {
+		if _, ok := p.onDemandCache[instanceType]; !ok {
+			p.onDemandLRU.order.Remove(element)
+			delete(p.onDemandLRU.elements, instanceType)
+		}
+	}
+	if len(p.onDemandLRU.elements) == len(p.onDemandCache) {
+		return
+	}
+	untracked := []string{}
+	for instanceType := range p.onDemandCache {
+		if _, ok := p.onDemandLRU.elements[instanceType]; !ok {
+			untracked = append(untracked, instanceType)
+		}
+	}
+	// untracked entries are added behind the used entries, with the instance type sorted first at the very back
+	sort.Sort(sort.Reverse(sort.StringSlice(untracked)))
+	for _, instanceType := range untracked {
+		p.onDemandLRU.elements[instanceType] = p.onDemandLRU.order.PushBack(instanceType)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+func setupLRUPricing(t *testing.T, maxCacheEntries int) (*ec2pricing.EC2Pricing, *int) {
+	pricingMock := mockedPricing{
+		GetProductsPagesCalls: new(int),
+		GetProductsPagesRespPages: []pricing.GetProductsOutput{
+			{PriceList: []aws.JSONValue{
+				productsPriceDoc(t, "m5.large", "0.0960000000"),
+				productsPriceDoc(t, "c5.large", "0.0850000000"),
+				productsPriceDoc(t, "r5.large", "0.1260000000"),
+			}},
+		},
+	}
+	ec2pricingClient := setupFixturePricing(t, "m5_large.json")
+	ec2pricingClient.PricingClient = pricingMock
+	ec2pricingClient.MaxCacheEntries = maxCacheEntries
+	return ec2pricingClient, pricingMock.GetProductsPagesCalls
+}
+
+func TestMaxCacheEntries_EvictsLeastRecentlyUsed(t *testing.T) {
+	ec2pricingClient, calls := setupLRUPricing(t, 2)
+	for _, instanceType := range []string{"m5.large", "c5.large"} {
+		_, err := ec2pricingClient.GetOndemandInstanceTypeCost(instanceType)
+		h.Ok(t, err)
+	}
+	h.Equals(t, 2, *calls)
+	// using m5.large again makes c5.large the least recently used entry
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, 0.096, price)
+	h.Equals(t, 2, *calls)
+
+	_, err = ec2pricingClient.GetOndemandInstanceTypeCost("r5.large")
+	h.Ok(t, err)
+	h.Equals(t, 3, *calls)
+	h.Equals(t, map[string]float64{"m5.large": 0.096, "r5.large": 0.126}, ec2pricingClient.OnDemandCacheSnapshot())
+
+	// the evicted instance type is fetched again, evicting the now least recently used m5.large
+	price, err = ec2pricingClient.GetOndemandInstanceTypeCost("c5.large")
+	h.Ok(t, err)
+	h.Equals(t, 0.085, price)
+	h.Equals(t, 4, *calls)
+	h.Equals(t, map[string]float64{"c5.large": 0.085, "r5.large": 0.126}, ec2pricingClient.OnDemandCacheSnapshot())
+}
+
+func TestMaxCacheEntries_Hydrate(t *testing.T) {
+	ec2pricingClient, calls := setupLRUPricing(t, 2)
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	// entries which have not been used are evicted in instance type order
+	h.Equals(t, map[string]float64{"m5.large": 0.096, "r5.large": 0.126}, ec2pricingClient.OnDemandCacheSnapshot())
+	h.Equals(t, 2, *calls)
+}
+
+func TestMaxCacheEntries_Disabled(t *testing.T) {
+	ec2pricingClient, calls := setupLRUPricing(t, 0)
+	for _, instanceType := range []string{"m5.large", "c5.large", "m5.large"} {
+		_, err := ec2pricingClient.GetOndemandInstanceTypeCost(instanceType)
+		h.Ok(t, err)
+	}
+	// individual lookups are not cached when the cache is unbounded
+	h.Equals(t, 3, *calls)
+	h.Equals(t, map[string]float64{}, ec2pricingClient.OnDemandCacheSnapshot())
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Equals(t, 3, len(ec2pricingClient.OnDemandCacheSnapshot()))
+}
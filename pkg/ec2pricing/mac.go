@@ -0,0 +1,29 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import "strings"
+
+// The Pricing API lists the on-demand price of mac metal instances (Example: mac1.metal) as macOS running on an allocated
+// Dedicated Host since they cannot be launched with shared or dedicated instance tenancy
+const (
+	macMetalOperatingSystem = "MacOS"
+	macMetalTenancy         = "Host"
+	macMetalCapacityStatus  = "AllocatedHost"
+)
+
+// isMacMetalInstanceType returns true if the instance type is a mac metal instance type (Example: mac1.metal or mac2.metal)
+func isMacMetalInstanceType(instanceType string) bool {
+	return strings.HasPrefix(instanceType, "mac") && strings.HasSuffix(instanceType, ".metal")
+}
@@ -0,0 +1,62 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import "time"
+
+// Cache kinds which are passed to an Observer
+const (
+	CacheKindOnDemand = "on-demand"
+	CacheKindSpot     = "spot"
+)
+
+// APIs which are passed to an Observer
+const (
+	APIGetProducts                       = "GetProducts"
+	APIDescribeSpotPriceHistory          = "DescribeSpotPriceHistory"
+	APIDescribeSavingsPlansOfferingRates = "DescribeSavingsPlansOfferingRates"
+	APISpotAdvisor                       = "SpotAdvisor"
+)
+
+// Observer receives events about the on-demand and spot caches and the AWS API calls EC2Pricing makes, such as to emit metrics
+// The kind is one of CacheKindOnDemand or CacheKindSpot and the api is one of APIGetProducts, APIDescribeSpotPriceHistory,
+// APIDescribeSavingsPlansOfferingRates, or APISpotAdvisor
+// An Observer must be safe for concurrent use since caches are hydrated and looked up concurrently
+type Observer interface {
+	OnCacheHit(kind string, instanceType string)
+	OnCacheMiss(kind string, instanceType string)
+	// OnAPICall is called after each paginated API call with the time taken to retrieve all of its pages
+	OnAPICall(api string, duration time.Duration)
+}
+
+// noopObserver discards all events and is used when no Observer has been set
+type noopObserver struct{}
+
+func (noopObserver) OnCacheHit(kind string, instanceType string)  {}
+func (noopObserver) OnCacheMiss(kind string, instanceType string) {}
+func (noopObserver) OnAPICall(api string, duration time.Duration) {}
+
+// SetObserver sets the Observer which EC2Pricing reports cache hits, cache misses, and API calls to
+// Events are discarded when the observer is nil, which is the default
+func (p *EC2Pricing) SetObserver(observer Observer) {
+	p.observer = observer
+}
+
+// observe returns the Observer that was set or a no-op Observer if none was set
+func (p *EC2Pricing) observe() Observer {
+	if p.observer == nil {
+		return noopObserver{}
+	}
+	return p.observer
+}
@@ -0,0 +1,104 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// recordingObserver records every event it receives, ignoring API call durations
+type recordingObserver struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *recordingObserver) record(event string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+}
+
+func (o *recordingObserver) OnCacheHit(kind string, instanceType string) {
+	o.record("hit " + kind + " " + instanceType)
+}
+
+func (o *recordingObserver) OnCacheMiss(kind string, instanceType string) {
+	o.record("miss " + kind + " " + instanceType)
+}
+
+func (o *recordingObserver) OnAPICall(api string, duration time.Duration) {
+	o.record("call " + api)
+}
+
+func TestObserver(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	observer := &recordingObserver{}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:         fixtureClock,
+		PricingClient: setupMock(t, getProductsPages, "m5_large.json"),
+		EC2Client:     setupMock(t, describeSpotPriceHistoryPages, "m5_large.json"),
+		AWSSession:    &sess,
+	}
+	ec2pricingClient.SetObserver(observer)
+
+	_, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	_, err = ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+
+	h.Equals(t, []string{
+		"miss on-demand m5.large",
+		"call GetProducts",
+		"miss spot m5.large",
+		"call DescribeSpotPriceHistory",
+		// the on-demand cache is hydrated with separate queries for mac metal instance types and the others
+		"call GetProducts",
+		"call GetProducts",
+		"call DescribeSpotPriceHistory",
+		"hit on-demand m5.large",
+		"hit spot m5.large",
+	}, observer.events)
+}
+
+func TestObserver_NotSet(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: setupMock(t, getProductsPages, "m5_large.json"),
+		AWSSession:    &sess,
+	}
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+}
@@ -0,0 +1,88 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Operating systems which prices can be retrieved for
+const (
+	OperatingSystemLinux   = "linux"
+	OperatingSystemWindows = "windows"
+	OperatingSystemRHEL    = "rhel"
+	OperatingSystemSUSE    = "suse"
+)
+
+// operatingSystemPricing is how an operating system is identified by the Pricing API and by the spot price history
+type operatingSystemPricing struct {
+	// pricingAPIValue is the value of the Pricing API's operatingSystem attribute
+	pricingAPIValue string
+	// spotProductDescription is the product description of the spot price history
+	spotProductDescription string
+}
+
+// operatingSystems maps each supported operating system to how it is priced
+var operatingSystems = map[string]operatingSystemPricing{
+	OperatingSystemLinux:   {pricingAPIValue: "Linux", spotProductDescription: productDescription},
+	OperatingSystemWindows: {pricingAPIValue: "Windows", spotProductDescription: "Windows (Amazon VPC)"},
+	OperatingSystemRHEL:    {pricingAPIValue: "RHEL", spotProductDescription: "Red Hat Enterprise Linux (Amazon VPC)"},
+	OperatingSystemSUSE:    {pricingAPIValue: "SUSE", spotProductDescription: "SUSE Linux (Amazon VPC)"},
+}
+
+// SupportedOperatingSystems returns the operating systems which can be passed to SetOperatingSystem sorted alpha-numerically
+func SupportedOperatingSystems() []string {
+	supported := []string{}
+	for operatingSystem := range operatingSystems {
+		supported = append(supported, operatingSystem)
+	}
+	sort.Strings(supported)
+	return supported
+}
+
+// SetOperatingSystem sets the operating system which on-demand and spot prices are retrieved for (Example: "windows")
+// Changing the operating system clears the on-demand and spot caches since they hold the previous operating system's prices
+// An error is returned if the operating system is not one of the SupportedOperatingSystems
+func (p *EC2Pricing) SetOperatingSystem(operatingSystem string) error {
+	operatingSystem = strings.ToLower(operatingSystem)
+	if _, ok := operatingSystems[operatingSystem]; !ok {
+		return fmt.Errorf("operating system %q is not supported, it must be one of: %s", operatingSystem, strings.Join(SupportedOperatingSystems(), ", "))
+	}
+	if operatingSystem == p.OperatingSystem() {
+		return nil
+	}
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+	p.operatingSystem = operatingSystem
+	p.onDemandCache = nil
+	p.lastOnDemandCacheUTC = nil
+	p.spotCache = nil
+	p.lastSpotCacheUTC = nil
+	return nil
+}
+
+// OperatingSystem returns the operating system which prices are retrieved for, which is linux by default
+func (p *EC2Pricing) OperatingSystem() string {
+	if p.operatingSystem == "" {
+		return OperatingSystemLinux
+	}
+	return p.operatingSystem
+}
+
+// operatingSystemPricing returns how the current operating system is identified by the Pricing API and by the spot price history
+func (p *EC2Pricing) operatingSystemPricing() operatingSystemPricing {
+	return operatingSystems[p.OperatingSystem()]
+}
@@ -0,0 +1,73 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+func getOperatingSystemFilter(input *pricing.GetProductsInput) string {
+	for _, filter := range input.Filters {
+		if aws.StringValue(filter.Field) == "operatingSystem" {
+			return aws.StringValue(filter.Value)
+		}
+	}
+	return ""
+}
+
+func TestSetOperatingSystem(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	pricingMock.GetProductsPagesInputs = &[]*pricing.GetProductsInput{}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large_multi_os.json")
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &[]*ec2.DescribeSpotPriceHistoryInput{}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:         fixtureClock,
+		PricingClient: pricingMock,
+		EC2Client:     ec2Mock,
+		AWSSession:    &sess,
+	}
+	h.Equals(t, ec2pricing.OperatingSystemLinux, ec2pricingClient.OperatingSystem())
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Equals(t, "Linux", getOperatingSystemFilter((*pricingMock.GetProductsPagesInputs)[0]))
+
+	h.Ok(t, ec2pricingClient.SetOperatingSystem("Windows"))
+	h.Equals(t, ec2pricing.OperatingSystemWindows, ec2pricingClient.OperatingSystem())
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() == nil, "Changing the operating system should clear the on-demand cache")
+
+	_, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, "Windows", getOperatingSystemFilter((*pricingMock.GetProductsPagesInputs)[2]))
+
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	h.Equals(t, []*string{aws.String("Windows (Amazon VPC)")}, (*ec2Mock.DescribeSpotPriceHistoryPagesInputs)[0].ProductDescriptions)
+}
+
+func TestSetOperatingSystem_Unsupported(t *testing.T) {
+	ec2pricingClient := ec2pricing.EC2Pricing{}
+	h.Nok(t, ec2pricingClient.SetOperatingSystem("macos"))
+	h.Equals(t, ec2pricing.OperatingSystemLinux, ec2pricingClient.OperatingSystem())
+	h.Equals(t, []string{"linux", "rhel", "suse", "windows"}, ec2pricing.SupportedOperatingSystems())
+}
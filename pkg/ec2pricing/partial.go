@@ -0,0 +1,54 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"fmt"
+
+	"go.uber.org/multierr"
+)
+
+// PartialHydrationError is returned by hydration when the cache was populated but some of the price documents or spot price
+// samples could not be processed, so the cache is missing their prices
+// Callers which can work with a partial cache can check for it with errors.As and ignore it
+type PartialHydrationError struct {
+	// CacheKind is the cache which was hydrated, one of CacheKindOnDemand or CacheKindSpot
+	CacheKind string
+	// FailedCount is the number of price documents or spot price samples which were skipped
+	FailedCount int
+	// Err combines the errors of the skipped price documents or spot price samples
+	Err error
+}
+
+func (e *PartialHydrationError) Error() string {
+	return fmt.Sprintf("the %s price cache was hydrated but %d of its entries could not be processed: %v", e.CacheKind, e.FailedCount, e.Err)
+}
+
+// Unwrap returns the combined errors of the skipped price documents or spot price samples
+func (e *PartialHydrationError) Unwrap() error {
+	return e.Err
+}
+
+// partialHydrationError returns a *PartialHydrationError of the cache kind for the processing errors of a hydration,
+// or nil if there were none
+func partialHydrationError(cacheKind string, processingErr error) error {
+	if processingErr == nil {
+		return nil
+	}
+	return &PartialHydrationError{
+		CacheKind:   cacheKind,
+		FailedCount: len(multierr.Errors(processingErr)),
+		Err:         processingErr,
+	}
+}
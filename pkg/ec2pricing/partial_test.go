@@ -0,0 +1,91 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+func TestHydrateOndemandCache_PartialHydrationError(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	malformedDoc := aws.JSONValue{"product": map[string]interface{}{"attributes": map[string]interface{}{"instanceType": "r5.large"}}}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: mockedPricing{GetProductsPagesRespPages: []pricing.GetProductsOutput{
+			{PriceList: []aws.JSONValue{productsPriceDoc(t, "m5.large", "0.0960000000"), malformedDoc}},
+			{PriceList: []aws.JSONValue{malformedDoc, productsPriceDoc(t, "c5.large", "0.0850000000")}},
+		}},
+		AWSSession: &sess,
+	}
+	err := ec2pricingClient.HydrateOndemandCache()
+	var partialErr *ec2pricing.PartialHydrationError
+	h.Assert(t, errors.As(err, &partialErr), "Expected a PartialHydrationError, got %v", err)
+	h.Equals(t, ec2pricing.CacheKindOnDemand, partialErr.CacheKind)
+	// the mock returns the same pages to the separate mac metal query, so each malformed document is counted twice
+	h.Equals(t, 4, partialErr.FailedCount)
+
+	// the documents which were parsed are cached
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() != nil, "The on-demand cache should be hydrated")
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, 0.096, price)
+	price, err = ec2pricingClient.GetOndemandInstanceTypeCost("c5.large")
+	h.Ok(t, err)
+	h.Equals(t, 0.085, price)
+}
+
+func TestHydrateSpotCache_PartialHydrationError(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large_multi_zone.json")
+	history := ec2Mock.DescribeSpotPriceHistoryPagesResp.SpotPriceHistory
+	malformedSample := *history[0]
+	malformedSample.SpotPrice = aws.String("not-a-price")
+	ec2Mock.DescribeSpotPriceHistoryPagesResp = ec2.DescribeSpotPriceHistoryOutput{SpotPriceHistory: append(history, &malformedSample)}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      func() time.Time { return time.Date(2021, 2, 13, 0, 0, 0, 0, time.UTC) },
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	err := ec2pricingClient.HydrateSpotCache(30)
+	var partialErr *ec2pricing.PartialHydrationError
+	h.Assert(t, errors.As(err, &partialErr), "Expected a PartialHydrationError, got %v", err)
+	h.Equals(t, ec2pricing.CacheKindSpot, partialErr.CacheKind)
+	h.Equals(t, 1, partialErr.FailedCount)
+
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() != nil, "The spot cache should be hydrated")
+	perZone, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostPerZone("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 3, len(perZone))
+
+	// no error is returned once every sample can be parsed
+	ec2Mock.DescribeSpotPriceHistoryPagesResp = ec2.DescribeSpotPriceHistoryOutput{SpotPriceHistory: history}
+	ec2pricingClient.EC2Client = ec2Mock
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+}
@@ -0,0 +1,71 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// GetSpotInstanceTypePercentileCost retrieves the spot price history from the past N days and returns the requested percentile
+// (Example: 50 for the median or 90 for p90) of each zone's spot price samples averaged across the zones, in the SpotCurrency
+// Unlike the time weighted average, every sample counts equally no matter how long its price was in effect, so short price spikes are not hidden
+// The percentile must be greater than 0 and at most 100
+// Passing an empty list for availabilityZones will retrieve the percentile for all AZs in the current AWSSession's region
+func (p *EC2Pricing) GetSpotInstanceTypePercentileCost(instanceType string, availabilityZones []string, days int, percentile float64) (float64, error) {
+	return p.GetSpotInstanceTypePercentileCostWithContext(context.Background(), instanceType, availabilityZones, days, percentile)
+}
+
+// GetSpotInstanceTypePercentileCostWithContext is like GetSpotInstanceTypePercentileCost but the spot-pricing-history api requests
+// are canceled when the context is done
+func (p *EC2Pricing) GetSpotInstanceTypePercentileCostWithContext(ctx context.Context, instanceType string, availabilityZones []string, days int, percentile float64) (float64, error) {
+	if percentile <= 0 || percentile > 100 {
+		return float64(-1), fmt.Errorf("percentile (%v) must be greater than 0 and at most 100", percentile)
+	}
+	zoneToPriceEntries, _, err := p.getSpotPricingEntries(ctx, instanceType, days)
+	if err != nil {
+		return float64(-1), err
+	}
+	zonePercentileSum := float64(0)
+	numOfZones := 0
+	selectedZones := newZoneSelection(availabilityZones)
+	for _, zone := range sortedZones(zoneToPriceEntries) {
+		priceEntries := zoneToPriceEntries[zone]
+		if len(priceEntries) == 0 || !selectedZones.isSelected(zone) {
+			continue
+		}
+		zonePercentileSum += spotPricePercentile(priceEntries, percentile)
+		numOfZones++
+	}
+	if numOfZones == 0 {
+		return float64(-1), fmt.Errorf("%w for instance type %s in zones %v", ErrNoSpotPriceHistory, instanceType, availabilityZones)
+	}
+	return zonePercentileSum / float64(numOfZones) * p.spotExchangeRate(), nil
+}
+
+// spotPricePercentile returns the percentile of the spot prices using the nearest-rank method, so the result is always one of the sampled prices
+func spotPricePercentile(spotPriceEntries []SpotPricingEntry, percentile float64) float64 {
+	prices := make([]float64, 0, len(spotPriceEntries))
+	for _, entry := range spotPriceEntries {
+		prices = append(prices, entry.SpotPrice)
+	}
+	sort.Float64s(prices)
+	rank := int(math.Ceil(percentile / 100 * float64(len(prices))))
+	if rank < 1 {
+		rank = 1
+	}
+	return prices[rank-1]
+}
@@ -0,0 +1,60 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+)
+
+func TestGetSpotInstanceTypePercentileCost_Median(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large_percentiles.json")
+	// us-east-1a sorted: 0.02, 0.03, 0.04, 0.05, 0.30
+	price, err := ec2pricingClient.GetSpotInstanceTypePercentileCost("m5.large", []string{"us-east-1a"}, 30, 50)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-0.04) < 1e-9, "Expected the us-east-1a median, got %f", price)
+
+	// the spike is only reflected in the highest percentiles
+	price, err = ec2pricingClient.GetSpotInstanceTypePercentileCost("m5.large", []string{"us-east-1a"}, 30, 90)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-0.30) < 1e-9, "Expected the us-east-1a p90, got %f", price)
+
+	// us-east-1b sorted: 0.06, 0.07, 0.08, the zone medians are averaged
+	price, err = ec2pricingClient.GetSpotInstanceTypePercentileCost("m5.large", []string{}, 30, 50)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-(0.04+0.07)/2) < 1e-9, "Expected the average of the zone medians, got %f", price)
+
+	price, err = ec2pricingClient.GetSpotInstanceTypePercentileCost("m5.large", []string{"us-east-1b"}, 30, 100)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-0.08) < 1e-9, "Expected the us-east-1b maximum, got %f", price)
+}
+
+func TestGetSpotInstanceTypePercentileCost_InvalidPercentile(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large_percentiles.json")
+	for _, percentile := range []float64{0, -10, 100.5} {
+		_, err := ec2pricingClient.GetSpotInstanceTypePercentileCost("m5.large", []string{}, 30, percentile)
+		h.Nok(t, err)
+	}
+}
+
+func TestGetSpotInstanceTypePercentileCost_NoHistory(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large_percentiles.json")
+	price, err := ec2pricingClient.GetSpotInstanceTypePercentileCost("m5.large", []string{"us-east-1d"}, 30, 50)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotPriceHistory), "Expected ErrNoSpotPriceHistory, got %v", err)
+	h.Equals(t, float64(-1), price)
+}
@@ -0,0 +1,134 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// cacheFileVersion is incremented whenever the format of the persisted cache file changes incompatibly
+const cacheFileVersion = 1
+
+// cacheFile is the JSON document SaveCache writes and LoadCache reads
+type cacheFile struct {
+	Version                      int                                                 `json:"Version"`
+	Region                       string                                              `json:"Region"`
+	OperatingSystem              string                                              `json:"OperatingSystem"`
+	Tenancy                      string                                              `json:"Tenancy"`
+	CapacityStatus               string                                              `json:"CapacityStatus,omitempty"`
+	OndemandCurrency             string                                              `json:"OndemandCurrency"`
+	OnDemandCache                map[string]float64                                  `json:"OnDemandCache,omitempty"`
+	LastOnDemandCacheUTC         *time.Time                                          `json:"LastOnDemandCacheUTC,omitempty"`
+	SpotCache                    map[string]map[string]map[string][]SpotPricingEntry `json:"SpotCache,omitempty"`
+	SpotCacheEndTime             time.Time                                           `json:"SpotCacheEndTime"`
+	SpotCacheDays                int                                                 `json:"SpotCacheDays"`
+	SpotCacheProductDescriptions []string                                            `json:"SpotCacheProductDescriptions,omitempty"`
+	SpotCacheInstanceTypes       []string                                            `json:"SpotCacheInstanceTypes,omitempty"`
+	LastSpotCacheUTC             *time.Time                                          `json:"LastSpotCacheUTC,omitempty"`
+}
+
+// SaveCache writes the on-demand and spot caches to a JSON file at path so that they can be restored with LoadCache
+// without hydrating them again. Caches which have not been hydrated are saved as empty.
+func (p *EC2Pricing) SaveCache(path string) error {
+	p.cacheMu.RLock()
+	contents := cacheFile{
+		Version:                      cacheFileVersion,
+		Region:                       p.region(),
+		OperatingSystem:              p.OperatingSystem(),
+		Tenancy:                      p.Tenancy(),
+		CapacityStatus:               p.CapacityStatus(),
+		OndemandCurrency:             p.OndemandCurrency(),
+		OnDemandCache:                p.onDemandCache,
+		LastOnDemandCacheUTC:         p.lastOnDemandCacheUTC,
+		SpotCache:                    p.spotCache,
+		SpotCacheEndTime:             p.spotCacheEndTime,
+		SpotCacheDays:                p.spotCacheDays,
+		SpotCacheProductDescriptions: p.spotCacheProductDescriptions,
+		SpotCacheInstanceTypes:       p.spotCacheInstanceTypes,
+		LastSpotCacheUTC:             p.lastSpotCacheUTC,
+	}
+	cacheJSON, err := json.Marshal(contents)
+	p.cacheMu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("unable to serialize the pricing caches: %w", err)
+	}
+	if err := ioutil.WriteFile(path, cacheJSON, 0644); err != nil {
+		return fmt.Errorf("unable to write the pricing caches to %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadCache replaces the on-demand and spot caches with the ones saved to path by SaveCache
+// An error is returned if the caches were saved for a different region than the current AWSSession's region, or for a different
+// OperatingSystem, Tenancy, CapacityStatus, or OndemandCurrency
+// The loaded caches keep the time they were hydrated at, so they expire based on the CacheTTL as if they had been hydrated in this process
+func (p *EC2Pricing) LoadCache(path string) error {
+	cacheJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read the pricing caches from %s: %w", path, err)
+	}
+	contents := cacheFile{}
+	if err := json.Unmarshal(cacheJSON, &contents); err != nil {
+		return fmt.Errorf("unable to parse the pricing caches in %s: %w", path, err)
+	}
+	if contents.Version != cacheFileVersion {
+		return fmt.Errorf("the pricing caches in %s have version %d but only version %d is supported", path, contents.Version, cacheFileVersion)
+	}
+	if region := p.region(); contents.Region != region {
+		return fmt.Errorf("the pricing caches in %s were saved for region %s but the current region is %s", path, contents.Region, region)
+	}
+	if operatingSystem := p.OperatingSystem(); contents.OperatingSystem != operatingSystem {
+		return fmt.Errorf("the pricing caches in %s were saved for operating system %s but the current operating system is %s", path, contents.OperatingSystem, operatingSystem)
+	}
+	if tenancy := p.Tenancy(); contents.Tenancy != tenancy {
+		return fmt.Errorf("the pricing caches in %s were saved for tenancy %s but the current tenancy is %s", path, contents.Tenancy, tenancy)
+	}
+	// caches saved before the capacity status was configurable hold the used capacity status's prices
+	if contents.CapacityStatus == "" {
+		contents.CapacityStatus = CapacityStatusUsed
+	}
+	if capacityStatus := p.CapacityStatus(); contents.CapacityStatus != capacityStatus {
+		return fmt.Errorf("the pricing caches in %s were saved for capacity status %s but the current capacity status is %s", path, contents.CapacityStatus, capacityStatus)
+	}
+	if currency := p.OndemandCurrency(); contents.OndemandCurrency != currency {
+		return fmt.Errorf("the pricing caches in %s were saved with on-demand prices in %s but the current on-demand currency is %s", path, contents.OndemandCurrency, currency)
+	}
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+	p.onDemandCache = contents.OnDemandCache
+	p.lastOnDemandCacheUTC = contents.LastOnDemandCacheUTC
+	p.spotCache = contents.SpotCache
+	p.spotCacheEndTime = contents.SpotCacheEndTime
+	p.spotCacheDays = contents.SpotCacheDays
+	p.spotCacheProductDescriptions = contents.SpotCacheProductDescriptions
+	// a spot cache saved without instance types holds every instance type
+	p.spotCacheInstanceTypes = contents.SpotCacheInstanceTypes
+	p.lastSpotCacheUTC = contents.LastSpotCacheUTC
+	p.evictOndemandCacheEntries()
+	p.log().Infof("loaded the pricing caches for region %s from %s", contents.Region, path)
+	return nil
+}
+
+// region returns the region of the current AWSSession or an empty string if it does not have one
+func (p *EC2Pricing) region() string {
+	if p.AWSSession == nil || p.AWSSession.Config == nil {
+		return ""
+	}
+	return aws.StringValue(p.AWSSession.Config.Region)
+}
@@ -0,0 +1,153 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func newRegionPricing(region string) *ec2pricing.EC2Pricing {
+	return &ec2pricing.EC2Pricing{
+		Clock: fixtureClock,
+		AWSSession: &session.Session{
+			Config: &aws.Config{
+				Region: aws.String(region),
+			},
+		},
+	}
+}
+
+func TestSaveCache_LoadCache(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "ec2pricing")
+	h.Ok(t, err)
+	defer os.RemoveAll(tmpDir)
+	cachePath := filepath.Join(tmpDir, "cache.json")
+
+	hydrated := newRegionPricing("us-east-1")
+	hydrated.PricingClient = setupMock(t, getProductsPages, "m5_large.json")
+	hydrated.EC2Client = setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	h.Ok(t, hydrated.HydrateOndemandCache())
+	h.Ok(t, hydrated.HydrateSpotCache(30))
+	h.Ok(t, hydrated.SaveCache(cachePath))
+
+	// the loaded pricing has no clients, so every price must come from the loaded caches
+	loaded := newRegionPricing("us-east-1")
+	h.Ok(t, loaded.LoadCache(cachePath))
+	h.Equals(t, *hydrated.LastOnDemandCacheUTC(), *loaded.LastOnDemandCacheUTC())
+	h.Equals(t, *hydrated.LastSpotCacheUTC(), *loaded.LastSpotCacheUTC())
+
+	expectedOndemand, err := hydrated.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	actualOndemand, err := loaded.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, expectedOndemand, actualOndemand)
+
+	for _, zones := range [][]string{nil, {"us-east-1a"}, {"us-east-1b", "us-east-1c"}} {
+		expected, err := hydrated.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", zones, 30)
+		h.Ok(t, err)
+		actual, err := loaded.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", zones, 30)
+		h.Ok(t, err)
+		h.Assert(t, fmt.Sprint(expected) == fmt.Sprint(actual), "Expected the loaded spot cost %v to equal %v for zones %v", actual, expected, zones)
+	}
+}
+
+func TestLoadCache_SpotCacheInstanceTypes(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "ec2pricing")
+	h.Ok(t, err)
+	defer os.RemoveAll(tmpDir)
+	restrictedPath := filepath.Join(tmpDir, "restricted.json")
+	fullPath := filepath.Join(tmpDir, "full.json")
+	clock := &fakeClock{current: fixtureClock()}
+
+	hydrated, _, _ := setupCacheTTLPricing(t, time.Hour, clock)
+	h.Ok(t, hydrated.HydrateSpotCacheForTypes([]string{"m5.large"}, 30))
+	h.Ok(t, hydrated.SaveCache(restrictedPath))
+	h.Ok(t, hydrated.HydrateSpotCache(30))
+	h.Ok(t, hydrated.SaveCache(fullPath))
+
+	// the refresh of an expired cache which was loaded keeps the instance types it was saved with
+	loaded, _, spotInputs := setupCacheTTLPricing(t, time.Hour, clock)
+	h.Ok(t, loaded.LoadCache(restrictedPath))
+	clock.current = clock.current.Add(2 * time.Hour)
+	_, err = loaded.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 1, len(*spotInputs))
+	h.Equals(t, []string{"m5.large"}, aws.StringValueSlice((*spotInputs)[0].InstanceTypes))
+
+	// loading a cache of every instance type drops the instance types of the cache it replaces
+	h.Ok(t, loaded.LoadCache(fullPath))
+	clock.current = clock.current.Add(2 * time.Hour)
+	_, err = loaded.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 2, len(*spotInputs))
+	h.Assert(t, (*spotInputs)[1].InstanceTypes == nil, "Expected the loaded spot cache to be refreshed for all instance types")
+}
+
+func TestLoadCache_HydrateSpotCacheForTypesWithoutSpotCache(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "ec2pricing")
+	h.Ok(t, err)
+	defer os.RemoveAll(tmpDir)
+	cachePath := filepath.Join(tmpDir, "cache.json")
+
+	// a spot cache without entries is left out of the file while its hydration time and product descriptions are kept
+	h.Ok(t, newRegionPricing("us-east-1").SaveCache(cachePath))
+	cacheJSON, err := ioutil.ReadFile(cachePath)
+	h.Ok(t, err)
+	contents := map[string]interface{}{}
+	h.Ok(t, json.Unmarshal(cacheJSON, &contents))
+	contents["LastSpotCacheUTC"] = fixtureClock()
+	contents["SpotCacheProductDescriptions"] = []string{"Linux/UNIX"}
+	cacheJSON, err = json.Marshal(contents)
+	h.Ok(t, err)
+	h.Ok(t, ioutil.WriteFile(cachePath, cacheJSON, 0644))
+
+	loaded := newRegionPricing("us-east-1")
+	loaded.EC2Client = setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	h.Ok(t, loaded.LoadCache(cachePath))
+	h.Assert(t, loaded.LastSpotCacheUTC() != nil, "Expected the spot cache to be loaded as hydrated")
+	h.Ok(t, loaded.HydrateSpotCacheForTypes([]string{"m5.large"}, 30))
+	_, err = loaded.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+}
+
+func TestLoadCache_RegionMismatch(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "ec2pricing")
+	h.Ok(t, err)
+	defer os.RemoveAll(tmpDir)
+	cachePath := filepath.Join(tmpDir, "cache.json")
+
+	hydrated := newRegionPricing("us-east-1")
+	hydrated.PricingClient = setupMock(t, getProductsPages, "m5_large.json")
+	h.Ok(t, hydrated.HydrateOndemandCache())
+	h.Ok(t, hydrated.SaveCache(cachePath))
+
+	loaded := newRegionPricing("us-west-2")
+	h.Nok(t, loaded.LoadCache(cachePath))
+	h.Assert(t, loaded.LastOnDemandCacheUTC() == nil, "The on-demand cache should not be loaded for a different region")
+}
+
+func TestLoadCache_MissingFile(t *testing.T) {
+	h.Nok(t, newRegionPricing("us-east-1").LoadCache("does-not-exist.json"))
+}
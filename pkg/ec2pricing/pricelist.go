@@ -0,0 +1,133 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// priceListDoc is a product price document returned in the PriceList of the Pricing API's GetProducts
+// Only the fields which prices are parsed from are decoded, fields which are missing from the document are nil
+type priceListDoc struct {
+	Product *struct {
+		Attributes *productAttributes `json:"attributes"`
+	} `json:"product"`
+	Terms *struct {
+		// OnDemand and Reserved are keyed by offer term code (Example: "6C86BEPQVG73ZGGR.JRTCKXETXF")
+		OnDemand map[string]term `json:"OnDemand"`
+		Reserved map[string]term `json:"Reserved"`
+	} `json:"terms"`
+}
+
+// productAttributes are the attributes of the product a price document is for
+type productAttributes struct {
+	InstanceType *string `json:"instanceType"`
+	// MarketOption is "CapacityBlock" for Capacity Block products, it is missing from the documents of most other products
+	MarketOption *string `json:"marketoption"`
+}
+
+// term is an on-demand or reserved pricing term of a price document
+type term struct {
+	EffectiveDate string `json:"effectiveDate"`
+	// PriceDimensions are keyed by rate code (Example: "6C86BEPQVG73ZGGR.JRTCKXETXF.6YS6EN2CT7")
+	PriceDimensions map[string]priceDimension `json:"priceDimensions"`
+	TermAttributes  termAttributes            `json:"termAttributes"`
+}
+
+// termAttributes identify a reserved pricing term, they are empty for on-demand terms
+type termAttributes struct {
+	LeaseContractLength string `json:"LeaseContractLength"`
+	OfferingClass       string `json:"OfferingClass"`
+	PurchaseOption      string `json:"PurchaseOption"`
+}
+
+// priceDimension is a price of a pricing term, such as the hourly fee (unit "Hrs") or the upfront fee (unit "Quantity")
+type priceDimension struct {
+	Unit string `json:"unit"`
+	// BeginRange is the usage the dimension's tier starts at, which is "0" for the first tier
+	BeginRange string `json:"beginRange"`
+	// PricePerUnit are decimal strings keyed by currency (Example: "USD")
+	PricePerUnit map[string]string `json:"pricePerUnit"`
+}
+
+// decodePriceListDoc decodes a price document from the Pricing API
+// The SDK decodes each document into an aws.JSONValue, so it is re-encoded to decode it into a priceListDoc
+func decodePriceListDoc(priceList aws.JSONValue) (priceListDoc, error) {
+	priceListJSON, err := json.Marshal(priceList)
+	if err != nil {
+		return priceListDoc{}, fmt.Errorf("Unable to encode pricing doc: %w", err)
+	}
+	doc := priceListDoc{}
+	if err := json.Unmarshal(priceListJSON, &doc); err != nil {
+		return priceListDoc{}, fmt.Errorf("Unable to decode pricing doc: %w", err)
+	}
+	return doc, nil
+}
+
+// instanceType returns the instance type the price document is for
+func (d priceListDoc) instanceType() (string, error) {
+	if d.Product == nil || d.Product.Attributes == nil {
+		return "", fmt.Errorf("Unable to find product attributes")
+	}
+	if d.Product.Attributes.InstanceType == nil {
+		return "", fmt.Errorf("Unable to find instance type name from product attributes")
+	}
+	return *d.Product.Attributes.InstanceType, nil
+}
+
+// ondemandHourlyDimension returns the price dimension of an on-demand term which is the base hourly rate
+// A term with a single dimension uses it, and a term with several dimensions (Example: tiered prices) uses the one billed in hours
+// which starts at the first tier. An error describing the ambiguity is returned if none or more than one of the dimensions match
+func (t term) ondemandHourlyDimension() (priceDimension, error) {
+	if len(t.PriceDimensions) == 0 {
+		return priceDimension{}, fmt.Errorf("Unable to find on-demand pricing dimensions")
+	}
+	if len(t.PriceDimensions) == 1 {
+		for _, dimension := range t.PriceDimensions {
+			return dimension, nil
+		}
+	}
+	rateCodes := []string{}
+	for rateCode, dimension := range t.PriceDimensions {
+		if dimension.Unit == "Hrs" && (dimension.BeginRange == "" || dimension.BeginRange == "0") {
+			rateCodes = append(rateCodes, rateCode)
+		}
+	}
+	if len(rateCodes) != 1 {
+		sort.Strings(rateCodes)
+		return priceDimension{}, fmt.Errorf("Unable to choose the hourly rate of %d on-demand pricing dimensions, %d of them are hourly first tier dimensions: %v", len(t.PriceDimensions), len(rateCodes), rateCodes)
+	}
+	return t.PriceDimensions[rateCodes[0]], nil
+}
+
+// price returns the price per unit of the dimension in the currency
+func (d priceDimension) price(currency string) (float64, error) {
+	if d.PricePerUnit == nil {
+		return float64(-1.0), fmt.Errorf("Unable to find price per unit in pricing dimensions")
+	}
+	priceStr, ok := d.PricePerUnit[currency]
+	if !ok {
+		return float64(-1.0), fmt.Errorf("Unable to find price per unit in %s", currency)
+	}
+	price, err := strconv.ParseFloat(priceStr, 64)
+	if err != nil {
+		return float64(-1.0), fmt.Errorf("Could not convert price per unit in %s to a float64", currency)
+	}
+	return price, nil
+}
@@ -0,0 +1,110 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// priceListJSONValue decodes a price document the same way the SDK decodes the PriceList of GetProducts
+func priceListJSONValue(t *testing.T, priceListJSON string) aws.JSONValue {
+	priceList := aws.JSONValue{}
+	h.Ok(t, json.Unmarshal([]byte(priceListJSON), &priceList))
+	return priceList
+}
+
+const validOndemandPriceList = `{
+	"product": {"attributes": {"instanceType": "m5.large"}},
+	"terms": {"OnDemand": {"SKU.TERM": {
+		"effectiveDate": "2021-02-01T00:00:00Z",
+		"priceDimensions": {"SKU.TERM.RATE": {"unit": "Hrs", "pricePerUnit": {"USD": "0.0960000000"}}}
+	}}}
+}`
+
+func TestParseOndemandUnitPrice(t *testing.T) {
+	instanceType, price, err := parseOndemandUnitPrice(priceListJSONValue(t, validOndemandPriceList), "USD")
+	h.Ok(t, err)
+	h.Equals(t, "m5.large", instanceType)
+	h.Equals(t, 0.096, price)
+}
+
+func TestParseOndemandUnitPrice_Malformed(t *testing.T) {
+	malformed := map[string]string{
+		"Unable to find product attributes":                  `{"terms": {"OnDemand": {}}}`,
+		"Unable to find instance type name":                  `{"product": {"attributes": {}}}`,
+		"Unable to decode pricing doc":                       `{"product": {"attributes": {"instanceType": 5}}}`,
+		"Unable to find pricing terms":                       `{"product": {"attributes": {"instanceType": "m5.large"}}}`,
+		"Unable to find on-demand pricing terms":             `{"product": {"attributes": {"instanceType": "m5.large"}}, "terms": {"Reserved": {}}}`,
+		"Unable to find on-demand pricing dimensions":        `{"product": {"attributes": {"instanceType": "m5.large"}}, "terms": {"OnDemand": {"SKU.TERM": {}}}}`,
+		"Unable to find price per unit in pricing dimension": `{"product": {"attributes": {"instanceType": "m5.large"}}, "terms": {"OnDemand": {"SKU.TERM": {"priceDimensions": {"SKU.TERM.RATE": {}}}}}}`,
+		"Unable to find price per unit in USD":               `{"product": {"attributes": {"instanceType": "m5.large"}}, "terms": {"OnDemand": {"SKU.TERM": {"priceDimensions": {"SKU.TERM.RATE": {"pricePerUnit": {"CNY": "0.62"}}}}}}}`,
+		"Could not convert price per unit in USD":            `{"product": {"attributes": {"instanceType": "m5.large"}}, "terms": {"OnDemand": {"SKU.TERM": {"priceDimensions": {"SKU.TERM.RATE": {"pricePerUnit": {"USD": "free"}}}}}}}`,
+		"Unable to parse pricing doc":                        `{"product": {"attributes": {"instanceType": "m5.large"}}, "terms": {"OnDemand": {}}}`,
+		// documents with more than one candidate price are ambiguous
+		"Unable to choose between 2 on-demand pricing terms": `{"product": {"attributes": {"instanceType": "m5.large"}}, "terms": {"OnDemand": {"SKU.TERM1": {}, "SKU.TERM2": {}}}}`,
+		"Unable to choose the hourly rate of 2 on-demand":    `{"product": {"attributes": {"instanceType": "m5.large"}}, "terms": {"OnDemand": {"SKU.TERM": {"priceDimensions": {"SKU.TERM.RATE1": {"unit": "Hrs", "beginRange": "0"}, "SKU.TERM.RATE2": {"unit": "Hrs", "beginRange": "0"}}}}}}`,
+		"0 of them are hourly first tier dimensions: []":     `{"product": {"attributes": {"instanceType": "m5.large"}}, "terms": {"OnDemand": {"SKU.TERM": {"priceDimensions": {"SKU.TERM.RATE1": {"unit": "Quantity"}, "SKU.TERM.RATE2": {"unit": "Hrs", "beginRange": "744"}}}}}}`,
+		// documents which are not shaped like a price document used to panic
+		"Unable to decode pricing doc: json":                   `{"product": "m5.large"}`,
+		"Unable to decode pricing doc: json: cannot unmarshal": `{"product": {"attributes": {"instanceType": "m5.large"}}, "terms": {"OnDemand": {"SKU.TERM": {"priceDimensions": ["0.096"]}}}}`,
+	}
+	for expectedErr, priceListJSON := range malformed {
+		_, price, err := parseOndemandUnitPrice(priceListJSONValue(t, priceListJSON), "USD")
+		h.Assert(t, err != nil && strings.Contains(err.Error(), expectedErr), "Expected an error containing %q for %s, got %v", expectedErr, priceListJSON, err)
+		h.Equals(t, float64(-1), price)
+	}
+}
+
+func TestParseOndemandUnitPriceAsOf_Malformed(t *testing.T) {
+	date := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	effectiveDate, price, err := parseOndemandUnitPriceAsOf(priceListJSONValue(t, validOndemandPriceList), date, "USD")
+	h.Ok(t, err)
+	h.Assert(t, effectiveDate != nil && effectiveDate.Equal(time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)), "Expected the term's effective date, got %v", effectiveDate)
+	h.Equals(t, 0.096, price)
+
+	malformed := map[string]string{
+		"Unable to find effective date":          `{"terms": {"OnDemand": {"SKU.TERM": {"priceDimensions": {}}}}}`,
+		"Could not parse on-demand pricing term": `{"terms": {"OnDemand": {"SKU.TERM": {"effectiveDate": "February 1st"}}}}`,
+		"Unable to decode pricing doc":           `{"terms": {"OnDemand": {"SKU.TERM": {"effectiveDate": 20210201}}}}`,
+		"Unable to find on-demand pricing terms": `{"terms": {}}`,
+		"Unable to find price per unit in USD":   `{"terms": {"OnDemand": {"SKU.TERM": {"effectiveDate": "2021-02-01T00:00:00Z", "priceDimensions": {"SKU.TERM.RATE": {"pricePerUnit": {}}}}}}}`,
+	}
+	for expectedErr, priceListJSON := range malformed {
+		_, _, err := parseOndemandUnitPriceAsOf(priceListJSONValue(t, priceListJSON), date, "USD")
+		h.Assert(t, err != nil && strings.Contains(err.Error(), expectedErr), "Expected an error containing %q for %s, got %v", expectedErr, priceListJSON, err)
+	}
+}
+
+func TestParseReservedHourlyCost_Malformed(t *testing.T) {
+	_, found, err := parseReservedHourlyCost(priceListJSONValue(t, validOndemandPriceList), ReservedTerm1Yr, ReservedPaymentNoUpfront, "USD")
+	h.Ok(t, err)
+	h.Assert(t, !found, "Expected no reserved term to be found in a document without reserved terms")
+
+	malformed := map[string]string{
+		"unable to find pricing terms":         `{}`,
+		"unable to find reserved pricing":      `{"terms": {"Reserved": {"SKU.TERM": {"termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "No Upfront"}}}}}`,
+		"unable to parse reserved price":       `{"terms": {"Reserved": {"SKU.TERM": {"termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "No Upfront"}, "priceDimensions": {"SKU.TERM.RATE": {"unit": "Hrs", "pricePerUnit": {"USD": ""}}}}}}}`,
+		"reserved pricing dimension with unit": `{"terms": {"Reserved": {"SKU.TERM": {"termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "No Upfront"}, "priceDimensions": {"SKU.TERM.RATE": {"unit": "Days", "pricePerUnit": {"USD": "1.0"}}}}}}}`,
+	}
+	for expectedErr, priceListJSON := range malformed {
+		_, _, err := parseReservedHourlyCost(priceListJSONValue(t, priceListJSON), ReservedTerm1Yr, ReservedPaymentNoUpfront, "USD")
+		h.Assert(t, err != nil && strings.Contains(err.Error(), expectedErr), "Expected an error containing %q for %s, got %v", expectedErr, priceListJSON, err)
+	}
+}
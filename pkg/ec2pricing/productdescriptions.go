@@ -0,0 +1,93 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// spotProductDescriptions are the product descriptions which the spot-pricing-history api accepts
+var spotProductDescriptions = map[string]bool{
+	"Linux/UNIX":                            true,
+	"Linux/UNIX (Amazon VPC)":               true,
+	"Red Hat Enterprise Linux":              true,
+	"Red Hat Enterprise Linux (Amazon VPC)": true,
+	"SUSE Linux":                            true,
+	"SUSE Linux (Amazon VPC)":               true,
+	"Windows":                               true,
+	"Windows (Amazon VPC)":                  true,
+}
+
+// SupportedSpotProductDescriptions returns the product descriptions which spot price history can be retrieved for sorted alpha-numerically
+func SupportedSpotProductDescriptions() []string {
+	supported := []string{}
+	for product := range spotProductDescriptions {
+		supported = append(supported, product)
+	}
+	sort.Strings(supported)
+	return supported
+}
+
+// WithSpotProductDescription sets the product description which spot price history is retrieved for, product descriptions which
+// are not one of the SupportedSpotProductDescriptions are ignored and the OperatingSystem's product description is used instead
+func WithSpotProductDescription(productDescription string) Option {
+	return func(p *EC2Pricing) {
+		if err := p.SetSpotProductDescription(productDescription); err != nil {
+			p.warnOption("%v, using the product description of operating system %s", err, p.OperatingSystem())
+		}
+	}
+}
+
+// SetSpotProductDescription sets the product description which spot price history is retrieved for (Example: "SUSE Linux (Amazon VPC)")
+// instead of the product description of the OperatingSystem, on-demand prices are not affected
+// Passing an empty string goes back to the OperatingSystem's product description
+// Changing the product description clears the spot cache since it holds the previous product description's history
+// An error is returned if the product description is not one of the SupportedSpotProductDescriptions
+func (p *EC2Pricing) SetSpotProductDescription(productDescription string) error {
+	if productDescription != "" {
+		if err := validateSpotProductDescriptions([]string{productDescription}); err != nil {
+			return err
+		}
+	}
+	if productDescription == p.spotProductDescriptionOverride {
+		return nil
+	}
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+	p.spotProductDescriptionOverride = productDescription
+	p.spotCache = nil
+	p.lastSpotCacheUTC = nil
+	return nil
+}
+
+// SpotProductDescription returns the product description which spot price history is retrieved for, which is the product
+// description of the OperatingSystem unless one was set with SetSpotProductDescription
+func (p *EC2Pricing) SpotProductDescription() string {
+	if p.spotProductDescriptionOverride != "" {
+		return p.spotProductDescriptionOverride
+	}
+	return p.operatingSystemPricing().spotProductDescription
+}
+
+// validateSpotProductDescriptions returns an error naming the first product description which is not one of the SupportedSpotProductDescriptions
+func validateSpotProductDescriptions(productDescriptions []string) error {
+	for _, product := range productDescriptions {
+		if !spotProductDescriptions[product] {
+			return fmt.Errorf("spot product description %q is not supported, it must be one of: %s", product, strings.Join(SupportedSpotProductDescriptions(), ", "))
+		}
+	}
+	return nil
+}
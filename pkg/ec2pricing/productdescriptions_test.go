@@ -0,0 +1,78 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestSetSpotProductDescription(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large_multi_os.json")
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &[]*ec2.DescribeSpotPriceHistoryInput{}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	h.Equals(t, "Linux/UNIX (Amazon VPC)", ec2pricingClient.SpotProductDescription())
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+
+	h.Ok(t, ec2pricingClient.SetSpotProductDescription("Red Hat Enterprise Linux (Amazon VPC)"))
+	h.Equals(t, "Red Hat Enterprise Linux (Amazon VPC)", ec2pricingClient.SpotProductDescription())
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() == nil, "Changing the spot product description should clear the spot cache")
+	// the operating system is unchanged so on-demand prices are still retrieved for linux
+	h.Equals(t, ec2pricing.OperatingSystemLinux, ec2pricingClient.OperatingSystem())
+
+	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1b"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-0.02) < 1e-9, "Expected the Red Hat Enterprise Linux average, got %f", price)
+	h.Equals(t, []*string{aws.String("Red Hat Enterprise Linux (Amazon VPC)")}, (*ec2Mock.DescribeSpotPriceHistoryPagesInputs)[1].ProductDescriptions)
+
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	h.Equals(t, []*string{aws.String("Red Hat Enterprise Linux (Amazon VPC)")}, (*ec2Mock.DescribeSpotPriceHistoryPagesInputs)[2].ProductDescriptions)
+
+	// an empty product description goes back to the operating system's
+	h.Ok(t, ec2pricingClient.SetSpotProductDescription(""))
+	h.Equals(t, "Linux/UNIX (Amazon VPC)", ec2pricingClient.SpotProductDescription())
+}
+
+func TestSetSpotProductDescription_Unsupported(t *testing.T) {
+	ec2pricingClient := ec2pricing.EC2Pricing{}
+	h.Nok(t, ec2pricingClient.SetSpotProductDescription("Linux"))
+	h.Equals(t, "Linux/UNIX (Amazon VPC)", ec2pricingClient.SpotProductDescription())
+	h.Nok(t, ec2pricingClient.HydrateSpotCacheForProductDescriptions(30, []string{"Windows (Amazon VPC)", "Windows Server"}))
+	_, _, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostForProductDescriptions("m5.large", []string{"FreeBSD"}, []string{}, 30)
+	h.Nok(t, err)
+}
+
+func TestWithSpotProductDescription(t *testing.T) {
+	ec2pricingClient := ec2pricing.EC2Pricing{}
+	ec2pricing.WithSpotProductDescription("SUSE Linux (Amazon VPC)")(&ec2pricingClient)
+	h.Equals(t, "SUSE Linux (Amazon VPC)", ec2pricingClient.SpotProductDescription())
+	// unsupported product descriptions are ignored
+	ec2pricing.WithSpotProductDescription("SUSE")(&ec2pricingClient)
+	h.Equals(t, "SUSE Linux (Amazon VPC)", ec2pricingClient.SpotProductDescription())
+}
@@ -0,0 +1,85 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"fmt"
+)
+
+// RefreshOndemandInstanceType queries the Pricing API for the current on-demand price of a single instance type and replaces its
+// entry in the on-demand cache, adding it if it is not cached yet
+// The rest of the cache and the LastOnDemandCacheUTC are left untouched, and the cache is kept as is if the price cannot be retrieved
+func (p *EC2Pricing) RefreshOndemandInstanceType(instanceType string) error {
+	return p.RefreshOndemandInstanceTypeWithContext(context.Background(), instanceType)
+}
+
+// RefreshOndemandInstanceTypeWithContext is like RefreshOndemandInstanceType but the Pricing API requests are canceled when the
+// context is done
+func (p *EC2Pricing) RefreshOndemandInstanceTypeWithContext(ctx context.Context, instanceType string) error {
+	price, err := p.getOndemandInstanceTypeCost(ctx, instanceType)
+	if err == errEmptyPriceList {
+		return fmt.Errorf("%w for instance type %s", ErrNoOndemandPrice, instanceType)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to refresh the on-demand price of instance type %s: %w", instanceType, err)
+	}
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+	p.insertOndemandCacheEntry(instanceType, price)
+	p.log().Debugf("refreshed the on-demand price cache entry of instance type %s", instanceType)
+	return nil
+}
+
+// RefreshSpotInstanceType queries the spot-pricing-history api for the past N days of spot price history of a single instance type
+// and replaces its entries in the spot cache for each of the product descriptions the cache was hydrated with
+// The rest of the cache and the LastSpotCacheUTC are left untouched, so the days should match the days the cache was hydrated with
+// An error is returned if the spot cache has not been hydrated since there is no history window to refresh the entry within
+func (p *EC2Pricing) RefreshSpotInstanceType(instanceType string, days int) error {
+	return p.RefreshSpotInstanceTypeWithContext(context.Background(), instanceType, days)
+}
+
+// RefreshSpotInstanceTypeWithContext is like RefreshSpotInstanceType but the spot-pricing-history api requests are canceled when
+// the context is done
+func (p *EC2Pricing) RefreshSpotInstanceTypeWithContext(ctx context.Context, instanceType string, days int) error {
+	if err := validateSpotDays(days); err != nil {
+		return err
+	}
+	if p.LastSpotCacheUTC() == nil {
+		return fmt.Errorf("the spot price cache must be hydrated before instance type %s can be refreshed", instanceType)
+	}
+	p.cacheMu.RLock()
+	productDescriptions := p.spotCacheProductDescriptions
+	p.cacheMu.RUnlock()
+	if len(productDescriptions) == 0 {
+		productDescriptions = []string{p.SpotProductDescription()}
+	}
+	productToZoneEntries, _, err := p.querySpotPricingEntries(ctx, instanceType, productDescriptions, days)
+	if err != nil {
+		return fmt.Errorf("unable to refresh the spot price history of instance type %s: %w", instanceType, err)
+	}
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+	if p.spotCache == nil {
+		p.spotCache = make(map[string]map[string]map[string][]SpotPricingEntry)
+	}
+	for product, zoneToPriceEntries := range productToZoneEntries {
+		if p.spotCache[product] == nil {
+			p.spotCache[product] = make(map[string]map[string][]SpotPricingEntry)
+		}
+		p.spotCache[product][instanceType] = zoneToPriceEntries
+	}
+	p.log().Debugf("refreshed the spot price cache entries of instance type %s", instanceType)
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+func spotPriceHistory(instanceType string, zone string, price string, timestamp time.Time) *ec2.SpotPrice {
+	return &ec2.SpotPrice{
+		AvailabilityZone:   aws.String(zone),
+		InstanceType:       aws.String(instanceType),
+		ProductDescription: aws.String("Linux/UNIX"),
+		SpotPrice:          aws.String(price),
+		Timestamp:          aws.Time(timestamp),
+	}
+}
+
+func TestRefreshOndemandInstanceType(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: mockedPricing{
+			GetProductsPagesCalls: new(int),
+			GetProductsPagesRespPages: []pricing.GetProductsOutput{
+				{PriceList: []aws.JSONValue{productsPriceDoc(t, "m5.large", "0.0960000000"), productsPriceDoc(t, "c5.large", "0.0850000000")}},
+			},
+		},
+		AWSSession: &sess,
+	}
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	lastOnDemandCacheUTC := ec2pricingClient.LastOnDemandCacheUTC()
+
+	// the price of m5.large changes after the cache was hydrated
+	refreshedMock := mockedPricing{
+		GetProductsPagesInputs: &[]*pricing.GetProductsInput{},
+		GetProductsPagesRespPages: []pricing.GetProductsOutput{
+			{PriceList: []aws.JSONValue{productsPriceDoc(t, "m5.large", "0.0900000000"), productsPriceDoc(t, "c5.large", "0.0800000000")}},
+		},
+	}
+	ec2pricingClient.PricingClient = refreshedMock
+	h.Ok(t, ec2pricingClient.RefreshOndemandInstanceType("m5.large"))
+	h.Equals(t, 1, len(*refreshedMock.GetProductsPagesInputs))
+	h.Equals(t, "m5.large", getProductsFilterValue((*refreshedMock.GetProductsPagesInputs)[0], "instanceType"))
+	h.Equals(t, lastOnDemandCacheUTC, ec2pricingClient.LastOnDemandCacheUTC())
+
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, 0.09, price)
+	// the other entries keep their cached price
+	price, err = ec2pricingClient.GetOndemandInstanceTypeCost("c5.large")
+	h.Ok(t, err)
+	h.Equals(t, 0.085, price)
+	h.Equals(t, 1, len(*refreshedMock.GetProductsPagesInputs))
+
+	err = ec2pricingClient.RefreshOndemandInstanceType("z1d.large")
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoOndemandPrice), "Expected ErrNoOndemandPrice, got %v", err)
+}
+
+func TestRefreshSpotInstanceType(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	start := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client: mockedPricing{
+			DescribeSpotPriceHistoryPagesResp: ec2.DescribeSpotPriceHistoryOutput{
+				SpotPriceHistory: []*ec2.SpotPrice{
+					spotPriceHistory("m5.large", "us-east-1a", "0.040000", start),
+					spotPriceHistory("c5.large", "us-east-1a", "0.030000", start),
+				},
+			},
+		},
+		AWSSession: &sess,
+		Clock:      func() time.Time { return time.Date(2021, 2, 11, 0, 0, 0, 0, time.UTC) },
+	}
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	lastSpotCacheUTC := ec2pricingClient.LastSpotCacheUTC()
+
+	// the spot price of m5.large doubled halfway through the window
+	refreshedMock := mockedPricing{
+		DescribeSpotPriceHistoryPagesInputs: &[]*ec2.DescribeSpotPriceHistoryInput{},
+		DescribeSpotPriceHistoryPagesResp: ec2.DescribeSpotPriceHistoryOutput{
+			SpotPriceHistory: []*ec2.SpotPrice{
+				spotPriceHistory("m5.large", "us-east-1a", "0.080000", start.Add(5*24*time.Hour)),
+				spotPriceHistory("m5.large", "us-east-1a", "0.040000", start),
+			},
+		},
+	}
+	ec2pricingClient.EC2Client = refreshedMock
+	h.Ok(t, ec2pricingClient.RefreshSpotInstanceType("m5.large", 30))
+	h.Equals(t, 1, len(*refreshedMock.DescribeSpotPriceHistoryPagesInputs))
+	h.Equals(t, []*string{aws.String("m5.large")}, (*refreshedMock.DescribeSpotPriceHistoryPagesInputs)[0].InstanceTypes)
+	h.Equals(t, lastSpotCacheUTC, ec2pricingClient.LastSpotCacheUTC())
+
+	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 0.06, price)
+	// the other entries keep their cached history
+	price, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("c5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 0.03, price)
+	h.Equals(t, 1, len(*refreshedMock.DescribeSpotPriceHistoryPagesInputs))
+}
+
+func TestRefreshSpotInstanceType_NotHydrated(t *testing.T) {
+	ec2pricingClient := ec2pricing.EC2Pricing{}
+	h.Nok(t, ec2pricingClient.RefreshSpotInstanceType("m5.large", 30))
+}
+
+func TestRefreshInstanceTypeWithContext_Canceled(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:         fixtureClock,
+		PricingClient: setupMock(t, getProductsPages, "m5_large.json"),
+		EC2Client:     setupMock(t, describeSpotPriceHistoryPages, "m5_large.json"),
+		AWSSession:    &sess,
+	}
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := ec2pricingClient.RefreshOndemandInstanceTypeWithContext(ctx, "m5.large")
+	h.Assert(t, errors.Is(err, context.Canceled), "Expected the on-demand refresh to be canceled, got %v", err)
+	h.Equals(t, 0, len(ec2pricingClient.OnDemandCacheSnapshot()))
+	err = ec2pricingClient.RefreshSpotInstanceTypeWithContext(ctx, "m5.large", 30)
+	h.Assert(t, errors.Is(err, context.Canceled), "Expected the spot refresh to be canceled, got %v", err)
+}
@@ -0,0 +1,102 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+// PricingReportRecord is the pricing of a single instance type in the JSON report of PricingReportJSON
+// Prices which are not available are null rather than -1
+type PricingReportRecord struct {
+	InstanceType string `json:"InstanceType"`
+	// OnDemandHourly is the hourly on-demand price in the OnDemandCurrency
+	OnDemandHourly   *float64 `json:"OnDemandHourly"`
+	OnDemandCurrency string   `json:"OnDemandCurrency"`
+	// SpotAvgHourly is the time weighted average hourly spot price across the zones over the Days window in the SpotCurrency
+	SpotAvgHourly *float64 `json:"SpotAvgHourly"`
+	SpotCurrency  string   `json:"SpotCurrency"`
+	// SpotSavingsPct is the percentage saved by running as spot rather than on-demand, it is null if either price is not available or
+	// the spot price cannot be converted to the OnDemandCurrency
+	SpotSavingsPct *float64 `json:"SpotSavingsPct"`
+	// Days is the length of the spot price history window, and SpotEarliestSample and SpotLatestSample are the timestamps of the
+	// oldest and newest spot price samples within it which were averaged
+	Days               int        `json:"Days"`
+	SpotEarliestSample *time.Time `json:"SpotEarliestSample"`
+	SpotLatestSample   *time.Time `json:"SpotLatestSample"`
+}
+
+// PricingReportJSON retrieves the on-demand price and the N day average spot price of each instance type with GetInstanceTypePricing
+// and marshals them into a JSON array of PricingReportRecord in the order of the instance types
+// The on-demand and spot caches are hydrated first if they have not been already. An instance type without an on-demand price or
+// without spot price history in the zones is reported with null prices, any other error of the lookups is returned instead of the report.
+// Passing an empty list for zones will retrieve spot prices for all AZs in the current AWSSession's region
+func (p *EC2Pricing) PricingReportJSON(instanceTypes []string, zones []string, days int) ([]byte, error) {
+	return p.PricingReportJSONWithContext(context.Background(), instanceTypes, zones, days)
+}
+
+// PricingReportJSONWithContext is like PricingReportJSON but the Pricing API and spot-pricing-history api requests are canceled
+// when the context is done
+func (p *EC2Pricing) PricingReportJSONWithContext(ctx context.Context, instanceTypes []string, zones []string, days int) ([]byte, error) {
+	if err := validateSpotDays(days); err != nil {
+		return nil, err
+	}
+	p.hydrateMissingCaches(ctx, days)
+
+	records := make([]PricingReportRecord, 0, len(instanceTypes))
+	var errs error
+	for _, instanceType := range instanceTypes {
+		instanceTypePricing, err := p.GetInstanceTypePricingWithContext(ctx, instanceType, zones, days)
+		for _, lookupErr := range multierr.Errors(err) {
+			if !errors.Is(lookupErr, ErrNoSpotPriceHistory) {
+				errs = multierr.Append(errs, lookupErr)
+			}
+		}
+		record := PricingReportRecord{
+			InstanceType:     instanceType,
+			OnDemandCurrency: p.OndemandCurrency(),
+			SpotCurrency:     p.SpotCurrency(),
+			Days:             days,
+		}
+		if instanceTypePricing != nil {
+			record.OnDemandHourly = reportPrice(instanceTypePricing.OnDemandHourly)
+			record.SpotAvgHourly = reportPrice(instanceTypePricing.SpotAvgHourly)
+			if _, ok := p.spotPriceInOndemandCurrency(instanceTypePricing.SpotAvgHourly); ok && record.SpotAvgHourly != nil && instanceTypePricing.OnDemandHourly > 0 {
+				record.SpotSavingsPct = &instanceTypePricing.SpotSavingsPct
+			}
+			if record.SpotAvgHourly != nil {
+				record.SpotEarliestSample = &instanceTypePricing.SpotEarliestSample
+				record.SpotLatestSample = &instanceTypePricing.SpotLatestSample
+			}
+		}
+		records = append(records, record)
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return json.Marshal(records)
+}
+
+// reportPrice returns nil for the -1 of a price which is not available
+func reportPrice(price float64) *float64 {
+	if price < 0 {
+		return nil
+	}
+	return &price
+}
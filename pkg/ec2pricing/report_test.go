@@ -0,0 +1,62 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+)
+
+func TestPricingReportJSON(t *testing.T) {
+	ec2pricingClient, _ := setupBatchPricing(t)
+	ec2pricingClient.Clock = fixtureClock
+	ec2pricingClient.EC2Client = setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+
+	reportJSON, err := ec2pricingClient.PricingReportJSON([]string{"m5.large", "c5.large"}, []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, json.Valid(reportJSON), "Expected a valid JSON report, got %s", reportJSON)
+
+	records := []ec2pricing.PricingReportRecord{}
+	h.Ok(t, json.Unmarshal(reportJSON, &records))
+	h.Equals(t, 2, len(records))
+	m5Large := records[0]
+	h.Equals(t, "m5.large", m5Large.InstanceType)
+	h.Equals(t, 0.096, *m5Large.OnDemandHourly)
+	h.Assert(t, *m5Large.SpotAvgHourly > 0 && *m5Large.SpotAvgHourly < 0.096, "Expected a spot price below the on-demand price, got %f", *m5Large.SpotAvgHourly)
+	h.Assert(t, *m5Large.SpotSavingsPct > 0, "Expected spot savings, got %f", *m5Large.SpotSavingsPct)
+	h.Equals(t, 30, m5Large.Days)
+	h.Assert(t, m5Large.SpotEarliestSample.Before(*m5Large.SpotLatestSample), "Expected the spot samples to span a window")
+
+	// c5.large has no spot price history, so its spot pricing is null rather than -1
+	c5Large := records[1]
+	h.Equals(t, "c5.large", c5Large.InstanceType)
+	h.Equals(t, 0.085, *c5Large.OnDemandHourly)
+	h.Assert(t, c5Large.SpotAvgHourly == nil, "Expected a null spot price")
+	h.Assert(t, c5Large.SpotSavingsPct == nil, "Expected null spot savings")
+	h.Assert(t, c5Large.SpotEarliestSample == nil && c5Large.SpotLatestSample == nil, "Expected a null spot sample window")
+
+	rawRecords := []map[string]interface{}{}
+	h.Ok(t, json.Unmarshal(reportJSON, &rawRecords))
+	spotAvg, ok := rawRecords[1]["SpotAvgHourly"]
+	h.Assert(t, ok && spotAvg == nil, "Expected SpotAvgHourly to be null, got %v", spotAvg)
+}
+
+func TestPricingReportJSON_Errors(t *testing.T) {
+	ec2pricingClient, _ := setupBatchPricing(t)
+	_, err := ec2pricingClient.PricingReportJSON([]string{"m5.large"}, nil, 0)
+	h.Nok(t, err)
+}
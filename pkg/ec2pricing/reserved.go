@@ -0,0 +1,173 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+// Reserved instance terms which prices can be retrieved for
+const (
+	ReservedTerm1Yr = "1yr"
+	ReservedTerm3Yr = "3yr"
+)
+
+// Reserved instance payment options which prices can be retrieved for
+const (
+	ReservedPaymentNoUpfront      = "No Upfront"
+	ReservedPaymentPartialUpfront = "Partial Upfront"
+	ReservedPaymentAllUpfront     = "All Upfront"
+)
+
+const (
+	// reservedOfferingClass is the offering class of the reserved instance prices, convertible reserved instances are not supported
+	reservedOfferingClass = "standard"
+	hoursPerYear          = 365 * 24
+)
+
+// reservedTermHours maps each supported reserved instance term to the number of hours its upfront fee is amortized over
+var reservedTermHours = map[string]float64{
+	ReservedTerm1Yr: hoursPerYear,
+	ReservedTerm3Yr: 3 * hoursPerYear,
+}
+
+// reservedPaymentOptions are the supported reserved instance payment options
+var reservedPaymentOptions = []string{ReservedPaymentNoUpfront, ReservedPaymentPartialUpfront, ReservedPaymentAllUpfront}
+
+// ErrNoReservedPrice is returned when the Pricing API does not have a reserved instance price for an instance type
+var ErrNoReservedPrice = errors.New("no reserved instance price found")
+
+// GetReservedInstanceTypeCost retrieves the effective hourly cost of a standard reserved instance of the instance type in the
+// OndemandCurrency, which is the hourly fee plus the upfront fee amortized over every hour of the term
+// The term is one of ReservedTerm1Yr or ReservedTerm3Yr and the paymentOption is one of ReservedPaymentNoUpfront,
+// ReservedPaymentPartialUpfront, or ReservedPaymentAllUpfront
+// Reserved instance prices are not cached, so each call queries the Pricing API
+// An ErrNoReservedPrice error is returned if the Pricing API does not have a price for the instance type, term, and payment option
+func (p *EC2Pricing) GetReservedInstanceTypeCost(instanceType string, term string, paymentOption string) (float64, error) {
+	return p.GetReservedInstanceTypeCostWithContext(context.Background(), instanceType, term, paymentOption)
+}
+
+// GetReservedInstanceTypeCostWithContext is like GetReservedInstanceTypeCost but the Pricing API request is canceled when the context is done
+func (p *EC2Pricing) GetReservedInstanceTypeCostWithContext(ctx context.Context, instanceType string, term string, paymentOption string) (float64, error) {
+	if _, ok := reservedTermHours[term]; !ok {
+		return -1, fmt.Errorf("reserved instance term %q is not supported, it must be one of: %s, %s", term, ReservedTerm1Yr, ReservedTerm3Yr)
+	}
+	if !isReservedPaymentOption(paymentOption) {
+		return -1, fmt.Errorf("reserved instance payment option %q is not supported, it must be one of: %s", paymentOption, strings.Join(reservedPaymentOptions, ", "))
+	}
+	productInput, err := p.getOndemandProductsInput(instanceType)
+	if err != nil {
+		return -1, err
+	}
+
+	if p.planAPICall(APIGetProducts, &productInput) {
+		return -1, fmt.Errorf("%w for the reserved instance cost of instance type %s", ErrDryRun, instanceType)
+	}
+	cost := float64(-1)
+	var processingErr error
+	var errCtx error
+	apiCallStart := time.Now()
+	errAPI := p.getProductsPages(ctx, &productInput, func(pricingOutput *pricing.GetProductsOutput, nextPage bool) bool {
+		if err := ctx.Err(); err != nil {
+			errCtx = err
+			return false
+		}
+		for _, priceDoc := range pricingOutput.PriceList {
+			reservedCost, found, errParse := parseReservedHourlyCost(priceDoc, term, paymentOption, p.OndemandCurrency())
+			if errParse != nil {
+				p.log().Warnf("unable to parse a reserved instance price document of instance type %s: %v", instanceType, errParse)
+				processingErr = errParse
+				continue
+			}
+			if found {
+				cost = reservedCost
+				return false
+			}
+		}
+		return true
+	})
+	p.observe().OnAPICall(APIGetProducts, time.Since(apiCallStart))
+	if errAPI == nil {
+		errAPI = errCtx
+	}
+	if errAPI != nil {
+		return -1, errAPI
+	}
+	if cost >= 0 {
+		return cost, nil
+	}
+	if processingErr != nil {
+		return -1, processingErr
+	}
+	return -1, fmt.Errorf("%w for instance type %s with a %s %s term", ErrNoReservedPrice, instanceType, term, paymentOption)
+}
+
+// isReservedPaymentOption returns true if the payment option is one of the supported reserved instance payment options
+func isReservedPaymentOption(paymentOption string) bool {
+	for _, supported := range reservedPaymentOptions {
+		if paymentOption == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// parseReservedHourlyCost finds the standard reserved term matching the term and payment option in a price document from the
+// Pricing API and returns its effective hourly cost in the currency, the termLength is one of ReservedTerm1Yr or ReservedTerm3Yr
+// Reserved terms are keyed by offer term code, so they are matched by their LeaseContractLength, OfferingClass, and PurchaseOption
+// term attributes. Each term has an hourly price dimension (unit "Hrs") and, unless it has no upfront fee, an upfront price
+// dimension (unit "Quantity") which is amortized over the hours of the term. false is returned if no term matches.
+func parseReservedHourlyCost(priceList aws.JSONValue, termLength string, paymentOption string, currency string) (float64, bool, error) {
+	doc, err := decodePriceListDoc(priceList)
+	if err != nil {
+		return -1, false, err
+	}
+	if doc.Terms == nil {
+		return -1, false, fmt.Errorf("unable to find pricing terms")
+	}
+	// instance types without reserved instances do not list any reserved terms
+	for _, reservedTerm := range doc.Terms.Reserved {
+		attributes := reservedTerm.TermAttributes
+		if attributes.LeaseContractLength != termLength || attributes.OfferingClass != reservedOfferingClass || attributes.PurchaseOption != paymentOption {
+			continue
+		}
+		if reservedTerm.PriceDimensions == nil {
+			return -1, false, fmt.Errorf("unable to find reserved pricing dimensions")
+		}
+		hourlyCost := float64(0)
+		for _, dimension := range reservedTerm.PriceDimensions {
+			price, err := dimension.price(currency)
+			if err != nil {
+				return -1, false, fmt.Errorf("unable to parse reserved price: %w", err)
+			}
+			switch dimension.Unit {
+			case "Hrs":
+				hourlyCost += price
+			case "Quantity":
+				hourlyCost += price / reservedTermHours[termLength]
+			default:
+				return -1, false, fmt.Errorf("unable to parse reserved pricing dimension with unit %q", dimension.Unit)
+			}
+		}
+		return hourlyCost, true, nil
+	}
+	return -1, false, nil
+}
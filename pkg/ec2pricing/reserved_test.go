@@ -0,0 +1,93 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func TestGetReservedInstanceTypeCost(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: setupMock(t, getProductsPages, "m5_large.json"),
+		AWSSession:    &sess,
+	}
+
+	cost, err := ec2pricingClient.GetReservedInstanceTypeCost("m5.large", ec2pricing.ReservedTerm1Yr, ec2pricing.ReservedPaymentNoUpfront)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(cost-0.06) < 1e-9, "Expected the 1yr no upfront hourly fee, got %f", cost)
+
+	// the upfront fee is amortized over the 8760 hours of a year, the convertible term with the same payment option is ignored
+	cost, err = ec2pricingClient.GetReservedInstanceTypeCost("m5.large", ec2pricing.ReservedTerm1Yr, ec2pricing.ReservedPaymentPartialUpfront)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(cost-(0.029+252.0/8760)) < 1e-9, "Expected the amortized 1yr partial upfront cost, got %f", cost)
+
+	cost, err = ec2pricingClient.GetReservedInstanceTypeCost("m5.large", ec2pricing.ReservedTerm1Yr, ec2pricing.ReservedPaymentAllUpfront)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(cost-494.0/8760) < 1e-9, "Expected the amortized 1yr all upfront cost, got %f", cost)
+
+	cost, err = ec2pricingClient.GetReservedInstanceTypeCost("m5.large", ec2pricing.ReservedTerm3Yr, ec2pricing.ReservedPaymentNoUpfront)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(cost-0.041) < 1e-9, "Expected the 3yr no upfront hourly fee, got %f", cost)
+
+	cost, err = ec2pricingClient.GetReservedInstanceTypeCost("m5.large", ec2pricing.ReservedTerm3Yr, ec2pricing.ReservedPaymentPartialUpfront)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(cost-(0.019+505.0/26280)) < 1e-9, "Expected the amortized 3yr partial upfront cost, got %f", cost)
+
+	cost, err = ec2pricingClient.GetReservedInstanceTypeCost("m5.large", ec2pricing.ReservedTerm3Yr, ec2pricing.ReservedPaymentAllUpfront)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(cost-949.0/26280) < 1e-9, "Expected the amortized 3yr all upfront cost, got %f", cost)
+}
+
+func TestGetReservedInstanceTypeCost_NoReservedTerms(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: setupMock(t, getProductsPages, "mac1_metal.json"),
+		AWSSession:    &sess,
+	}
+	cost, err := ec2pricingClient.GetReservedInstanceTypeCost("mac1.metal", ec2pricing.ReservedTerm1Yr, ec2pricing.ReservedPaymentNoUpfront)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoReservedPrice), "Expected ErrNoReservedPrice, got %v", err)
+	h.Equals(t, float64(-1), cost)
+}
+
+func TestGetReservedInstanceTypeCost_Unsupported(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: setupMock(t, getProductsPages, "m5_large.json"),
+		AWSSession:    &sess,
+	}
+	_, err := ec2pricingClient.GetReservedInstanceTypeCost("m5.large", "5yr", ec2pricing.ReservedPaymentNoUpfront)
+	h.Nok(t, err)
+	_, err = ec2pricingClient.GetReservedInstanceTypeCost("m5.large", ec2pricing.ReservedTerm1Yr, "no-upfront")
+	h.Nok(t, err)
+}
@@ -0,0 +1,138 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/savingsplans"
+)
+
+// Savings plan types which rates can be retrieved for
+const (
+	// SavingsPlanTypeCompute rates apply to any instance family in any region
+	SavingsPlanTypeCompute = savingsplans.SavingsPlanTypeCompute
+	// SavingsPlanTypeEC2Instance rates apply to a single instance family in a single region and are lower than Compute rates
+	SavingsPlanTypeEC2Instance = savingsplans.SavingsPlanTypeEc2instance
+)
+
+// savingsPlanTermSeconds maps each supported term to the duration of the savings plan offerings in seconds
+// Savings plan terms are the same lengths as reserved instance terms, so they are identified by ReservedTerm1Yr and ReservedTerm3Yr
+var savingsPlanTermSeconds = map[string]int64{
+	ReservedTerm1Yr: hoursPerYear * 60 * 60,
+	ReservedTerm3Yr: 3 * hoursPerYear * 60 * 60,
+}
+
+// ErrNoSavingsPlanRate is returned when there is no savings plan rate for an instance type
+var ErrNoSavingsPlanRate = errors.New("no savings plan rate found")
+
+// GetSavingsPlanInstanceTypeRate retrieves the discounted hourly rate of the instance type in the current AWSSession's region
+// under a savings plan, in the OndemandCurrency
+// The planType is one of SavingsPlanTypeCompute or SavingsPlanTypeEC2Instance, the term is one of ReservedTerm1Yr or ReservedTerm3Yr,
+// and the paymentOption is one of ReservedPaymentNoUpfront, ReservedPaymentPartialUpfront, or ReservedPaymentAllUpfront
+// EC2 Instance savings plans are purchased for an instance family but are still rated per instance type
+// Rates are retrieved from the Savings Plans API rather than the Pricing API and are matched by the OperatingSystem and Tenancy.
+// Upfront payments are not included in the rate, the rate is the hourly amount each instance hour deducts from the commitment.
+// Savings plan rates are not cached, so each call queries the Savings Plans API
+// An ErrNoSavingsPlanRate error is returned if the Savings Plans API does not have a rate for the instance type
+func (p *EC2Pricing) GetSavingsPlanInstanceTypeRate(instanceType string, planType string, term string, paymentOption string) (float64, error) {
+	return p.GetSavingsPlanInstanceTypeRateWithContext(context.Background(), instanceType, planType, term, paymentOption)
+}
+
+// GetSavingsPlanInstanceTypeRateWithContext is like GetSavingsPlanInstanceTypeRate but the Savings Plans API requests are canceled when
+// the context is done
+func (p *EC2Pricing) GetSavingsPlanInstanceTypeRateWithContext(ctx context.Context, instanceType string, planType string, term string, paymentOption string) (float64, error) {
+	if planType != SavingsPlanTypeCompute && planType != SavingsPlanTypeEC2Instance {
+		return -1, fmt.Errorf("savings plan type %q is not supported, it must be one of: %s, %s", planType, SavingsPlanTypeCompute, SavingsPlanTypeEC2Instance)
+	}
+	durationSeconds, ok := savingsPlanTermSeconds[term]
+	if !ok {
+		return -1, fmt.Errorf("savings plan term %q is not supported, it must be one of: %s, %s", term, ReservedTerm1Yr, ReservedTerm3Yr)
+	}
+	if !isReservedPaymentOption(paymentOption) {
+		return -1, fmt.Errorf("savings plan payment option %q is not supported, it must be one of: %s", paymentOption, strings.Join(reservedPaymentOptions, ", "))
+	}
+	input := savingsplans.DescribeSavingsPlansOfferingRatesInput{
+		Products:                  aws.StringSlice([]string{savingsplans.SavingsPlanProductTypeEc2}),
+		ServiceCodes:              aws.StringSlice([]string{savingsplans.SavingsPlanRateServiceCodeAmazonEc2}),
+		SavingsPlanTypes:          aws.StringSlice([]string{planType}),
+		SavingsPlanPaymentOptions: aws.StringSlice([]string{paymentOption}),
+		Filters: []*savingsplans.SavingsPlanOfferingRateFilterElement{
+			{Name: aws.String(savingsplans.SavingsPlanRateFilterAttributeRegion), Values: aws.StringSlice([]string{p.region()})},
+			{Name: aws.String(savingsplans.SavingsPlanRateFilterAttributeInstanceType), Values: aws.StringSlice([]string{instanceType})},
+			{Name: aws.String(savingsplans.SavingsPlanRateFilterAttributeTenancy), Values: aws.StringSlice([]string{p.Tenancy()})},
+			{Name: aws.String(savingsplans.SavingsPlanRateFilterAttributeProductDescription), Values: aws.StringSlice([]string{p.savingsPlanProductDescription()})},
+		},
+	}
+	if p.planAPICall(APIDescribeSavingsPlansOfferingRates, &input) {
+		return -1, fmt.Errorf("%w for the savings plan rate of instance type %s", ErrDryRun, instanceType)
+	}
+	if p.SavingsPlansClient == nil {
+		return -1, fmt.Errorf("unable to retrieve the savings plan rate of instance type %s since the SavingsPlansClient is not set", instanceType)
+	}
+	for {
+		apiCallStart := time.Now()
+		output, err := p.SavingsPlansClient.DescribeSavingsPlansOfferingRatesWithContext(ctx, &input)
+		p.observe().OnAPICall(APIDescribeSavingsPlansOfferingRates, time.Since(apiCallStart))
+		if err != nil {
+			return -1, err
+		}
+		for _, offeringRate := range output.SearchResults {
+			rate, found, err := parseSavingsPlanRate(offeringRate, durationSeconds, p.OndemandCurrency())
+			if err != nil {
+				p.log().Warnf("unable to parse a savings plan rate of instance type %s: %v", instanceType, err)
+				return -1, err
+			}
+			if found {
+				return rate, nil
+			}
+		}
+		if aws.StringValue(output.NextToken) == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	return -1, fmt.Errorf("%w for instance type %s with a %s %s %s savings plan", ErrNoSavingsPlanRate, instanceType, term, paymentOption, planType)
+}
+
+// savingsPlanProductDescription returns the product description savings plan rates list the OperatingSystem as, which is the
+// spot product description without the VPC suffix (Example: "Linux/UNIX")
+func (p *EC2Pricing) savingsPlanProductDescription() string {
+	return strings.TrimSuffix(p.operatingSystemPricing().spotProductDescription, amazonVPCSuffix)
+}
+
+// parseSavingsPlanRate returns the hourly rate of a savings plan offering rate from the Savings Plans API in the currency
+// Offering rates are returned for every duration of the plan type and payment option, so false is returned for rates whose offering
+// is not of the durationSeconds, is not in the currency, or is not charged per hour (such as the rates of Dedicated Host usage)
+func parseSavingsPlanRate(offeringRate *savingsplans.SavingsPlanOfferingRate, durationSeconds int64, currency string) (float64, bool, error) {
+	offering := offeringRate.SavingsPlanOffering
+	if offering == nil {
+		return -1, false, fmt.Errorf("unable to find the savings plan offering of the rate")
+	}
+	if aws.Int64Value(offering.DurationSeconds) != durationSeconds || aws.StringValue(offering.Currency) != currency ||
+		aws.StringValue(offeringRate.Unit) != savingsplans.SavingsPlanRateUnitHrs {
+		return -1, false, nil
+	}
+	rate, err := strconv.ParseFloat(aws.StringValue(offeringRate.Rate), 64)
+	if err != nil {
+		return -1, false, fmt.Errorf("could not convert the savings plan rate %q to a float64", aws.StringValue(offeringRate.Rate))
+	}
+	return rate, true, nil
+}
@@ -0,0 +1,121 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/savingsplans"
+)
+
+func getSavingsPlansFilterValues(input *savingsplans.DescribeSavingsPlansOfferingRatesInput, name string) []string {
+	for _, filter := range input.Filters {
+		if aws.StringValue(filter.Name) == name {
+			return aws.StringValueSlice(filter.Values)
+		}
+	}
+	return nil
+}
+
+func TestGetSavingsPlanInstanceTypeRate(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	savingsPlansMock := setupMock(t, describeSavingsPlansOfferingRates, "m5_large.json")
+	savingsPlansMock.DescribeSavingsPlansOfferingRatesInputs = &[]*savingsplans.DescribeSavingsPlansOfferingRatesInput{}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		SavingsPlansClient: savingsPlansMock,
+		AWSSession:         &sess,
+	}
+	// the 3yr rate of the same plan type and payment option is skipped
+	rate, err := ec2pricingClient.GetSavingsPlanInstanceTypeRate("m5.large", ec2pricing.SavingsPlanTypeCompute, ec2pricing.ReservedTerm1Yr, ec2pricing.ReservedPaymentNoUpfront)
+	h.Ok(t, err)
+	h.Equals(t, 0.068, rate)
+	input := (*savingsPlansMock.DescribeSavingsPlansOfferingRatesInputs)[0]
+	h.Equals(t, []string{"Compute"}, aws.StringValueSlice(input.SavingsPlanTypes))
+	h.Equals(t, []string{"No Upfront"}, aws.StringValueSlice(input.SavingsPlanPaymentOptions))
+	h.Equals(t, []string{"us-east-1"}, getSavingsPlansFilterValues(input, "region"))
+	h.Equals(t, []string{"m5.large"}, getSavingsPlansFilterValues(input, "instanceType"))
+	h.Equals(t, []string{"shared"}, getSavingsPlansFilterValues(input, "tenancy"))
+	h.Equals(t, []string{"Linux/UNIX"}, getSavingsPlansFilterValues(input, "productDescription"))
+
+	rate, err = ec2pricingClient.GetSavingsPlanInstanceTypeRate("m5.large", ec2pricing.SavingsPlanTypeCompute, ec2pricing.ReservedTerm3Yr, ec2pricing.ReservedPaymentNoUpfront)
+	h.Ok(t, err)
+	h.Equals(t, 0.047, rate)
+
+	rate, err = ec2pricingClient.GetSavingsPlanInstanceTypeRate("m5.large", ec2pricing.SavingsPlanTypeEC2Instance, ec2pricing.ReservedTerm1Yr, ec2pricing.ReservedPaymentNoUpfront)
+	h.Ok(t, err)
+	h.Equals(t, 0.06, rate)
+
+	rate, err = ec2pricingClient.GetSavingsPlanInstanceTypeRate("m5.large", ec2pricing.SavingsPlanTypeEC2Instance, ec2pricing.ReservedTerm3Yr, ec2pricing.ReservedPaymentAllUpfront)
+	h.Ok(t, err)
+	h.Equals(t, 0.036, rate)
+
+	rate, err = ec2pricingClient.GetSavingsPlanInstanceTypeRate("m5.large", ec2pricing.SavingsPlanTypeEC2Instance, ec2pricing.ReservedTerm1Yr, ec2pricing.ReservedPaymentAllUpfront)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSavingsPlanRate), "Expected ErrNoSavingsPlanRate, got %v", err)
+	h.Equals(t, float64(-1), rate)
+}
+
+func TestGetSavingsPlanInstanceTypeRate_Pages(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	savingsPlansMock := setupMock(t, describeSavingsPlansOfferingRates, "m5_large.json")
+	rates := savingsPlansMock.DescribeSavingsPlansOfferingRatesResp[0].SearchResults
+	// the 1yr Compute rate is on the second page
+	savingsPlansMock.DescribeSavingsPlansOfferingRatesResp = []savingsplans.DescribeSavingsPlansOfferingRatesOutput{
+		{SearchResults: rates[:1]},
+		{SearchResults: rates[1:]},
+	}
+	savingsPlansMock.DescribeSavingsPlansOfferingRatesInputs = &[]*savingsplans.DescribeSavingsPlansOfferingRatesInput{}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		SavingsPlansClient: savingsPlansMock,
+		AWSSession:         &sess,
+	}
+	rate, err := ec2pricingClient.GetSavingsPlanInstanceTypeRate("m5.large", ec2pricing.SavingsPlanTypeCompute, ec2pricing.ReservedTerm1Yr, ec2pricing.ReservedPaymentNoUpfront)
+	h.Ok(t, err)
+	h.Equals(t, 0.068, rate)
+	h.Equals(t, 2, len(*savingsPlansMock.DescribeSavingsPlansOfferingRatesInputs))
+}
+
+func TestGetSavingsPlanInstanceTypeRate_Unsupported(t *testing.T) {
+	ec2pricingClient := ec2pricing.EC2Pricing{}
+	_, err := ec2pricingClient.GetSavingsPlanInstanceTypeRate("m5.large", "SageMaker", ec2pricing.ReservedTerm1Yr, ec2pricing.ReservedPaymentNoUpfront)
+	h.Nok(t, err)
+	_, err = ec2pricingClient.GetSavingsPlanInstanceTypeRate("m5.large", ec2pricing.SavingsPlanTypeCompute, "2yr", ec2pricing.ReservedPaymentNoUpfront)
+	h.Nok(t, err)
+	_, err = ec2pricingClient.GetSavingsPlanInstanceTypeRate("m5.large", ec2pricing.SavingsPlanTypeCompute, ec2pricing.ReservedTerm1Yr, "none")
+	h.Nok(t, err)
+}
+
+func TestGetSavingsPlanInstanceTypeRate_NoClient(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	// NewFromClients does not create a SavingsPlansClient
+	ec2pricingClient := ec2pricing.NewFromClients(setupMock(t, getProductsPages, "m5_large.json"), nil, &sess)
+	_, err := ec2pricingClient.GetSavingsPlanInstanceTypeRate("m5.large", ec2pricing.SavingsPlanTypeCompute, ec2pricing.ReservedTerm1Yr, ec2pricing.ReservedPaymentNoUpfront)
+	h.Nok(t, err)
+}
@@ -0,0 +1,43 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+// OnDemandCacheSnapshot returns a copy of the on-demand cache keyed by instance type
+// The copy is independent of the cache, so it can be modified without affecting lookups. An empty map is returned if the cache has not been hydrated.
+func (p *EC2Pricing) OnDemandCacheSnapshot() map[string]float64 {
+	p.cacheMu.RLock()
+	defer p.cacheMu.RUnlock()
+	snapshot := make(map[string]float64, len(p.onDemandCache))
+	for instanceType, price := range p.onDemandCache {
+		snapshot[instanceType] = price
+	}
+	return snapshot
+}
+
+// SpotCacheSnapshot returns a copy of the spot cache of the current SpotProductDescription keyed by instance type and then by availability zone
+// The copy is independent of the cache, so it can be modified without affecting lookups. An empty map is returned if the cache has not been hydrated.
+func (p *EC2Pricing) SpotCacheSnapshot() map[string]map[string][]SpotPricingEntry {
+	spotProductDescription := p.SpotProductDescription()
+	p.cacheMu.RLock()
+	defer p.cacheMu.RUnlock()
+	snapshot := make(map[string]map[string][]SpotPricingEntry, len(p.spotCache[spotProductDescription]))
+	for instanceType, zoneToPriceEntries := range p.spotCache[spotProductDescription] {
+		zoneSnapshot := make(map[string][]SpotPricingEntry, len(zoneToPriceEntries))
+		for zone, priceEntries := range zoneToPriceEntries {
+			zoneSnapshot[zone] = append([]SpotPricingEntry{}, priceEntries...)
+		}
+		snapshot[instanceType] = zoneSnapshot
+	}
+	return snapshot
+}
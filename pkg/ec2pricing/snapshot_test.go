@@ -0,0 +1,81 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func TestOnDemandCacheSnapshot(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: setupMock(t, getProductsPages, "m5_large.json"),
+		AWSSession:    &sess,
+	}
+	h.Equals(t, map[string]float64{}, ec2pricingClient.OnDemandCacheSnapshot())
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	snapshot := ec2pricingClient.OnDemandCacheSnapshot()
+	h.Equals(t, map[string]float64{"m5.large": 0.096}, snapshot)
+
+	// modifying the snapshot does not affect the cache
+	snapshot["m5.large"] = 1
+	snapshot["c5.large"] = 1
+	h.Equals(t, map[string]float64{"m5.large": 0.096}, ec2pricingClient.OnDemandCacheSnapshot())
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, 0.096, price)
+}
+
+func TestSpotCacheSnapshot(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  setupMock(t, describeSpotPriceHistoryPages, "m5_large.json"),
+		AWSSession: &sess,
+	}
+	h.Equals(t, map[string]map[string][]ec2pricing.SpotPricingEntry{}, ec2pricingClient.SpotCacheSnapshot())
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	snapshot := ec2pricingClient.SpotCacheSnapshot()
+	h.Equals(t, 1, len(snapshot))
+	h.Equals(t, 5, len(snapshot["m5.large"]))
+	h.Assert(t, len(snapshot["m5.large"]["us-east-1a"]) > 0, "Expected spot price history for us-east-1a")
+
+	// modifying the snapshot does not affect the cache
+	priceBefore, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	for _, priceEntries := range snapshot["m5.large"] {
+		for i := range priceEntries {
+			priceEntries[i].SpotPrice = 100
+		}
+	}
+	delete(snapshot["m5.large"], "us-east-1b")
+	delete(snapshot, "m5.large")
+	h.Equals(t, 5, len(ec2pricingClient.SpotCacheSnapshot()["m5.large"]))
+	priceAfter, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, priceBefore, priceAfter)
+}
@@ -0,0 +1,343 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNoSpotPriceHistory is returned when an instance type has no spot price history in any of the requested availability zones
+var ErrNoSpotPriceHistory = errors.New("no spot price history found")
+
+// SpotCostResult is the detailed result of averaging the spot price history of an instance type
+type SpotCostResult struct {
+	// Avg is the time weighted average hourly spot price across all contributing zones in the SpotCurrency
+	Avg float64
+	// ZoneAvgs are the time weighted average hourly spot prices of each contributing zone keyed by availability zone name
+	ZoneAvgs map[string]float64
+	// Zones are the names of the availability zones which contributed to the average, sorted alpha-numerically
+	Zones []string
+	// ZoneSampleCounts are the number of spot price samples of each contributing zone keyed by availability zone name
+	ZoneSampleCounts map[string]int
+	// SampleCount is the number of spot price samples across all contributing zones
+	SampleCount int
+	// EarliestSample and LatestSample are the timestamps of the oldest and newest samples across all contributing zones, which
+	// can cover much less than the requested N days when the instance type has little spot price history
+	EarliestSample time.Time
+	LatestSample   time.Time
+	// Gaps are the intervals between consecutive samples which exceeded the SpotGapThreshold, sorted by start time
+	// Gaps is always empty when gap detection is disabled
+	Gaps []SpotPriceGap
+}
+
+// SpotPriceGap is an interval in a zone's spot price history with no samples
+type SpotPriceGap struct {
+	AvailabilityZone string
+	Start            time.Time
+	End              time.Time
+}
+
+// Duration returns the length of the gap
+func (g SpotPriceGap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// LongestGap returns the duration of the longest gap in the spot price history or 0 if there were no gaps
+func (r SpotCostResult) LongestGap() time.Duration {
+	longest := time.Duration(0)
+	for _, gap := range r.Gaps {
+		if gap.Duration() > longest {
+			longest = gap.Duration()
+		}
+	}
+	return longest
+}
+
+// GetSpotInstanceTypeNDayAvgCostDetailed retrieves the spot price history for a given AZ from the past N days and averages the price
+// Unlike GetSpotInstanceTypeNDayAvgCost, statistics about the underlying samples are returned along with the average
+// Passing an empty list for availabilityZones will retrieve avg cost for all AZs in the current AWSSession's region
+func (p *EC2Pricing) GetSpotInstanceTypeNDayAvgCostDetailed(instanceType string, availabilityZones []string, days int) (SpotCostResult, error) {
+	return p.GetSpotInstanceTypeNDayAvgCostDetailedWithContext(context.Background(), instanceType, availabilityZones, days)
+}
+
+// GetSpotInstanceTypeNDayAvgCostDetailedWithContext is like GetSpotInstanceTypeNDayAvgCostDetailed but the spot-pricing-history api
+// request is canceled when the context is done
+func (p *EC2Pricing) GetSpotInstanceTypeNDayAvgCostDetailedWithContext(ctx context.Context, instanceType string, availabilityZones []string, days int) (SpotCostResult, error) {
+	zoneToPriceEntries, endTime, err := p.getSpotPricingEntries(ctx, instanceType, days)
+	if err != nil {
+		return SpotCostResult{}, err
+	}
+	result := p.spotCostResult(zoneToPriceEntries, endTime, availabilityZones)
+	if len(result.Zones) == 0 {
+		return SpotCostResult{}, fmt.Errorf("%w for instance type %s in zones %v", ErrNoSpotPriceHistory, instanceType, availabilityZones)
+	}
+	return result, nil
+}
+
+// GetSpotInstanceTypeNDayAvgCostForProductDescriptions retrieves the spot price history of each product description
+// (Example: "Linux/UNIX (Amazon VPC)" or "Red Hat Enterprise Linux (Amazon VPC)") from the past N days in a single query
+// and returns the lowest average along with the product description it belongs to, product descriptions with the same average are ordered by name
+// Product descriptions without spot price history in the availability zones are skipped
+// Passing an empty list for availabilityZones will retrieve avg cost for all AZs in the current AWSSession's region
+func (p *EC2Pricing) GetSpotInstanceTypeNDayAvgCostForProductDescriptions(instanceType string, productDescriptions []string, availabilityZones []string, days int) (float64, string, error) {
+	return p.GetSpotInstanceTypeNDayAvgCostForProductDescriptionsWithContext(context.Background(), instanceType, productDescriptions, availabilityZones, days)
+}
+
+// GetSpotInstanceTypeNDayAvgCostForProductDescriptionsWithContext is like GetSpotInstanceTypeNDayAvgCostForProductDescriptions but
+// the spot-pricing-history api requests are canceled when the context is done
+func (p *EC2Pricing) GetSpotInstanceTypeNDayAvgCostForProductDescriptionsWithContext(ctx context.Context, instanceType string, productDescriptions []string, availabilityZones []string, days int) (float64, string, error) {
+	if len(productDescriptions) == 0 {
+		return float64(-1), "", fmt.Errorf("at least one product description must be specified")
+	}
+	if err := validateSpotProductDescriptions(productDescriptions); err != nil {
+		return float64(-1), "", err
+	}
+	productToZoneEntries, endTime, err := p.getSpotPricingEntriesByProduct(ctx, instanceType, productDescriptions, days)
+	if err != nil {
+		return float64(-1), "", err
+	}
+	cheapestAvg := float64(-1)
+	cheapestProduct := ""
+	for _, product := range productDescriptions {
+		result := p.spotCostResult(productToZoneEntries[product], endTime, availabilityZones)
+		if len(result.Zones) == 0 {
+			continue
+		}
+		if cheapestProduct == "" || isCheaper(result.Avg, product, cheapestAvg, cheapestProduct) {
+			cheapestAvg = result.Avg
+			cheapestProduct = product
+		}
+	}
+	if cheapestProduct == "" {
+		return float64(-1), "", fmt.Errorf("no spot price history was found for instance type %s with product descriptions: %s", instanceType, strings.Join(productDescriptions, ", "))
+	}
+	return cheapestAvg, cheapestProduct, nil
+}
+
+// spotCostResult averages the spot price history of each zone which is in the availabilityZones, or every zone if availabilityZones is empty
+// The averages are converted to the SpotCurrency
+func (p *EC2Pricing) spotCostResult(zoneToPriceEntries map[string][]SpotPricingEntry, endTime time.Time, availabilityZones []string) SpotCostResult {
+	result := SpotCostResult{ZoneAvgs: map[string]float64{}, Zones: []string{}, ZoneSampleCounts: map[string]int{}}
+	exchangeRate := p.spotExchangeRate()
+	zones := sortedZones(zoneToPriceEntries)
+	selectedZones := newZoneSelection(availabilityZones)
+	for _, zone := range zones {
+		priceEntries := zoneToPriceEntries[zone]
+		if !selectedZones.isSelected(zone) {
+			continue
+		}
+		zoneAggregate, zoneGaps := p.calculateSpotAggregate(priceEntries, endTime)
+		zoneAggregate *= exchangeRate
+		result.ZoneAvgs[zone] = zoneAggregate
+		result.Zones = append(result.Zones, zone)
+		result.ZoneSampleCounts[zone] = len(priceEntries)
+		result.SampleCount += len(priceEntries)
+		for _, priceEntry := range priceEntries {
+			if result.EarliestSample.IsZero() || priceEntry.Timestamp.Before(result.EarliestSample) {
+				result.EarliestSample = priceEntry.Timestamp
+			}
+			if priceEntry.Timestamp.After(result.LatestSample) {
+				result.LatestSample = priceEntry.Timestamp
+			}
+		}
+		for _, gap := range zoneGaps {
+			gap.AvailabilityZone = zone
+			result.Gaps = append(result.Gaps, gap)
+		}
+	}
+	sort.Slice(result.Gaps, func(i, j int) bool {
+		if result.Gaps[i].Start.Equal(result.Gaps[j].Start) {
+			return result.Gaps[i].AvailabilityZone < result.Gaps[j].AvailabilityZone
+		}
+		return result.Gaps[i].Start.Before(result.Gaps[j].Start)
+	})
+
+	if len(result.Zones) > 0 {
+		aggregateZonePriceSum := float64(0)
+		for _, zone := range result.Zones {
+			aggregateZonePriceSum += result.ZoneAvgs[zone]
+		}
+		result.Avg = aggregateZonePriceSum / float64(len(result.Zones))
+	}
+	return result
+}
+
+// GetSpotPriceHistory retrieves the spot price history from the past N days and returns the samples of each availability zone keyed by
+// availability zone name and sorted from oldest to newest
+// The spot cache is used when it has been hydrated. The prices are the raw samples in USD, they are not converted to the SpotCurrency.
+// An ErrNoSpotPriceHistory error is returned if none of the availability zones have any spot price history
+// Passing an empty list for availabilityZones will retrieve the history of all AZs in the current AWSSession's region
+func (p *EC2Pricing) GetSpotPriceHistory(instanceType string, availabilityZones []string, days int) (map[string][]SpotPricingEntry, error) {
+	return p.GetSpotPriceHistoryWithContext(context.Background(), instanceType, availabilityZones, days)
+}
+
+// GetSpotPriceHistoryWithContext is like GetSpotPriceHistory but the spot-pricing-history api requests are canceled when the
+// context is done
+func (p *EC2Pricing) GetSpotPriceHistoryWithContext(ctx context.Context, instanceType string, availabilityZones []string, days int) (map[string][]SpotPricingEntry, error) {
+	zoneToPriceEntries, _, err := p.getSpotPricingEntries(ctx, instanceType, days)
+	if err != nil {
+		return nil, err
+	}
+	history := map[string][]SpotPricingEntry{}
+	selectedZones := newZoneSelection(availabilityZones)
+	for zone, priceEntries := range zoneToPriceEntries {
+		if len(priceEntries) == 0 || !selectedZones.isSelected(zone) {
+			continue
+		}
+		sortedEntries := append([]SpotPricingEntry{}, priceEntries...)
+		sort.SliceStable(sortedEntries, func(i, j int) bool {
+			return sortedEntries[i].Timestamp.Before(sortedEntries[j].Timestamp)
+		})
+		history[zone] = sortedEntries
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%w for instance type %s in zones %v", ErrNoSpotPriceHistory, instanceType, availabilityZones)
+	}
+	return history, nil
+}
+
+// validateSpotDays returns an error if the number of days of spot price history is not greater than 0, which would make the
+// history window empty or end before it starts
+func validateSpotDays(days int) error {
+	if days <= 0 {
+		return fmt.Errorf("the days of spot price history must be greater than 0 but was %d", days)
+	}
+	return nil
+}
+
+// sortedZones returns the availability zones of the spot price history in alphabetical order
+// Averages across zones are summed in this fixed order so that the floating point result is the same on every run
+func sortedZones(zoneToPriceEntries map[string][]SpotPricingEntry) []string {
+	zones := make([]string, 0, len(zoneToPriceEntries))
+	for zone := range zoneToPriceEntries {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+// sortSpotEntriesNewestFirst returns a copy of the spot price entries sorted by descending timestamp, the caller's slice is not reordered
+// Entries with the same timestamp are sorted by descending price so that the higher price is consistently treated as the most recent
+func sortSpotEntriesNewestFirst(spotPriceEntries []SpotPricingEntry) []SpotPricingEntry {
+	entries := make([]SpotPricingEntry, len(spotPriceEntries))
+	copy(entries, spotPriceEntries)
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Timestamp.Equal(entries[j].Timestamp) {
+			return entries[i].SpotPrice > entries[j].SpotPrice
+		}
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+	return entries
+}
+
+// zoneSelection is the set of availability zones a spot price is computed from, an empty set selects every zone
+type zoneSelection map[string]struct{}
+
+// newZoneSelection returns the set of availabilityZones, which selects every zone if availabilityZones is empty
+func newZoneSelection(availabilityZones []string) zoneSelection {
+	selection := make(zoneSelection, len(availabilityZones))
+	for _, zone := range availabilityZones {
+		selection[zone] = struct{}{}
+	}
+	return selection
+}
+
+// isSelected returns true if the zone exactly matches one of the selected availability zones or every zone is selected
+func (s zoneSelection) isSelected(zone string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	_, ok := s[zone]
+	return ok
+}
+
+// GetSpotInstanceTypeNDayAvgCostWithAZ retrieves the spot price history for a given AZ from the past N days and returns both the
+// average price across all zones and the average price of each zone keyed by availability zone name
+// Both are computed from a single retrieval of the spot price history
+// Passing an empty list for availabilityZones will retrieve avg cost for all AZs in the current AWSSession's region
+func (p *EC2Pricing) GetSpotInstanceTypeNDayAvgCostWithAZ(instanceType string, availabilityZones []string, days int) (float64, map[string]float64, error) {
+	return p.GetSpotInstanceTypeNDayAvgCostWithAZWithContext(context.Background(), instanceType, availabilityZones, days)
+}
+
+// GetSpotInstanceTypeNDayAvgCostWithAZWithContext is like GetSpotInstanceTypeNDayAvgCostWithAZ but the spot-pricing-history api
+// requests are canceled when the context is done
+func (p *EC2Pricing) GetSpotInstanceTypeNDayAvgCostWithAZWithContext(ctx context.Context, instanceType string, availabilityZones []string, days int) (float64, map[string]float64, error) {
+	result, err := p.GetSpotInstanceTypeNDayAvgCostDetailedWithContext(ctx, instanceType, availabilityZones, days)
+	if err != nil {
+		return float64(-1), nil, err
+	}
+	return result.Avg, result.ZoneAvgs, nil
+}
+
+// GetSpotInstanceTypeNDayAvgCostPerZone retrieves the spot price history from the past N days and returns the time weighted
+// average price of each zone separately, keyed by availability zone name
+// Zones without spot price history are omitted and an ErrNoSpotPriceHistory error is returned if none of the zones have any
+// Passing an empty list for availabilityZones will retrieve avg cost for all AZs in the current AWSSession's region
+func (p *EC2Pricing) GetSpotInstanceTypeNDayAvgCostPerZone(instanceType string, availabilityZones []string, days int) (map[string]float64, error) {
+	return p.GetSpotInstanceTypeNDayAvgCostPerZoneWithContext(context.Background(), instanceType, availabilityZones, days)
+}
+
+// GetSpotInstanceTypeNDayAvgCostPerZoneWithContext is like GetSpotInstanceTypeNDayAvgCostPerZone but the spot-pricing-history api
+// requests are canceled when the context is done
+func (p *EC2Pricing) GetSpotInstanceTypeNDayAvgCostPerZoneWithContext(ctx context.Context, instanceType string, availabilityZones []string, days int) (map[string]float64, error) {
+	result, err := p.GetSpotInstanceTypeNDayAvgCostDetailedWithContext(ctx, instanceType, availabilityZones, days)
+	if err != nil {
+		return nil, err
+	}
+	return result.ZoneAvgs, nil
+}
+
+// GetSpotInstanceTypeWeightedAvgCost retrieves the spot price history from the past N days and combines the time weighted average price
+// of each zone in zoneWeights using the zone's weight, such as the share of a fleet's capacity running in the zone
+// The weights are normalized so they do not have to sum to 1. An error is returned if a weight is negative or the weights sum to 0,
+// and an ErrNoSpotPriceHistory error is returned if any of the zones does not have spot price history
+func (p *EC2Pricing) GetSpotInstanceTypeWeightedAvgCost(instanceType string, zoneWeights map[string]float64, days int) (float64, error) {
+	return p.GetSpotInstanceTypeWeightedAvgCostWithContext(context.Background(), instanceType, zoneWeights, days)
+}
+
+// GetSpotInstanceTypeWeightedAvgCostWithContext is like GetSpotInstanceTypeWeightedAvgCost but the spot-pricing-history api
+// requests are canceled when the context is done
+func (p *EC2Pricing) GetSpotInstanceTypeWeightedAvgCostWithContext(ctx context.Context, instanceType string, zoneWeights map[string]float64, days int) (float64, error) {
+	zones := []string{}
+	weightSum := float64(0)
+	for zone, weight := range zoneWeights {
+		if weight < 0 {
+			return float64(-1), fmt.Errorf("the weight of zone %s must not be negative but was %f", zone, weight)
+		}
+		zones = append(zones, zone)
+		weightSum += weight
+	}
+	if weightSum <= 0 {
+		return float64(-1), fmt.Errorf("the zone weights must sum to more than 0")
+	}
+	sort.Strings(zones)
+	result, err := p.GetSpotInstanceTypeNDayAvgCostDetailedWithContext(ctx, instanceType, zones, days)
+	if err != nil {
+		return float64(-1), err
+	}
+	weightedAvg := float64(0)
+	for _, zone := range zones {
+		zoneAvg, ok := result.ZoneAvgs[zone]
+		if !ok {
+			return float64(-1), fmt.Errorf("%w for instance type %s in zone %s", ErrNoSpotPriceHistory, instanceType, zone)
+		}
+		weightedAvg += zoneAvg * zoneWeights[zone] / weightSum
+	}
+	return weightedAvg, nil
+}
@@ -0,0 +1,696 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestGetSpotInstanceTypeNDayAvgCostDetailed_NoGapDetection(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	result, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, float64(0.04148843143974511), result.Avg)
+	h.Equals(t, 0, len(result.Gaps))
+	h.Equals(t, time.Duration(0), result.LongestGap())
+	h.Equals(t, []string{"us-east-1a"}, result.Zones)
+	h.Equals(t, map[string]int{"us-east-1a": 48}, result.ZoneSampleCounts)
+
+	result, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	h.Equals(t, []string{"us-east-1a", "us-east-1b", "us-east-1c", "us-east-1d", "us-east-1f"}, result.Zones)
+	h.Equals(t, map[string]int{"us-east-1a": 48, "us-east-1b": 50, "us-east-1c": 49, "us-east-1d": 43, "us-east-1f": 60}, result.ZoneSampleCounts)
+}
+
+func TestGetSpotInstanceTypeNDayAvgCostDetailed_SampleRange(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client:  setupMock(t, describeSpotPriceHistoryPages, "m5_large_multi_zone.json"),
+		AWSSession: &sess,
+		Clock:      func() time.Time { return time.Date(2021, 2, 13, 0, 0, 0, 0, time.UTC) },
+	}
+	// the 30 day average only has samples from the past 12 days
+	result, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 5, result.SampleCount)
+	h.Assert(t, time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC).Equal(result.EarliestSample), "Expected the earliest sample at 2021-02-01, got %s", result.EarliestSample)
+	h.Assert(t, time.Date(2021, 2, 11, 0, 0, 0, 0, time.UTC).Equal(result.LatestSample), "Expected the latest sample at 2021-02-11, got %s", result.LatestSample)
+
+	// only the samples of the requested zones are counted
+	result, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", []string{"us-east-1b"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 2, result.SampleCount)
+	h.Assert(t, time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC).Equal(result.EarliestSample), "Expected the earliest sample at 2021-02-03, got %s", result.EarliestSample)
+	h.Assert(t, time.Date(2021, 2, 9, 0, 0, 0, 0, time.UTC).Equal(result.LatestSample), "Expected the latest sample at 2021-02-09, got %s", result.LatestSample)
+}
+
+func TestGetSpotInstanceTypeNDayAvgCostDetailed_Gaps(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:            fixtureClock,
+		EC2Client:        ec2Mock,
+		AWSSession:       &sess,
+		SpotGapThreshold: 24*time.Hour + 5*time.Second,
+	}
+	result, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	// flagging gaps does not change the average
+	h.Equals(t, float64(0.04148843143974511), result.Avg)
+	h.Equals(t, 2, len(result.Gaps))
+	h.Equals(t, "us-east-1a", result.Gaps[0].AvailabilityZone)
+	h.Equals(t, time.Date(2021, 1, 12, 15, 5, 3, 0, time.UTC), result.Gaps[0].Start.UTC())
+	h.Equals(t, 24*time.Hour+9*time.Second, result.LongestGap())
+}
+
+func TestGetSpotInstanceTypeNDayAvgCostDetailed_InterpolateGaps(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large_sparse.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client:        ec2Mock,
+		AWSSession:       &sess,
+		SpotGapThreshold: 48 * time.Hour,
+		// end the window at the newest sample so that only the gap affects the average
+		Clock: func() time.Time { return time.Date(2021, 2, 12, 0, 0, 0, 0, time.UTC) },
+	}
+	result, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	// the 10 day old sample is carried forward across the gap
+	h.Assert(t, math.Abs(result.Avg-0.46/11) < 1e-9, "Expected the carried forward average, got %f", result.Avg)
+	h.Equals(t, 1, len(result.Gaps))
+	h.Equals(t, 240*time.Hour, result.LongestGap())
+
+	ec2pricingClient.InterpolateSpotGaps = true
+	result, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	// the gap is priced at the midpoint of the samples on either side of it
+	h.Assert(t, math.Abs(result.Avg-0.56/11) < 1e-9, "Expected the interpolated average, got %f", result.Avg)
+	h.Equals(t, 1, len(result.Gaps))
+
+	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, result.Avg, price)
+}
+
+func TestGetSpotInstanceTypeNDayAvgCostWithAZ(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	avg, perAZ, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostWithAZ("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, float64(0.04148843143974511), avg)
+	h.Equals(t, map[string]float64{"us-east-1a": 0.04148843143974511}, perAZ)
+
+	avg, perAZ, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCostWithAZ("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 5, len(perAZ))
+	zoneSum := float64(0)
+	for _, zoneAvg := range perAZ {
+		zoneSum += zoneAvg
+	}
+	h.Assert(t, math.Abs(avg-zoneSum/5) < 1e-12, "The average should be the mean of the zone averages, got %f", avg)
+}
+
+func TestGetSpotInstanceTypeNDayAvgCostPerZone(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large_multi_zone.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+		Clock:      func() time.Time { return time.Date(2021, 2, 13, 0, 0, 0, 0, time.UTC) },
+	}
+	perZone, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostPerZone("m5.large", []string{"us-east-1a", "us-east-1b"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 2, len(perZone))
+	// us-east-1a: (10*0.04 + 2*0.06) / 12
+	h.Assert(t, math.Abs(perZone["us-east-1a"]-0.52/12) < 1e-9, "Expected the us-east-1a average, got %f", perZone["us-east-1a"])
+	// us-east-1b: (6*0.10 + 4*0.07) / 10
+	h.Assert(t, math.Abs(perZone["us-east-1b"]-0.88/10) < 1e-9, "Expected the us-east-1b average, got %f", perZone["us-east-1b"])
+
+	avg, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a", "us-east-1b"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(avg-(perZone["us-east-1a"]+perZone["us-east-1b"])/2) < 1e-12, "The average should be the mean of the zone averages, got %f", avg)
+
+	perZone, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCostPerZone("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 3, len(perZone))
+	h.Assert(t, math.Abs(perZone["us-east-1c"]-0.08) < 1e-9, "Expected the us-east-1c average, got %f", perZone["us-east-1c"])
+
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCostPerZone("m5.large", []string{"us-east-1d"}, 30)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotPriceHistory), "Expected ErrNoSpotPriceHistory, got %v", err)
+}
+
+func TestGetSpotInstanceTypeNDayAvgCostDetailed_ExactZoneMatch(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	start := fixtureClock().Add(-24 * time.Hour)
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock: fixtureClock,
+		EC2Client: mockedPricing{
+			DescribeSpotPriceHistoryPagesResp: ec2.DescribeSpotPriceHistoryOutput{
+				SpotPriceHistory: []*ec2.SpotPrice{
+					spotPriceHistory("m5.large", "us-east-1a", "0.100000", start),
+					spotPriceHistory("m5.large", "us-east-1ab", "0.020000", start),
+				},
+			},
+		},
+		AWSSession: &sess,
+	}
+	// us-east-1a is a prefix of us-east-1ab but must not be selected by it
+	result, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", []string{"us-east-1ab"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, []string{"us-east-1ab"}, result.Zones)
+	h.Assert(t, math.Abs(result.Avg-0.02) < 1e-9, "Expected the us-east-1ab average, got %f", result.Avg)
+
+	result, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, []string{"us-east-1a"}, result.Zones)
+	h.Assert(t, math.Abs(result.Avg-0.1) < 1e-9, "Expected the us-east-1a average, got %f", result.Avg)
+
+	// a zone name which only partially matches is not selected
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", []string{"us-east-1"}, 30)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotPriceHistory), "Expected ErrNoSpotPriceHistory, got %v", err)
+
+	history, err := ec2pricingClient.GetSpotPriceHistory("m5.large", []string{"us-east-1ab"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 1, len(history))
+	h.Equals(t, 1, len(history["us-east-1ab"]))
+}
+
+func TestGetSpotPriceHistory(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client:  setupMock(t, describeSpotPriceHistoryPages, "m5_large_multi_zone.json"),
+		AWSSession: &sess,
+		Clock:      func() time.Time { return time.Date(2021, 2, 13, 0, 0, 0, 0, time.UTC) },
+	}
+	history, err := ec2pricingClient.GetSpotPriceHistory("m5.large", []string{"us-east-1a", "us-east-1b"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 2, len(history))
+	// the fixture lists the samples of each zone from newest to oldest
+	expectedHistory := map[string][]ec2pricing.SpotPricingEntry{
+		"us-east-1a": {
+			{Timestamp: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), SpotPrice: 0.04},
+			{Timestamp: time.Date(2021, 2, 11, 0, 0, 0, 0, time.UTC), SpotPrice: 0.06},
+		},
+		"us-east-1b": {
+			{Timestamp: time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC), SpotPrice: 0.1},
+			{Timestamp: time.Date(2021, 2, 9, 0, 0, 0, 0, time.UTC), SpotPrice: 0.07},
+		},
+	}
+	for zone, expectedEntries := range expectedHistory {
+		h.Equals(t, len(expectedEntries), len(history[zone]))
+		for i, expected := range expectedEntries {
+			h.Assert(t, expected.Timestamp.Equal(history[zone][i].Timestamp), "Expected %s sample %d at %s, got %s", zone, i, expected.Timestamp, history[zone][i].Timestamp)
+			h.Equals(t, expected.SpotPrice, history[zone][i].SpotPrice)
+		}
+	}
+
+	// the cache returns the same sorted samples
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	history, err = ec2pricingClient.GetSpotPriceHistory("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 3, len(history))
+	for zone, priceEntries := range history {
+		for i := 1; i < len(priceEntries); i++ {
+			h.Assert(t, !priceEntries[i].Timestamp.Before(priceEntries[i-1].Timestamp), "Expected the %s samples to be sorted by timestamp", zone)
+		}
+	}
+
+	_, err = ec2pricingClient.GetSpotPriceHistory("m5.large", []string{"us-east-1d"}, 30)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotPriceHistory), "Expected ErrNoSpotPriceHistory, got %v", err)
+}
+
+func TestGetSpotInstanceTypeNDayAvgCost_NewestPriceWeight(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large_sparse.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+		Clock:      func() time.Time { return time.Date(2021, 2, 13, 0, 0, 0, 0, time.UTC) },
+	}
+	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	// the newest price (0.05) is in effect for the last day of the window: (10*0.04 + 1*0.06 + 1*0.05) / 12
+	h.Assert(t, math.Abs(price-0.51/12) < 1e-9, "Expected the newest price to be weighted until the end of the window, got %f", price)
+
+	// moving the end of the window out increases the weight of the newest price
+	ec2pricingClient.Clock = func() time.Time { return time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC) }
+	price, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-1.01/22) < 1e-9, "Expected the newest price to be weighted until the end of the window, got %f", price)
+}
+
+func TestGetSpotInstanceTypeNDayAvgCostForProductDescriptions(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large_multi_os.json")
+	inputs := []*ec2.DescribeSpotPriceHistoryInput{}
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &inputs
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	products := []string{"Linux/UNIX (Amazon VPC)", "Red Hat Enterprise Linux (Amazon VPC)"}
+	avg, product, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostForProductDescriptions("m5.large", products, []string{}, 30)
+	h.Ok(t, err)
+	h.Equals(t, "Linux/UNIX (Amazon VPC)", product)
+	h.Assert(t, math.Abs(avg-0.045) < 1e-9, "Expected the Linux/UNIX average, got %f", avg)
+	// both product descriptions are retrieved with a single query
+	h.Equals(t, 1, len(inputs))
+	h.Equals(t, 2, len(inputs[0].ProductDescriptions))
+
+	avg, product, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCostForProductDescriptions("m5.large", products, []string{"us-east-1b"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, "Red Hat Enterprise Linux (Amazon VPC)", product)
+	h.Assert(t, math.Abs(avg-0.02) < 1e-9, "Expected the Red Hat Enterprise Linux average, got %f", avg)
+
+	_, _, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCostForProductDescriptions("m5.large", []string{"Windows (Amazon VPC)"}, []string{}, 30)
+	h.Nok(t, err)
+	_, _, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCostForProductDescriptions("m5.large", []string{}, []string{}, 30)
+	h.Nok(t, err)
+}
+
+func TestHydrateSpotCacheForTypes(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "multi_type.json")
+	inputs := []*ec2.DescribeSpotPriceHistoryInput{}
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &inputs
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	h.Ok(t, ec2pricingClient.HydrateSpotCacheForTypes([]string{"m5.large", "c5.large"}, 30))
+	h.Equals(t, 1, len(inputs))
+	h.Equals(t, []string{"m5.large", "c5.large"}, aws.StringValueSlice(inputs[0].InstanceTypes))
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() != nil, "The spot cache should be hydrated")
+
+	// only the requested instance types are cached
+	for _, instanceType := range []string{"m5.large", "c5.large"} {
+		_, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost(instanceType, []string{"us-east-1a"}, 30)
+		h.Ok(t, err)
+	}
+	h.Equals(t, 1, len(inputs))
+	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("r5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-0.05) < 1e-9, "Expected the r5.large average, got %f", price)
+	h.Equals(t, 2, len(inputs))
+
+	// further instance types are merged into the cache
+	h.Ok(t, ec2pricingClient.HydrateSpotCacheForTypes([]string{"r5.large"}, 30))
+	h.Equals(t, 3, len(inputs))
+	for _, instanceType := range []string{"m5.large", "c5.large", "r5.large"} {
+		_, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost(instanceType, []string{"us-east-1b"}, 30)
+		h.Ok(t, err)
+	}
+	h.Equals(t, 3, len(inputs))
+
+	h.Nok(t, ec2pricingClient.HydrateSpotCacheForTypes([]string{}, 30))
+	h.Nok(t, ec2pricingClient.HydrateSpotCacheForTypes([]string{"m5.large"}, 0))
+}
+
+func TestHydrateSpotCacheForProductDescriptions(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large_multi_os.json")
+	inputs := []*ec2.DescribeSpotPriceHistoryInput{}
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &inputs
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	products := []string{"Linux/UNIX (Amazon VPC)", "Red Hat Enterprise Linux (Amazon VPC)"}
+	h.Ok(t, ec2pricingClient.HydrateSpotCacheForProductDescriptions(30, products))
+	avg, product, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostForProductDescriptions("m5.large", products, []string{"us-east-1b"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, "Red Hat Enterprise Linux (Amazon VPC)", product)
+	h.Assert(t, math.Abs(avg-0.02) < 1e-9, "Expected the Red Hat Enterprise Linux average, got %f", avg)
+
+	// the default Linux/UNIX average is separate from the other product descriptions in the cache
+	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1b"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-0.05) < 1e-9, "Expected the Linux/UNIX average, got %f", price)
+	h.Equals(t, 1, len(inputs))
+}
+
+func TestWithSpotCurrency(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large_multi_os.json")
+
+	ec2pricingClient := ec2pricing.New(sess, ec2pricing.WithSpotCurrency("EUR", 0.5))
+	ec2pricingClient.EC2Client = ec2Mock
+	ec2pricingClient.Clock = fixtureClock
+	h.Equals(t, "EUR", ec2pricingClient.SpotCurrency())
+	result, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostDetailed("m5.large", []string{"us-east-1a", "us-east-1b"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(result.ZoneAvgs["us-east-1a"]-0.02) < 1e-9, "Expected the us-east-1a average in EUR, got %f", result.ZoneAvgs["us-east-1a"])
+	h.Assert(t, math.Abs(result.ZoneAvgs["us-east-1b"]-0.025) < 1e-9, "Expected the us-east-1b average in EUR, got %f", result.ZoneAvgs["us-east-1b"])
+	h.Assert(t, math.Abs(result.Avg-0.0225) < 1e-9, "Expected the average in EUR, got %f", result.Avg)
+
+	// spot prices stay in USD without an exchange rate
+	ec2pricingClient = ec2pricing.New(sess, ec2pricing.WithSpotCurrency("EUR", 0))
+	ec2pricingClient.EC2Client = ec2Mock
+	ec2pricingClient.Clock = fixtureClock
+	h.Equals(t, "USD", ec2pricingClient.SpotCurrency())
+	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-0.04) < 1e-9, "Expected the us-east-1a average in USD, got %f", price)
+}
+
+func TestGetSpotInstanceTypeNDayAvgCost_DuplicateTimestamps(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large_duplicate_timestamps.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	// the higher of the prices with the same timestamp is treated as the most recent regardless of the order returned
+	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-0.06) < 1e-9, "Expected the higher duplicate price, got %f", price)
+}
+
+func TestGetSpotInstanceTypeNDayAvgCost_SingleSample(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	// the only sample of us-east-1a is at the end of the window, so the window has no duration to weight the price by
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large_single_sample.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-0.042) < 1e-9, "Expected the price of the single sample, got %f", price)
+
+	// the single sample zone is averaged with equal weight to a zone with 0.05 and 0.07 for an hour each
+	price, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-0.051) < 1e-9, "Expected the average of the zones, got %f", price)
+}
+
+func TestGetSpotPriceHistory_ShorterThanCachedWindow(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large_multi_zone.json")
+	inputs := []*ec2.DescribeSpotPriceHistoryInput{}
+	ec2Mock.DescribeSpotPriceHistoryPagesInputs = &inputs
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+		Clock:      func() time.Time { return time.Date(2021, 2, 13, 0, 0, 0, 0, time.UTC) },
+	}
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	h.Equals(t, 1, len(inputs))
+
+	// only the samples since 2021-02-06 are within the past 7 days of the cached window
+	history, err := ec2pricingClient.GetSpotPriceHistory("m5.large", []string{}, 7)
+	h.Ok(t, err)
+	h.Equals(t, 1, len(inputs))
+	h.Equals(t, 2, len(history))
+	h.Equals(t, 1, len(history["us-east-1a"]))
+	h.Assert(t, time.Date(2021, 2, 11, 0, 0, 0, 0, time.UTC).Equal(history["us-east-1a"][0].Timestamp), "Expected the us-east-1a sample at 2021-02-11, got %s", history["us-east-1a"][0].Timestamp)
+	h.Equals(t, 1, len(history["us-east-1b"]))
+	h.Assert(t, time.Date(2021, 2, 9, 0, 0, 0, 0, time.UTC).Equal(history["us-east-1b"][0].Timestamp), "Expected the us-east-1b sample at 2021-02-09, got %s", history["us-east-1b"][0].Timestamp)
+
+	zonePrices, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostPerZone("m5.large", []string{}, 7)
+	h.Ok(t, err)
+	h.Equals(t, 2, len(zonePrices))
+	h.Assert(t, math.Abs(zonePrices["us-east-1a"]-0.06) < 1e-9, "Expected only the in-window us-east-1a price, got %f", zonePrices["us-east-1a"])
+	h.Assert(t, math.Abs(zonePrices["us-east-1b"]-0.07) < 1e-9, "Expected only the in-window us-east-1b price, got %f", zonePrices["us-east-1b"])
+	h.Equals(t, 1, len(inputs))
+
+	// the whole cached window is still used for the days it was hydrated with
+	history, err = ec2pricingClient.GetSpotPriceHistory("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	h.Equals(t, 3, len(history))
+	h.Equals(t, 1, len(inputs))
+
+	// a longer window than the cached one is queried from the spot-pricing-history api
+	_, err = ec2pricingClient.GetSpotPriceHistory("m5.large", []string{}, 60)
+	h.Ok(t, err)
+	h.Equals(t, 2, len(inputs))
+	h.Equals(t, 60*24*time.Hour, inputs[1].EndTime.Sub(*inputs[1].StartTime))
+}
+
+func TestGetSpotInstanceTypeWeightedAvgCost(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client:  setupMock(t, describeSpotPriceHistoryPages, "m5_large_multi_zone.json"),
+		AWSSession: &sess,
+		Clock:      func() time.Time { return time.Date(2021, 2, 13, 0, 0, 0, 0, time.UTC) },
+	}
+	zoneAvgs, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostPerZone("m5.large", []string{"us-east-1a", "us-east-1b"}, 30)
+	h.Ok(t, err)
+	equalAvg, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a", "us-east-1b"}, 30)
+	h.Ok(t, err)
+
+	// equal weights match the unweighted average regardless of their sum
+	weightedAvg, err := ec2pricingClient.GetSpotInstanceTypeWeightedAvgCost("m5.large", map[string]float64{"us-east-1a": 2, "us-east-1b": 2}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(weightedAvg-equalAvg) < 1e-12, "Expected equal weights to match the average %f, got %f", equalAvg, weightedAvg)
+
+	// a fleet with three quarters of its capacity in us-east-1a
+	weightedAvg, err = ec2pricingClient.GetSpotInstanceTypeWeightedAvgCost("m5.large", map[string]float64{"us-east-1a": 3, "us-east-1b": 1}, 30)
+	h.Ok(t, err)
+	expected := zoneAvgs["us-east-1a"]*0.75 + zoneAvgs["us-east-1b"]*0.25
+	h.Assert(t, math.Abs(weightedAvg-expected) < 1e-12, "Expected the weighted average %f, got %f", expected, weightedAvg)
+	h.Assert(t, weightedAvg != equalAvg, "Expected the weighted average to differ from the equal weight average %f", equalAvg)
+
+	// a zone with no weight does not contribute
+	weightedAvg, err = ec2pricingClient.GetSpotInstanceTypeWeightedAvgCost("m5.large", map[string]float64{"us-east-1a": 1, "us-east-1b": 0}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(weightedAvg-zoneAvgs["us-east-1a"]) < 1e-12, "Expected the us-east-1a average %f, got %f", zoneAvgs["us-east-1a"], weightedAvg)
+}
+
+func TestGetSpotInstanceTypeWeightedAvgCost_Errors(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client:  setupMock(t, describeSpotPriceHistoryPages, "m5_large_multi_zone.json"),
+		AWSSession: &sess,
+		Clock:      func() time.Time { return time.Date(2021, 2, 13, 0, 0, 0, 0, time.UTC) },
+	}
+	_, err := ec2pricingClient.GetSpotInstanceTypeWeightedAvgCost("m5.large", map[string]float64{"us-east-1a": 1, "us-east-1d": 1}, 30)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotPriceHistory), "Expected ErrNoSpotPriceHistory, got %v", err)
+	h.Equals(t, "no spot price history found for instance type m5.large in zone us-east-1d", err.Error())
+
+	_, err = ec2pricingClient.GetSpotInstanceTypeWeightedAvgCost("m5.large", map[string]float64{"us-east-1a": 1, "us-east-1b": -1}, 30)
+	h.Nok(t, err)
+	_, err = ec2pricingClient.GetSpotInstanceTypeWeightedAvgCost("m5.large", map[string]float64{"us-east-1a": 0}, 30)
+	h.Nok(t, err)
+	_, err = ec2pricingClient.GetSpotInstanceTypeWeightedAvgCost("m5.large", map[string]float64{}, 30)
+	h.Nok(t, err)
+}
+
+func TestTimeWeightedSpotAvg(t *testing.T) {
+	now := time.Now().UTC()
+	// 0.10 for 1 hour and 0.04 from an hour ago until now
+	spotPriceEntries := []ec2pricing.SpotPricingEntry{
+		{Timestamp: now.Add(-time.Hour), SpotPrice: 0.04},
+		{Timestamp: now.Add(-2 * time.Hour), SpotPrice: 0.10},
+	}
+	avg := ec2pricing.TimeWeightedSpotAvg(spotPriceEntries)
+	h.Assert(t, math.Abs(avg-0.07) < 1e-4, "Expected the time weighted average of 0.07, got %f", avg)
+
+	h.Equals(t, 0.0, ec2pricing.TimeWeightedSpotAvg(nil))
+}
+
+func TestTimeWeightedSpotAvgUntil(t *testing.T) {
+	start := time.Date(2021, 2, 8, 0, 0, 0, 0, time.UTC)
+	// 0.04 for 1 hour, 0.10 for 2 hours, 0.07 for 3 hours and 0.50 for 2 hours until the end of the window
+	spotPriceEntries := []ec2pricing.SpotPricingEntry{
+		{Timestamp: start.Add(3 * time.Hour), SpotPrice: 0.07},
+		{Timestamp: start, SpotPrice: 0.04},
+		{Timestamp: start.Add(6 * time.Hour), SpotPrice: 0.50},
+		{Timestamp: start.Add(time.Hour), SpotPrice: 0.10},
+	}
+	avg := ec2pricing.TimeWeightedSpotAvgUntil(spotPriceEntries, start.Add(8*time.Hour))
+	h.Assert(t, math.Abs(avg-0.18125) < 1e-9, "Expected the time weighted average of 0.18125, got %f", avg)
+	// the entries are not sorted in place
+	h.Equals(t, start.Add(3*time.Hour), spotPriceEntries[0].Timestamp)
+
+	// the window ends at the most recent entry when the end time is earlier
+	avg = ec2pricing.TimeWeightedSpotAvgUntil(spotPriceEntries, start)
+	h.Assert(t, math.Abs(avg-0.075) < 1e-9, "Expected the time weighted average of 0.075, got %f", avg)
+
+	h.Equals(t, 0.0, ec2pricing.TimeWeightedSpotAvgUntil(nil, start))
+	h.Equals(t, 0.5, ec2pricing.TimeWeightedSpotAvgUntil(spotPriceEntries[2:3], start.Add(8*time.Hour)))
+}
+
+func TestTimeWeightedSpotAvgUntil_MatchesSpotAvgCost(t *testing.T) {
+	pricingMock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		EC2Client:  pricingMock,
+		AWSSession: &session.Session{Config: &aws.Config{Region: aws.String("us-east-1")}},
+		Clock:      fixtureClock,
+	}
+	entries := []ec2pricing.SpotPricingEntry{}
+	for _, history := range pricingMock.DescribeSpotPriceHistoryPagesResp.SpotPriceHistory {
+		if aws.StringValue(history.AvailabilityZone) != "us-east-1a" {
+			continue
+		}
+		price, err := strconv.ParseFloat(aws.StringValue(history.SpotPrice), 64)
+		h.Ok(t, err)
+		entries = append(entries, ec2pricing.SpotPricingEntry{Timestamp: *history.Timestamp, SpotPrice: price})
+	}
+	h.Assert(t, len(entries) > 1, "Expected multiple us-east-1a entries in the fixture")
+
+	avg, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	expected := ec2pricing.TimeWeightedSpotAvgUntil(entries, fixtureClock())
+	h.Assert(t, math.Abs(avg-expected) < 1e-9, "Expected TimeWeightedSpotAvgUntil's %f to match GetSpotInstanceTypeNDayAvgCost's %f", expected, avg)
+}
+
+func TestSpotAggregatesWithContext_Canceled(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  setupMock(t, describeSpotPriceHistoryPages, "m5_large.json"),
+		AWSSession: &sess,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	zones := []string{"us-east-1a"}
+	lookups := map[string]func() error{
+		"GetSpotInstanceTypeNDayAvgCostForProductDescriptionsWithContext": func() error {
+			_, _, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostForProductDescriptionsWithContext(ctx, "m5.large", []string{"Linux/UNIX"}, zones, 30)
+			return err
+		},
+		"GetSpotPriceHistoryWithContext": func() error {
+			_, err := ec2pricingClient.GetSpotPriceHistoryWithContext(ctx, "m5.large", zones, 30)
+			return err
+		},
+		"GetSpotInstanceTypeNDayAvgCostWithAZWithContext": func() error {
+			_, _, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostWithAZWithContext(ctx, "m5.large", zones, 30)
+			return err
+		},
+		"GetSpotInstanceTypeNDayAvgCostPerZoneWithContext": func() error {
+			_, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCostPerZoneWithContext(ctx, "m5.large", zones, 30)
+			return err
+		},
+		"GetSpotInstanceTypeWeightedAvgCostWithContext": func() error {
+			_, err := ec2pricingClient.GetSpotInstanceTypeWeightedAvgCostWithContext(ctx, "m5.large", map[string]float64{"us-east-1a": 1}, 30)
+			return err
+		},
+		"GetSpotInstanceTypePercentileCostWithContext": func() error {
+			_, err := ec2pricingClient.GetSpotInstanceTypePercentileCostWithContext(ctx, "m5.large", zones, 30, 50)
+			return err
+		},
+		"GetSpotInstanceTypeVolatilityWithContext": func() error {
+			_, err := ec2pricingClient.GetSpotInstanceTypeVolatilityWithContext(ctx, "m5.large", zones, 30)
+			return err
+		},
+		"GetSpotInstanceTypeDecayWeightedAvgCostWithContext": func() error {
+			_, err := ec2pricingClient.GetSpotInstanceTypeDecayWeightedAvgCostWithContext(ctx, "m5.large", zones, 30, time.Hour)
+			return err
+		},
+	}
+	for name, lookup := range lookups {
+		err := lookup()
+		h.Assert(t, errors.Is(err, context.Canceled), "Expected %s to be canceled, got %v", name, err)
+	}
+}
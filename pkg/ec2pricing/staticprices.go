@@ -0,0 +1,69 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	// embed is used to bundle the static on-demand price list
+	_ "embed"
+	"encoding/json"
+	"sync"
+)
+
+// staticOndemandPriceListJSON is a snapshot of on-demand prices bundled into the binary, see staticOndemandPriceList
+//
+//go:embed static/ondemand_prices.json
+var staticOndemandPriceListJSON []byte
+
+// staticOndemandPriceList is the decoded form of the bundled on-demand price snapshot
+// The snapshot only covers common instance types in a few regions, for one operating system, tenancy, and currency
+type staticOndemandPriceList struct {
+	PublicationDate string
+	OperatingSystem string
+	Tenancy         string
+	Currency        string
+	// Prices are the hourly on-demand prices keyed by region and then by instance type
+	Prices map[string]map[string]float64
+}
+
+var (
+	staticOndemandPrices     staticOndemandPriceList
+	staticOndemandPricesOnce sync.Once
+)
+
+// loadStaticOndemandPriceList decodes the bundled on-demand price snapshot once
+func loadStaticOndemandPriceList() staticOndemandPriceList {
+	staticOndemandPricesOnce.Do(func() {
+		// the snapshot is validated by the tests, so a decoding failure only leaves it empty
+		_ = json.Unmarshal(staticOndemandPriceListJSON, &staticOndemandPrices)
+	})
+	return staticOndemandPrices
+}
+
+// staticOndemandPrice returns the on-demand price of the instance type in the current AWSSession's region from the bundled
+// price snapshot, marked as Stale since the snapshot may be out of date
+// false is returned if the snapshot does not have the instance type in the region, or if its prices are for a different
+// OperatingSystem, Tenancy, or OndemandCurrency, or the CapacityStatus is not the used capacity status the snapshot is for
+func (p *EC2Pricing) staticOndemandPrice(instanceType string) (*Price, bool) {
+	priceList := loadStaticOndemandPriceList()
+	if priceList.OperatingSystem != p.OperatingSystem() || priceList.Tenancy != p.Tenancy() || priceList.Currency != p.OndemandCurrency() || p.CapacityStatus() != CapacityStatusUsed {
+		return nil, false
+	}
+	amountPerHour, ok := priceList.Prices[p.region()][instanceType]
+	if !ok {
+		return nil, false
+	}
+	price := p.ondemandPrice(instanceType, amountPerHour)
+	price.Stale = true
+	return price, true
+}
@@ -0,0 +1,95 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+func TestGetOndemandInstanceTypePrice_StaticFallbackOnError(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: mockedPricing{GetProductsPagesErr: errors.New("ThrottlingException: Rate exceeded")},
+		AWSSession:    &sess,
+	}
+	price, err := ec2pricingClient.GetOndemandInstanceTypePrice("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, ec2pricing.Price{InstanceType: "m5.large", AmountPerHour: 0.096, Currency: "USD", Stale: true}, *price)
+
+	cost, err := ec2pricingClient.GetOndemandInstanceTypeCost("c5.large")
+	h.Ok(t, err)
+	h.Equals(t, 0.085, cost)
+
+	// instance types which are not in the static price list still return the Pricing API's error
+	_, err = ec2pricingClient.GetOndemandInstanceTypePrice("z1d.large")
+	h.Nok(t, err)
+}
+
+func TestGetOndemandInstanceTypePrice_NoStaticFallbackOnSuccess(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: setupMock(t, getProductsPages, "m5_large.json"),
+		AWSSession:    &sess,
+	}
+	price, err := ec2pricingClient.GetOndemandInstanceTypePrice("m5.large")
+	h.Ok(t, err)
+	h.Assert(t, !price.Stale, "Prices from the Pricing API should not be marked as stale")
+}
+
+func TestOfflineMode(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("eu-west-1"),
+		},
+	}
+	pricingMock := mockedPricing{GetProductsPagesInputs: &[]*pricing.GetProductsInput{}}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+		OfflineMode:   true,
+	}
+	price, err := ec2pricingClient.GetOndemandInstanceTypePrice("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, ec2pricing.Price{InstanceType: "m5.large", AmountPerHour: 0.107, Currency: "USD", Stale: true}, *price)
+
+	_, err = ec2pricingClient.GetOndemandInstanceTypePrice("z1d.large")
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoOndemandPrice), "Expected ErrNoOndemandPrice, got %v", err)
+
+	// the static price list only has linux prices
+	h.Ok(t, ec2pricingClient.SetOperatingSystem(ec2pricing.OperatingSystemWindows))
+	_, err = ec2pricingClient.GetOndemandInstanceTypePrice("m5.large")
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoOndemandPrice), "Expected ErrNoOndemandPrice, got %v", err)
+
+	// overrides still take precedence
+	ec2pricingClient.SetOndemandPriceOverride("z1d.large", 0.186)
+	price, err = ec2pricingClient.GetOndemandInstanceTypePrice("z1d.large")
+	h.Ok(t, err)
+	h.Assert(t, !price.Stale, "Overridden prices should not be marked as stale")
+	h.Equals(t, 0, len(*pricingMock.GetProductsPagesInputs))
+}
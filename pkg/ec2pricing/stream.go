@@ -0,0 +1,88 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"go.uber.org/multierr"
+)
+
+// StreamSpotPriceHistory retrieves the spot price history of the SpotProductDescription for all instance types from the past N days
+// and calls fn with each sample as the pages arrive, so large windows can be aggregated without holding the whole history in memory
+// The spot cache is neither used nor hydrated. No further pages are retrieved once the context is done or fn returns an error,
+// and the error returned by fn is returned as is
+// Samples whose price cannot be parsed are skipped and their errors are combined into the returned error
+func (p *EC2Pricing) StreamSpotPriceHistory(ctx context.Context, days int, fn func(instanceType string, zone string, entry SpotPricingEntry) error) error {
+	if err := validateSpotDays(days); err != nil {
+		return err
+	}
+	productDescriptions := []string{p.SpotProductDescription()}
+	endTime := p.now()
+	startTime := endTime.Add(time.Hour * time.Duration(24*-1*days))
+	spotPriceHistInput := ec2.DescribeSpotPriceHistoryInput{
+		ProductDescriptions: aws.StringSlice(productDescriptions),
+		StartTime:           &startTime,
+		EndTime:             &endTime,
+	}
+	p.setSpotPriceHistoryPageSize(&spotPriceHistInput)
+	if p.planAPICall(APIDescribeSpotPriceHistory, &spotPriceHistInput) {
+		return fmt.Errorf("%w for streaming the spot price history", ErrDryRun)
+	}
+	var processingErr error
+	var errCtx error
+	var errFn error
+	apiCallStart := time.Now()
+	errAPI := p.EC2Client.DescribeSpotPriceHistoryPagesWithContext(ctx, &spotPriceHistInput, func(dspho *ec2.DescribeSpotPriceHistoryOutput, b bool) bool {
+		if err := ctx.Err(); err != nil {
+			errCtx = err
+			return false
+		}
+		for _, history := range dspho.SpotPriceHistory {
+			spotPrice, errParse := strconv.ParseFloat(*history.SpotPrice, 64)
+			if errParse != nil {
+				p.log().Warnf("unable to parse the spot price of instance type %s: %v", aws.StringValue(history.InstanceType), errParse)
+				processingErr = multierr.Append(processingErr, errParse)
+				continue
+			}
+			if _, ok := matchProductDescription(productDescriptions, history.ProductDescription); !ok {
+				continue
+			}
+			if errFn = fn(*history.InstanceType, *history.AvailabilityZone, SpotPricingEntry{
+				Timestamp: *history.Timestamp,
+				SpotPrice: spotPrice,
+			}); errFn != nil {
+				return false
+			}
+		}
+		return true
+	})
+	p.observe().OnAPICall(APIDescribeSpotPriceHistory, time.Since(apiCallStart))
+	if errAPI == nil {
+		errAPI = errCtx
+	}
+	if errAPI != nil {
+		p.log().Warnf("unable to stream the spot price history: %v", errAPI)
+		return errAPI
+	}
+	if errFn != nil {
+		return errFn
+	}
+	return processingErr
+}
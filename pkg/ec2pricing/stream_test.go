@@ -0,0 +1,92 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func TestStreamSpotPriceHistory(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	spotPages := spotPriceHistoryPages(t, "m5_large.json", 3)
+	samples := 0
+	for _, page := range spotPages {
+		samples += len(page.SpotPriceHistory)
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	ec2Mock.DescribeSpotPriceHistoryPagesRespPages = spotPages
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+
+	calls := 0
+	zones := map[string]int{}
+	err := ec2pricingClient.StreamSpotPriceHistory(context.Background(), 30, func(instanceType string, zone string, entry ec2pricing.SpotPricingEntry) error {
+		calls++
+		zones[zone]++
+		h.Equals(t, "m5.large", instanceType)
+		h.Assert(t, entry.SpotPrice > 0, "Expected a positive spot price, got %f", entry.SpotPrice)
+		return nil
+	})
+	h.Ok(t, err)
+	h.Equals(t, samples, calls)
+	h.Assert(t, len(zones) > 1, "Expected samples from more than one zone, got %v", zones)
+	// the spot cache is not hydrated
+	h.Assert(t, ec2pricingClient.LastSpotCacheUTC() == nil, "The spot cache should not be hydrated when streaming")
+}
+
+func TestStreamSpotPriceHistory_CallbackError(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	ec2Mock := setupMock(t, describeSpotPriceHistoryPages, "m5_large.json")
+	ec2Mock.DescribeSpotPriceHistoryPagesRespPages = spotPriceHistoryPages(t, "m5_large.json", 3)
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		Clock:      fixtureClock,
+		EC2Client:  ec2Mock,
+		AWSSession: &sess,
+	}
+	errStop := errors.New("stop")
+	calls := 0
+	err := ec2pricingClient.StreamSpotPriceHistory(context.Background(), 30, func(instanceType string, zone string, entry ec2pricing.SpotPricingEntry) error {
+		calls++
+		if calls == 3 {
+			return errStop
+		}
+		return nil
+	})
+	h.Equals(t, errStop, err)
+	// no further samples or pages are passed to the callback once it returns an error
+	h.Equals(t, 3, calls)
+
+	err = ec2pricingClient.StreamSpotPriceHistory(context.Background(), 0, func(instanceType string, zone string, entry ec2pricing.SpotPricingEntry) error {
+		return nil
+	})
+	h.Nok(t, err)
+}
@@ -0,0 +1,86 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Tenancies which on-demand prices can be retrieved for
+const (
+	TenancyShared    = "shared"
+	TenancyDedicated = "dedicated"
+	TenancyHost      = "host"
+)
+
+// tenancies maps each supported tenancy to the value of the Pricing API's tenancy attribute
+var tenancies = map[string]string{
+	TenancyShared:    "Shared",
+	TenancyDedicated: "Dedicated",
+	TenancyHost:      "Host",
+}
+
+// SupportedTenancies returns the tenancies which can be passed to SetTenancy sorted alpha-numerically
+func SupportedTenancies() []string {
+	supported := []string{}
+	for tenancy := range tenancies {
+		supported = append(supported, tenancy)
+	}
+	sort.Strings(supported)
+	return supported
+}
+
+// WithTenancy sets the tenancy which on-demand prices are retrieved for, tenancies which are not one of the
+// SupportedTenancies are ignored and shared tenancy is used instead
+func WithTenancy(tenancy string) Option {
+	return func(p *EC2Pricing) {
+		if err := p.SetTenancy(tenancy); err != nil {
+			p.warnOption("%v, using %s tenancy", err, TenancyShared)
+		}
+	}
+}
+
+// SetTenancy sets the tenancy which on-demand prices are retrieved for (Example: "dedicated")
+// Changing the tenancy clears the on-demand cache since it holds the previous tenancy's prices, spot prices are not affected
+// An error is returned if the tenancy is not one of the SupportedTenancies
+func (p *EC2Pricing) SetTenancy(tenancy string) error {
+	tenancy = strings.ToLower(tenancy)
+	if _, ok := tenancies[tenancy]; !ok {
+		return fmt.Errorf("tenancy %q is not supported, it must be one of: %s", tenancy, strings.Join(SupportedTenancies(), ", "))
+	}
+	if tenancy == p.Tenancy() {
+		return nil
+	}
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+	p.tenancy = tenancy
+	p.onDemandCache = nil
+	p.lastOnDemandCacheUTC = nil
+	return nil
+}
+
+// Tenancy returns the tenancy which on-demand prices are retrieved for, which is shared by default
+func (p *EC2Pricing) Tenancy() string {
+	if p.tenancy == "" {
+		return TenancyShared
+	}
+	return p.tenancy
+}
+
+// pricingAPITenancy returns the value of the Pricing API's tenancy attribute for the current tenancy
+func (p *EC2Pricing) pricingAPITenancy() string {
+	return tenancies[p.Tenancy()]
+}
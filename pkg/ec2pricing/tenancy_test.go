@@ -0,0 +1,74 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+// getProductsFilterValue returns the value of the input's filter on the field or an empty string if it does not filter on it
+func getProductsFilterValue(input *pricing.GetProductsInput, field string) string {
+	for _, filter := range input.Filters {
+		if aws.StringValue(filter.Field) == field {
+			return aws.StringValue(filter.Value)
+		}
+	}
+	return ""
+}
+
+func TestSetTenancy(t *testing.T) {
+	sess := session.Session{
+		Config: &aws.Config{
+			Region: aws.String("us-east-1"),
+		},
+	}
+	pricingMock := setupMock(t, getProductsPages, "m5_large.json")
+	pricingMock.GetProductsPagesInputs = &[]*pricing.GetProductsInput{}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		PricingClient: pricingMock,
+		AWSSession:    &sess,
+	}
+	h.Equals(t, ec2pricing.TenancyShared, ec2pricingClient.Tenancy())
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Equals(t, "Shared", getProductsFilterValue((*pricingMock.GetProductsPagesInputs)[0], "tenancy"))
+
+	h.Ok(t, ec2pricingClient.SetTenancy("dedicated"))
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() == nil, "Changing the tenancy should clear the on-demand cache")
+	_, err := ec2pricingClient.GetOndemandInstanceTypeCost("m5.large")
+	h.Ok(t, err)
+	h.Equals(t, "Dedicated", getProductsFilterValue((*pricingMock.GetProductsPagesInputs)[2], "tenancy"))
+
+	h.Ok(t, ec2pricingClient.SetTenancy(ec2pricing.TenancyHost))
+	h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Equals(t, "Host", getProductsFilterValue((*pricingMock.GetProductsPagesInputs)[3], "tenancy"))
+}
+
+func TestSetTenancy_Unsupported(t *testing.T) {
+	ec2pricingClient := ec2pricing.EC2Pricing{}
+	h.Nok(t, ec2pricingClient.SetTenancy("reserved"))
+	h.Equals(t, ec2pricing.TenancyShared, ec2pricingClient.Tenancy())
+	h.Equals(t, []string{"dedicated", "host", "shared"}, ec2pricing.SupportedTenancies())
+}
+
+func TestWithTenancy(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	h.Equals(t, ec2pricing.TenancyDedicated, ec2pricing.New(sess, ec2pricing.WithTenancy("Dedicated")).Tenancy())
+	h.Equals(t, ec2pricing.TenancyShared, ec2pricing.New(sess, ec2pricing.WithTenancy("reserved")).Tenancy())
+}
@@ -0,0 +1,72 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+const (
+	defaultPricingThrottleMaxAttempts = 3
+	defaultPricingThrottleBaseDelay   = 250 * time.Millisecond
+)
+
+// pricingThrottleErrorCodes are the error codes the Pricing API throttles requests with
+var pricingThrottleErrorCodes = map[string]bool{
+	"ThrottlingException":  true,
+	"RequestLimitExceeded": true,
+}
+
+// getProductsPages calls GetProductsPagesWithContext and retries it up to the PricingThrottleMaxAttempts when the Pricing API throttles it
+// The delay before each retry is a random duration of up to PricingThrottleBaseDelay * 2^(retry - 1) so that concurrent callers spread out
+// A retry paginates from the first page again, but the pages which were already passed to fn are skipped so fn sees each page once
+func (p *EC2Pricing) getProductsPages(ctx context.Context, input *pricing.GetProductsInput, fn func(*pricing.GetProductsOutput, bool) bool) error {
+	pagesDelivered := 0
+	for attempt := 1; ; attempt++ {
+		page := 0
+		err := p.PricingClient.GetProductsPagesWithContext(ctx, input, func(pricingOutput *pricing.GetProductsOutput, lastPage bool) bool {
+			page++
+			if page <= pagesDelivered {
+				return true
+			}
+			pagesDelivered++
+			return fn(pricingOutput, lastPage)
+		})
+		if !isPricingThrottle(err) || attempt >= p.PricingThrottleMaxAttempts {
+			return err
+		}
+		delay := time.Duration(0)
+		if maxDelay := int64(p.PricingThrottleBaseDelay) << (attempt - 1); maxDelay > 0 {
+			delay = time.Duration(rand.Int63n(maxDelay))
+		}
+		p.log().Infof("the Pricing API throttled attempt %d of %d, retrying in %s: %v", attempt, p.PricingThrottleMaxAttempts, delay, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isPricingThrottle returns true if the error is the Pricing API throttling a request
+func isPricingThrottle(err error) bool {
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && pricingThrottleErrorCodes[awsErr.Code()]
+}
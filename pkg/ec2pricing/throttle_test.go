@@ -0,0 +1,119 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"testing"
+	"time"
+
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+// throttlingPricing throttles the first Throttles calls of GetProductsPages after passing PagesBeforeThrottle pages to the callback
+type throttlingPricing struct {
+	mockedPricing
+	Throttles           *int
+	PagesBeforeThrottle int
+	Code                string
+}
+
+func (m throttlingPricing) GetProductsPagesWithContext(ctx aws.Context, input *pricing.GetProductsInput, fn gpFn, opts ...request.Option) error {
+	if *m.Throttles == 0 {
+		return m.mockedPricing.GetProductsPagesWithContext(ctx, input, fn, opts...)
+	}
+	*m.Throttles--
+	pages := 0
+	errAPI := m.mockedPricing.GetProductsPagesWithContext(ctx, input, func(output *pricing.GetProductsOutput, lastPage bool) bool {
+		if pages == m.PagesBeforeThrottle {
+			return false
+		}
+		pages++
+		return fn(output, lastPage)
+	}, opts...)
+	if errAPI != nil {
+		return errAPI
+	}
+	return awserr.New(m.Code, "Rate exceeded", nil)
+}
+
+func TestHydrateOndemandCache_RetriesThrottling(t *testing.T) {
+	for _, code := range []string{"ThrottlingException", "RequestLimitExceeded"} {
+		ec2pricingClient, pricingMock := setupBatchPricing(t)
+		// the first attempt is throttled mid pagination and the second before any page
+		throttles := 2
+		ec2pricingClient.PricingClient = throttlingPricing{mockedPricing: pricingMock, Throttles: &throttles, PagesBeforeThrottle: 1, Code: code}
+		ec2pricingClient.PricingThrottleMaxAttempts = 3
+		ec2pricingClient.PricingThrottleBaseDelay = time.Millisecond
+
+		h.Ok(t, ec2pricingClient.HydrateOndemandCache())
+		h.Equals(t, 0, throttles)
+		// three attempts of the query for all instance types and one for mac metal instances
+		h.Equals(t, 4, *pricingMock.GetProductsPagesCalls)
+		costs, missing, err := ec2pricingClient.GetOndemandInstanceTypeCosts([]string{"m5.large", "c5.large", "r5.large", "m5.xlarge", "c5.xlarge"})
+		h.Ok(t, err)
+		h.Equals(t, 0, len(missing))
+		// every page is cached although the retries paginate from the first page again
+		h.Equals(t, map[string]float64{
+			"m5.large":  0.096,
+			"c5.large":  0.085,
+			"r5.large":  0.126,
+			"m5.xlarge": 0.192,
+			"c5.xlarge": 0.17,
+		}, costs)
+		h.Equals(t, 4, *pricingMock.GetProductsPagesCalls)
+	}
+}
+
+func TestHydrateOndemandCache_SurfacesThrottlingAfterMaxAttempts(t *testing.T) {
+	ec2pricingClient, pricingMock := setupBatchPricing(t)
+	throttles := 3
+	ec2pricingClient.PricingClient = throttlingPricing{mockedPricing: pricingMock, Throttles: &throttles, Code: "ThrottlingException"}
+	ec2pricingClient.PricingThrottleMaxAttempts = 2
+	ec2pricingClient.PricingThrottleBaseDelay = time.Millisecond
+
+	err := ec2pricingClient.HydrateOndemandCache()
+	h.Nok(t, err)
+	h.Assert(t, ec2pricingClient.LastOnDemandCacheUTC() == nil, "The on-demand cache should not be hydrated when the Pricing API throttles every attempt")
+	h.Equals(t, 1, throttles)
+	h.Equals(t, 2, *pricingMock.GetProductsPagesCalls)
+}
+
+func TestHydrateOndemandCache_DoesNotRetryOtherErrors(t *testing.T) {
+	ec2pricingClient, pricingMock := setupBatchPricing(t)
+	pricingMock.GetProductsPagesErr = awserr.New("AccessDeniedException", "not authorized", nil)
+	ec2pricingClient.PricingClient = pricingMock
+	ec2pricingClient.PricingThrottleMaxAttempts = 3
+	ec2pricingClient.PricingThrottleBaseDelay = time.Millisecond
+
+	h.Nok(t, ec2pricingClient.HydrateOndemandCache())
+	h.Equals(t, 1, *pricingMock.GetProductsPagesCalls)
+}
+
+func TestGetOndemandInstanceTypeCostAsOf_RetriesThrottling(t *testing.T) {
+	ec2pricingClient, pricingMock := setupBatchPricing(t)
+	throttles := 2
+	ec2pricingClient.PricingClient = throttlingPricing{mockedPricing: pricingMock, Throttles: &throttles, Code: "ThrottlingException"}
+	ec2pricingClient.PricingThrottleMaxAttempts = 3
+	ec2pricingClient.PricingThrottleBaseDelay = time.Millisecond
+
+	price, err := ec2pricingClient.GetOndemandInstanceTypeCostAsOf("r5.large", time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC))
+	h.Ok(t, err)
+	h.Equals(t, 0.126, price)
+	h.Equals(t, 0, throttles)
+	h.Equals(t, 3, *pricingMock.GetProductsPagesCalls)
+}
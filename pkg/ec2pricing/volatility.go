@@ -0,0 +1,87 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// GetSpotInstanceTypeVolatility retrieves the spot price history from the past N days and returns the time weighted standard deviation
+// of each zone's spot price averaged across the zones, in the SpotCurrency
+// Each price is weighted by how long it was in effect, the same way the time weighted average is computed, and the result is the
+// population standard deviation since the price history covers the whole window rather than a sample of it
+// The spot cache is used when it has been hydrated
+// Passing an empty list for availabilityZones will retrieve the volatility for all AZs in the current AWSSession's region
+func (p *EC2Pricing) GetSpotInstanceTypeVolatility(instanceType string, availabilityZones []string, days int) (float64, error) {
+	return p.GetSpotInstanceTypeVolatilityWithContext(context.Background(), instanceType, availabilityZones, days)
+}
+
+// GetSpotInstanceTypeVolatilityWithContext is like GetSpotInstanceTypeVolatility but the spot-pricing-history api requests are
+// canceled when the context is done
+func (p *EC2Pricing) GetSpotInstanceTypeVolatilityWithContext(ctx context.Context, instanceType string, availabilityZones []string, days int) (float64, error) {
+	zoneToPriceEntries, endTime, err := p.getSpotPricingEntries(ctx, instanceType, days)
+	if err != nil {
+		return float64(-1), err
+	}
+	zoneStdDevSum := float64(0)
+	numOfZones := 0
+	selectedZones := newZoneSelection(availabilityZones)
+	for _, zone := range sortedZones(zoneToPriceEntries) {
+		priceEntries := zoneToPriceEntries[zone]
+		if len(priceEntries) == 0 || !selectedZones.isSelected(zone) {
+			continue
+		}
+		zoneStdDevSum += calculateSpotStdDev(priceEntries, endTime)
+		numOfZones++
+	}
+	if numOfZones == 0 {
+		return float64(-1), fmt.Errorf("%w for instance type %s in zones %v", ErrNoSpotPriceHistory, instanceType, availabilityZones)
+	}
+	return zoneStdDevSum / float64(numOfZones) * p.spotExchangeRate(), nil
+}
+
+// calculateSpotStdDev returns the time weighted population standard deviation of the spot prices, where each price is in effect
+// until the next newer price or the endTime for the most recent price
+func calculateSpotStdDev(spotPriceEntries []SpotPricingEntry, endTime time.Time) float64 {
+	entries := sortSpotEntriesNewestFirst(spotPriceEntries)
+	if endTime.Before(entries[0].Timestamp) {
+		endTime = entries[0].Timestamp
+	}
+
+	durations := make([]float64, len(entries))
+	totalDuration := float64(0)
+	weightedPriceSum := float64(0)
+	for i, entry := range entries {
+		if i == 0 {
+			durations[i] = endTime.Sub(entry.Timestamp).Minutes()
+		} else {
+			durations[i] = entries[i-1].Timestamp.Sub(entry.Timestamp).Minutes()
+		}
+		totalDuration += durations[i]
+		weightedPriceSum += durations[i] * entry.SpotPrice
+	}
+	if totalDuration == 0 {
+		return 0
+	}
+	mean := weightedPriceSum / totalDuration
+	weightedSquaredDeviationSum := float64(0)
+	for i, entry := range entries {
+		deviation := entry.SpotPrice - mean
+		weightedSquaredDeviationSum += durations[i] * deviation * deviation
+	}
+	return math.Sqrt(weightedSquaredDeviationSum / totalDuration)
+}
@@ -0,0 +1,59 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2pricing_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+)
+
+func TestGetSpotInstanceTypeVolatility_ConstantPrice(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large_constant.json")
+	volatility, err := ec2pricingClient.GetSpotInstanceTypeVolatility("m5.large", []string{}, 30)
+	h.Ok(t, err)
+	h.Assert(t, volatility < 1e-12, "Expected a constant price to have no volatility, got %f", volatility)
+}
+
+func TestGetSpotInstanceTypeVolatility_VaryingPrice(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large_sparse.json")
+	volatility, err := ec2pricingClient.GetSpotInstanceTypeVolatility("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	// the newest sample is after the fixtureClock, so the window ends at it and its price of 0.05 has no weight
+	// which leaves 0.04 for 10 days and 0.06 for 1 day around the time weighted mean of 0.46 / 11
+	mean := 0.46 / 11
+	expected := math.Sqrt((10*math.Pow(0.04-mean, 2) + math.Pow(0.06-mean, 2)) / 11)
+	h.Assert(t, math.Abs(volatility-expected) < 1e-9, "Expected a volatility of %f, got %f", expected, volatility)
+}
+
+func TestGetSpotInstanceTypeVolatility_HydratedCache(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large_sparse.json")
+	h.Ok(t, ec2pricingClient.HydrateSpotCache(30))
+	fromCache, err := ec2pricingClient.GetSpotInstanceTypeVolatility("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+
+	uncachedClient := setupFixturePricing(t, "m5_large_sparse.json")
+	uncached, err := uncachedClient.GetSpotInstanceTypeVolatility("m5.large", []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(fromCache-uncached) < 1e-12, "Expected the cached volatility %f to match %f", fromCache, uncached)
+}
+
+func TestGetSpotInstanceTypeVolatility_NoHistory(t *testing.T) {
+	ec2pricingClient := setupFixturePricing(t, "m5_large_sparse.json")
+	_, err := ec2pricingClient.GetSpotInstanceTypeVolatility("m5.large", []string{"us-east-1d"}, 30)
+	h.Assert(t, errors.Is(err, ec2pricing.ErrNoSpotPriceHistory), "Expected ErrNoSpotPriceHistory, got %v", err)
+}
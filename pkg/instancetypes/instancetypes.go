@@ -7,4 +7,8 @@ type Details struct {
 	ec2.InstanceTypeInfo
 	OndemandPricePerHour *float64
 	SpotPrice            *float64
+	// PriceEnrichmentSkipped is true when prices were not looked up because the price enrichment budget was exhausted
+	PriceEnrichmentSkipped bool
+	// PreviousGenerationFallback is true when no current generation instance types matched and the results include previous generation instance types
+	PreviousGenerationFallback bool
 }
@@ -0,0 +1,93 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instancetypes
+
+import (
+	"strings"
+	"time"
+)
+
+// familyLaunchDates maps an instance type family to the date it became generally available
+// The DescribeInstanceTypes API does not expose launch dates, so this table needs to be updated as new families are launched
+var familyLaunchDates = map[string]string{
+	"a1":   "2018-11-26",
+	"c1":   "2008-08-08",
+	"c3":   "2013-11-14",
+	"c4":   "2015-01-11",
+	"c5":   "2017-11-06",
+	"c5a":  "2020-06-04",
+	"c5ad": "2020-08-13",
+	"c5d":  "2018-05-04",
+	"c5n":  "2018-11-26",
+	"c6g":  "2020-06-11",
+	"c6gd": "2020-07-27",
+	"c6gn": "2020-12-18",
+	"c6i":  "2021-10-27",
+	"c6a":  "2022-02-14",
+	"c7g":  "2022-05-23",
+	"d2":   "2015-03-24",
+	"g2":   "2013-11-05",
+	"g3":   "2017-07-13",
+	"g4dn": "2019-09-20",
+	"g5":   "2021-11-11",
+	"h1":   "2017-11-30",
+	"i3":   "2017-02-23",
+	"i3en": "2019-05-08",
+	"inf1": "2019-12-03",
+	"m1":   "2006-08-25",
+	"m3":   "2012-10-31",
+	"m4":   "2015-06-11",
+	"m5":   "2017-11-28",
+	"m5a":  "2018-11-06",
+	"m5d":  "2018-06-27",
+	"m5n":  "2019-10-11",
+	"m6g":  "2020-05-11",
+	"m6i":  "2021-08-16",
+	"m6a":  "2021-11-29",
+	"m7g":  "2023-02-13",
+	"m7i":  "2023-08-02",
+	"mac1": "2020-11-30",
+	"p2":   "2016-09-29",
+	"p3":   "2017-10-25",
+	"p3dn": "2018-12-07",
+	"p4d":  "2020-11-02",
+	"p5":   "2023-07-26",
+	"r3":   "2014-04-10",
+	"r4":   "2016-11-30",
+	"r5":   "2018-07-25",
+	"r6g":  "2020-06-10",
+	"r6i":  "2021-08-16",
+	"t1":   "2010-09-09",
+	"t2":   "2014-07-01",
+	"t3":   "2018-08-21",
+	"t3a":  "2019-04-24",
+	"t4g":  "2020-09-14",
+	"x1":   "2016-05-18",
+	"z1d":  "2018-07-25",
+}
+
+// FamilyLaunchDate returns the date the family of the instance type became generally available
+// nil is returned if the launch date of the family is not known
+func FamilyLaunchDate(instanceType string) *time.Time {
+	family := strings.Split(instanceType, ".")[0]
+	launchDateStr, ok := familyLaunchDates[family]
+	if !ok {
+		return nil
+	}
+	launchDate, err := time.Parse("2006-01-02", launchDateStr)
+	if err != nil {
+		return nil
+	}
+	return &launchDate
+}
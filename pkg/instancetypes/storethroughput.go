@@ -0,0 +1,44 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instancetypes
+
+import (
+	"strings"
+)
+
+// familyInstanceStoreReadThroughputPerVCpu maps a storage optimized instance type family to its approximate sequential
+// NVMe instance store read throughput in MB/s for each vcpu. Instance store throughput scales linearly with the size of
+// the instance type within these families, so the throughput of a size is the family rate multiplied by its vcpus.
+// The DescribeInstanceTypes API does not expose instance store throughput, so this table needs to be updated as
+// new storage optimized families are launched
+var familyInstanceStoreReadThroughputPerVCpu = map[string]float64{
+	"i3":     250,
+	"i3en":   165,
+	"i4g":    280,
+	"i4i":    300,
+	"im4gn":  250,
+	"is4gen": 240,
+}
+
+// InstanceStoreReadThroughput returns the approximate sequential NVMe instance store read throughput in MB/s of the instance type
+// nil is returned if the throughput of the family is not published
+func InstanceStoreReadThroughput(instanceType string, vcpus int64) *float64 {
+	family := strings.Split(instanceType, ".")[0]
+	throughputPerVCpu, ok := familyInstanceStoreReadThroughputPerVCpu[family]
+	if !ok || vcpus <= 0 {
+		return nil
+	}
+	throughput := throughputPerVCpu * float64(vcpus)
+	return &throughput
+}
@@ -0,0 +1,144 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/instancetypes"
+)
+
+// PriceEnrichmentBudget bounds the time and number of price lookups spent enriching instance types with prices
+// Once the budget is exhausted, the remaining instance types are returned without prices and marked with PriceEnrichmentSkipped
+type PriceEnrichmentBudget struct {
+	// MaxDuration is the longest time spent enriching prices, measured from the start of the selection run
+	// A lookup which is still in flight when the time runs out is canceled if the EC2Pricing client supports contexts
+	// A zero value does not limit the time
+	MaxDuration time.Duration
+	// MaxLookups is the maximum number of on-demand and spot price lookups, each of which may require an API call on a cold cache
+	// A zero value does not limit the number of lookups
+	MaxLookups int
+	// LookupOnDemandPrices and LookupSpotPrices look up the on-demand and spot prices of each instance type even when the
+	// pricing cache was not hydrated, so that a cold cache is priced one instance type at a time within the budget instead of
+	// being hydrated in full before the selection run. Prices are always looked up when their cache was hydrated.
+	LookupOnDemandPrices bool
+	LookupSpotPrices     bool
+}
+
+// ec2PricingWithContext is implemented by EC2Pricing clients whose lookups can be canceled, such as ec2pricing.EC2Pricing
+type ec2PricingWithContext interface {
+	GetOndemandInstanceTypeCostWithContext(ctx context.Context, instanceType string) (float64, error)
+	GetSpotInstanceTypeNDayAvgCostWithContext(ctx context.Context, instanceType string, availabilityZones []string, days int) (float64, error)
+}
+
+// priceEnrichmentTracker tracks the usage of a PriceEnrichmentBudget during a selection run
+// A single tracker is shared by every pass of the run so that the budget is not reset between passes
+type priceEnrichmentTracker struct {
+	budget    *PriceEnrichmentBudget
+	ctx       context.Context
+	lookups   int
+	exhausted bool
+}
+
+// newPriceEnrichmentTracker starts tracking the budget, the returned cancel func must be called once the run is done
+func newPriceEnrichmentTracker(budget *PriceEnrichmentBudget) (*priceEnrichmentTracker, context.CancelFunc) {
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if budget != nil && budget.MaxDuration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, budget.MaxDuration)
+	}
+	return &priceEnrichmentTracker{budget: budget, ctx: ctx}, cancel
+}
+
+// lookupOnDemand returns true if on-demand prices should be looked up even though the on-demand cache was not hydrated
+func (t *priceEnrichmentTracker) lookupOnDemand() bool {
+	return t.budget != nil && t.budget.LookupOnDemandPrices
+}
+
+// lookupSpot returns true if spot prices should be looked up even though the spot cache was not hydrated
+func (t *priceEnrichmentTracker) lookupSpot() bool {
+	return t.budget != nil && t.budget.LookupSpotPrices
+}
+
+// allowLookup returns true and records the lookup if the budget allows another price lookup
+func (t *priceEnrichmentTracker) allowLookup() bool {
+	if t.exhausted {
+		return false
+	}
+	if t.ctx.Err() != nil {
+		t.exhaust("time")
+		return false
+	}
+	if t.budget != nil && t.budget.MaxLookups > 0 && t.lookups >= t.budget.MaxLookups {
+		t.exhaust("lookup")
+		return false
+	}
+	t.lookups++
+	return true
+}
+
+func (t *priceEnrichmentTracker) exhaust(limit string) {
+	t.exhausted = true
+	log.Printf("The price enrichment %s budget was exhausted after %d lookups, the remaining instance types will not have prices\n", limit, t.lookups)
+}
+
+// enrichPrices looks up the on-demand and spot prices of the instance type within the price enrichment budget
+// Prices which are not looked up before the budget is exhausted leave the instance type marked with PriceEnrichmentSkipped
+func (itf Selector) enrichPrices(tracker *priceEnrichmentTracker, details *instancetypes.Details, availabilityZones []string) {
+	instanceTypeName := *details.InstanceType
+	pricingWithContext, cancelable := itf.EC2Pricing.(ec2PricingWithContext)
+	// If prices are fetched, populate the fields irrespective of the price filters
+	if itf.EC2Pricing.LastOnDemandCacheUTC() != nil || tracker.lookupOnDemand() {
+		if !tracker.allowLookup() {
+			details.PriceEnrichmentSkipped = true
+		} else {
+			var price float64
+			var err error
+			if cancelable {
+				price, err = pricingWithContext.GetOndemandInstanceTypeCostWithContext(tracker.ctx, instanceTypeName)
+			} else {
+				price, err = itf.EC2Pricing.GetOndemandInstanceTypeCost(instanceTypeName)
+			}
+			if err != nil && tracker.ctx.Err() != nil {
+				details.PriceEnrichmentSkipped = true
+			} else if err != nil {
+				fmt.Printf("Could not retrieve instantaneous hourly on-demand price for instance type %s\n", instanceTypeName)
+			} else {
+				details.OndemandPricePerHour = &price
+			}
+		}
+	}
+	if itf.EC2Pricing.LastSpotCacheUTC() != nil || tracker.lookupSpot() {
+		if !tracker.allowLookup() {
+			details.PriceEnrichmentSkipped = true
+		} else {
+			var price float64
+			var err error
+			if cancelable {
+				price, err = pricingWithContext.GetSpotInstanceTypeNDayAvgCostWithContext(tracker.ctx, instanceTypeName, availabilityZones, 30)
+			} else {
+				price, err = itf.EC2Pricing.GetSpotInstanceTypeNDayAvgCost(instanceTypeName, availabilityZones, 30)
+			}
+			if err != nil && tracker.ctx.Err() != nil {
+				details.PriceEnrichmentSkipped = true
+			} else if err != nil {
+				fmt.Printf("Could not retrieve 30 day avg hourly spot price for instance type %s\n", instanceTypeName)
+			} else {
+				details.SpotPrice = &price
+			}
+		}
+	}
+}
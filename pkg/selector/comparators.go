@@ -14,12 +14,14 @@
 package selector
 
 import (
-	"log"
 	"math"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/instancetypes"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 )
@@ -27,8 +29,29 @@ import (
 const (
 	supported = "supported"
 	required  = "required"
+
+	// nitroSharedAttachmentLimit is the number of attachments shared between ENIs, EBS volumes, and NVMe instance store volumes on most Nitro instance types
+	nitroSharedAttachmentLimit = 28
+	// bareMetalEBSVolumeLimit is the maximum number of EBS volumes which can be attached to most bare metal instance types
+	bareMetalEBSVolumeLimit = 31
+	// xenEBSVolumeLimit is the recommended maximum number of EBS volumes attached to Xen instance types, beyond which boot failures can occur
+	xenEBSVolumeLimit = 40
 )
 
+// ebsVolumeAttachmentLimits are instance types which have a dedicated EBS volume limit that differs from the limit of their hypervisor
+var ebsVolumeAttachmentLimits = map[string]int{
+	"mac1.metal":        16,
+	"u-6tb1.metal":      19,
+	"u-9tb1.metal":      19,
+	"u-12tb1.metal":     19,
+	"u-18tb1.metal":     19,
+	"u-24tb1.metal":     19,
+	"u-6tb1.56xlarge":   26,
+	"u-6tb1.112xlarge":  26,
+	"u-9tb1.112xlarge":  26,
+	"u-12tb1.112xlarge": 26,
+}
+
 func isSupportedFromString(instanceTypeValue *string, target *string) bool {
 	if target == nil {
 		return true
@@ -129,28 +152,228 @@ func getTotalGpuMemory(gpusInfo *ec2.GpuInfo) *int64 {
 	return gpusInfo.TotalGpuMemoryInMiB
 }
 
+// getNetworkPerformance returns the network performance in whole Gbps, rounding fractional bandwidths like "Up to 12.5 Gigabit" down
+// -1 is returned when the network performance is not a number of Gigabits
 func getNetworkPerformance(networkPerformance *string) *int {
-	if networkPerformance == nil {
+	bandwidth := getNetworkBandwidthGbps(networkPerformance)
+	if bandwidth == nil {
 		return aws.Int(-1)
 	}
-	re, err := regexp.Compile(`[0-9]+ Gigabit`)
+	return aws.Int(int(math.Floor(*bandwidth)))
+}
+
+// networkBandwidthRegex matches network performance bandwidths like "10 Gigabit", "Up to 12.5 Gigabit", or "8 x 100 Gigabit"
+var networkBandwidthRegex = regexp.MustCompile(`(?:([0-9]+) x )?([0-9]+(?:\.[0-9]+)?) Gigabit`)
+
+// getNetworkBandwidthGbps parses the network performance into Gbps, including fractional and multiplied bandwidths
+// "Up to" bandwidths are parsed as their burst bandwidth, and nil is returned for qualitative performance like "High"
+func getNetworkBandwidthGbps(networkPerformance *string) *float64 {
+	if networkPerformance == nil {
+		return nil
+	}
+	matches := networkBandwidthRegex.FindStringSubmatch(*networkPerformance)
+	if matches == nil {
+		return nil
+	}
+	bandwidth, err := strconv.ParseFloat(matches[2], 64)
 	if err != nil {
-		log.Printf("Unable to compile regexp to parse network performance: %s\n", *networkPerformance)
 		return nil
 	}
-	networkBandwidth := re.FindString(*networkPerformance)
-	if networkBandwidth == "" {
-		return aws.Int(-1)
+	if matches[1] != "" {
+		multiplier, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return nil
+		}
+		bandwidth *= float64(multiplier)
 	}
-	bandwidthAndUnit := strings.Split(networkBandwidth, " ")
-	if len(bandwidthAndUnit) != 2 {
-		return aws.Int(-1)
+	return &bandwidth
+}
+
+// getAggregateNetworkBandwidthGbps returns the network bandwidth in Gbps summed across all of the instance type's network cards
+// Instance types without network card info, or whose cards do not report a number of Gigabits, fall back to the network performance
+func getAggregateNetworkBandwidthGbps(networkInfo *ec2.NetworkInfo) *float64 {
+	if networkInfo == nil {
+		return nil
 	}
-	bandwidthNumber, err := strconv.Atoi(bandwidthAndUnit[0])
-	if err != nil {
-		return aws.Int(-1)
+	var aggregate *float64
+	for _, networkCard := range networkInfo.NetworkCards {
+		if networkCard == nil {
+			continue
+		}
+		if bandwidth := getNetworkBandwidthGbps(networkCard.NetworkPerformance); bandwidth != nil {
+			if aggregate == nil {
+				aggregate = aws.Float64(0)
+			}
+			*aggregate += *bandwidth
+		}
+	}
+	if aggregate == nil {
+		return getNetworkBandwidthGbps(networkInfo.NetworkPerformance)
+	}
+	return aggregate
+}
+
+// minAggregateNetworkRange converts a minimum aggregate network bandwidth to an unbounded range filter
+func minAggregateNetworkRange(minAggregateNetworkGbps *float64) *Float64RangeFilter {
+	if minAggregateNetworkGbps == nil {
+		return nil
 	}
-	return aws.Int(bandwidthNumber)
+	return &Float64RangeFilter{LowerBound: *minAggregateNetworkGbps, UpperBound: math.MaxFloat64}
+}
+
+// calculateNetworkPerVCpu returns the network bandwidth in Gbps available to each vcpu
+// nil is returned when the bandwidth is not a number of Gigabits
+func calculateNetworkPerVCpu(vcpusVal *int64, networkPerformance *string) *float64 {
+	bandwidth := getNetworkBandwidthGbps(networkPerformance)
+	if vcpusVal == nil || *vcpusVal == 0 || bandwidth == nil {
+		return nil
+	}
+	result := *bandwidth / float64(*vcpusVal)
+	return &result
+}
+
+// minNetworkPerVCpuRange converts a minimum network bandwidth per vcpu to an unbounded range filter
+func minNetworkPerVCpuRange(minNetworkPerVCpu *float64) *Float64RangeFilter {
+	if minNetworkPerVCpu == nil {
+		return nil
+	}
+	return &Float64RangeFilter{LowerBound: *minNetworkPerVCpu, UpperBound: math.MaxFloat64}
+}
+
+// memoryRangeWithTolerance widens an exact memory range by the tolerance percentage in both directions
+// Ranges which are not exact and ranges without a tolerance are returned as is
+func memoryRangeWithTolerance(memoryRange *ByteQuantityRangeFilter, tolerancePercent *float64) *ByteQuantityRangeFilter {
+	if memoryRange == nil || tolerancePercent == nil || *tolerancePercent <= 0 || memoryRange.LowerBound.Quantity != memoryRange.UpperBound.Quantity {
+		return memoryRange
+	}
+	tolerance := uint64(math.Round(float64(memoryRange.LowerBound.Quantity) * *tolerancePercent / 100))
+	lowerBound := uint64(0)
+	if memoryRange.LowerBound.Quantity > tolerance {
+		lowerBound = memoryRange.LowerBound.Quantity - tolerance
+	}
+	return &ByteQuantityRangeFilter{
+		LowerBound: bytequantity.ByteQuantity{Quantity: lowerBound},
+		UpperBound: bytequantity.ByteQuantity{Quantity: memoryRange.UpperBound.Quantity + tolerance},
+	}
+}
+
+// getSpotSavingsPercent returns the percentage the spot price is below the on-demand price
+// nil is returned when either price is unknown
+func getSpotSavingsPercent(onDemandPrice *float64, spotPrice *float64) *float64 {
+	if onDemandPrice == nil || spotPrice == nil || *onDemandPrice <= 0 || *spotPrice < 0 || math.IsNaN(*spotPrice) {
+		return nil
+	}
+	savingsPercent := (*onDemandPrice - *spotPrice) / *onDemandPrice * 100
+	return &savingsPercent
+}
+
+// minSpotSavingsPercentRange converts a minimum spot savings percentage to a range filter up to 100%
+func minSpotSavingsPercentRange(minSpotSavingsPercent *float64) *Float64RangeFilter {
+	if minSpotSavingsPercent == nil {
+		return nil
+	}
+	return &Float64RangeFilter{LowerBound: *minSpotSavingsPercent, UpperBound: 100}
+}
+
+// isPriceAvailable returns true if the on-demand price was resolved
+// The on-demand price is negative when the instance type was not found in the pricing catalog
+func isPriceAvailable(onDemandPrice *float64) *bool {
+	return aws.Bool(onDemandPrice != nil && *onDemandPrice >= 0)
+}
+
+// requirePriceAvailable only returns a filter when a price is required, so that false does not select unpriced instance types
+func requirePriceAvailable(requirePriceAvailable *bool) *bool {
+	if requirePriceAvailable == nil || !*requirePriceAvailable {
+		return nil
+	}
+	return requirePriceAvailable
+}
+
+// getEBSBaselineThroughput returns the EBS-optimized baseline throughput in MB/s or nil if the instance type is not EBS-optimized
+func getEBSBaselineThroughput(ebsInfo *ec2.EbsInfo) *float64 {
+	if ebsInfo == nil || ebsInfo.EbsOptimizedInfo == nil {
+		return nil
+	}
+	return ebsInfo.EbsOptimizedInfo.BaselineThroughputInMBps
+}
+
+// minEBSBaselineThroughputRange converts a minimum EBS baseline throughput to an unbounded range filter
+func minEBSBaselineThroughputRange(minEBSBaselineThroughput *float64) *Float64RangeFilter {
+	if minEBSBaselineThroughput == nil {
+		return nil
+	}
+	return &Float64RangeFilter{LowerBound: *minEBSBaselineThroughput, UpperBound: math.MaxFloat64}
+}
+
+// getMaxEBSVolumeAttachments returns the maximum number of EBS volumes, including the root volume, which can be attached to an instance type
+// Nitro instance types share their attachment limit with ENIs and NVMe instance store volumes, so the count assumes only the primary ENI is attached
+func getMaxEBSVolumeAttachments(instanceTypeInfo *ec2.InstanceTypeInfo) *int {
+	if instanceTypeInfo.InstanceType != nil {
+		if limit, ok := ebsVolumeAttachmentLimits[*instanceTypeInfo.InstanceType]; ok {
+			return aws.Int(limit)
+		}
+	}
+	if instanceTypeInfo.BareMetal != nil && *instanceTypeInfo.BareMetal {
+		return aws.Int(bareMetalEBSVolumeLimit)
+	}
+	if instanceTypeInfo.Hypervisor == nil {
+		return nil
+	}
+	switch *instanceTypeInfo.Hypervisor {
+	case ec2.InstanceTypeHypervisorNitro:
+		// one attachment is always used by the primary ENI
+		limit := nitroSharedAttachmentLimit - 1
+		if instanceTypeInfo.InstanceStorageInfo != nil {
+			for _, disk := range instanceTypeInfo.InstanceStorageInfo.Disks {
+				if disk.Count != nil {
+					limit -= int(*disk.Count)
+				}
+			}
+		}
+		return aws.Int(limit)
+	case ec2.InstanceTypeHypervisorXen:
+		return aws.Int(xenEBSVolumeLimit)
+	}
+	return nil
+}
+
+// getInstanceStoreReadThroughput returns the approximate NVMe instance store read throughput in MB/s of the instance type
+// nil is returned if the instance store throughput of the family is not published
+func getInstanceStoreReadThroughput(instanceTypeInfo *ec2.InstanceTypeInfo) *float64 {
+	if instanceTypeInfo.InstanceType == nil || instanceTypeInfo.VCpuInfo.DefaultVCpus == nil {
+		return nil
+	}
+	return instancetypes.InstanceStoreReadThroughput(*instanceTypeInfo.InstanceType, *instanceTypeInfo.VCpuInfo.DefaultVCpus)
+}
+
+// minInstanceStoreThroughputRange converts a minimum instance store throughput to an unbounded range filter
+func minInstanceStoreThroughputRange(minInstanceStoreThroughput *float64) *Float64RangeFilter {
+	if minInstanceStoreThroughput == nil {
+		return nil
+	}
+	return &Float64RangeFilter{LowerBound: *minInstanceStoreThroughput, UpperBound: math.MaxFloat64}
+}
+
+// getFamilyAgeDays returns the number of whole days since the family of the instance type launched
+// nil is returned if the launch date of the family is not known
+func getFamilyAgeDays(instanceType *string) *int {
+	if instanceType == nil {
+		return nil
+	}
+	launchDate := instancetypes.FamilyLaunchDate(*instanceType)
+	if launchDate == nil {
+		return nil
+	}
+	return aws.Int(int(time.Since(*launchDate).Hours() / 24))
+}
+
+// hasAcceleratedNetworking returns true if the instance type supports enhanced networking through ENA
+// ENA is an SR-IOV based interface, so instance types supporting it also meet the SR-IOV requirement
+func hasAcceleratedNetworking(instanceTypeInfo *ec2.InstanceTypeInfo) *bool {
+	if instanceTypeInfo.NetworkInfo == nil {
+		return nil
+	}
+	return supportSyntaxToBool(instanceTypeInfo.NetworkInfo.EnaSupport)
 }
 
 // supportSyntaxToBool takes an instance spec field that uses ["unsupported", "supported", or "required"]
@@ -174,6 +397,25 @@ func calculateVCpusToMemoryRatio(vcpusVal *int64, memoryVal *int64) *float64 {
 	return &result
 }
 
+// calculateVCpusPerGpu returns the number of vcpus available to each GPU
+// nil is returned for instance types without GPUs
+func calculateVCpusPerGpu(vcpusVal *int64, gpusInfo *ec2.GpuInfo) *float64 {
+	gpus := getTotalGpusCount(gpusInfo)
+	if vcpusVal == nil || gpus == nil || *gpus == 0 {
+		return nil
+	}
+	result := float64(*vcpusVal) / float64(*gpus)
+	return &result
+}
+
+// minVCpusPerGpuRange converts a minimum number of vcpus per GPU to an unbounded range filter
+func minVCpusPerGpuRange(minVCpusPerGpu *float64) *Float64RangeFilter {
+	if minVCpusPerGpu == nil {
+		return nil
+	}
+	return &Float64RangeFilter{LowerBound: *minVCpusPerGpu, UpperBound: math.MaxFloat64}
+}
+
 // Slice helper function
 
 func contains(slice []*string, target string) bool {
@@ -19,6 +19,7 @@ import (
 
 	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
 )
 
 func TestIsSupportedFromStrings_Supported(t *testing.T) {
@@ -308,6 +309,12 @@ func TestGetNetworkPerformance(t *testing.T) {
 	netPerformance = getNetworkPerformance(aws.String("100 Gigabit"))
 	h.Assert(t, *netPerformance == 100, "Networking performance should parse properly")
 
+	netPerformance = getNetworkPerformance(aws.String("Up to 12.5 Gigabit"))
+	h.Assert(t, *netPerformance == 12, "Fractional networking performance should be rounded down")
+
+	netPerformance = getNetworkPerformance(aws.String("8 x 100 Gigabit"))
+	h.Assert(t, *netPerformance == 800, "Multiplied networking performance should parse properly")
+
 	netPerformance = getNetworkPerformance(aws.String("10 Gigabit abcd"))
 	h.Assert(t, *netPerformance == 10, "Networking performance should parse properly when an arbitrary string is passed after quantity-unit syntax")
 
@@ -323,3 +330,62 @@ func TestGetNetworkPerformance(t *testing.T) {
 	netPerformance = getNetworkPerformance(aws.String("abcd"))
 	h.Assert(t, *netPerformance == -1, "Networking performance should parse properly when an arbitrary string is passed")
 }
+
+func TestGetNetworkBandwidthGbps(t *testing.T) {
+	h.Equals(t, aws.Float64(10), getNetworkBandwidthGbps(aws.String("10 Gigabit")))
+	h.Equals(t, aws.Float64(12.5), getNetworkBandwidthGbps(aws.String("Up to 12.5 Gigabit")))
+	h.Equals(t, aws.Float64(800), getNetworkBandwidthGbps(aws.String("8 x 100 Gigabit")))
+	h.Assert(t, getNetworkBandwidthGbps(aws.String("High")) == nil, "Qualitative network performance should not parse")
+	h.Assert(t, getNetworkBandwidthGbps(nil) == nil, "Nil network performance should not parse")
+}
+
+func TestGetAggregateNetworkBandwidthGbps(t *testing.T) {
+	multiCard := &ec2.NetworkInfo{
+		NetworkPerformance: aws.String("4x 100 Gigabit"),
+		NetworkCards: []*ec2.NetworkCardInfo{
+			{NetworkCardIndex: aws.Int64(0), NetworkPerformance: aws.String("100 Gigabit")},
+			{NetworkCardIndex: aws.Int64(1), NetworkPerformance: aws.String("100 Gigabit")},
+			{NetworkCardIndex: aws.Int64(2), NetworkPerformance: aws.String("100 Gigabit")},
+			{NetworkCardIndex: aws.Int64(3), NetworkPerformance: aws.String("100 Gigabit")},
+		},
+	}
+	h.Equals(t, aws.Float64(400), getAggregateNetworkBandwidthGbps(multiCard))
+	h.Equals(t, aws.Float64(10), getAggregateNetworkBandwidthGbps(&ec2.NetworkInfo{NetworkPerformance: aws.String("Up to 10 Gigabit")}))
+	qualitativeCards := &ec2.NetworkInfo{
+		NetworkPerformance: aws.String("25 Gigabit"),
+		NetworkCards:       []*ec2.NetworkCardInfo{{NetworkCardIndex: aws.Int64(0), NetworkPerformance: aws.String("High")}},
+	}
+	h.Equals(t, aws.Float64(25), getAggregateNetworkBandwidthGbps(qualitativeCards))
+	h.Assert(t, getAggregateNetworkBandwidthGbps(&ec2.NetworkInfo{NetworkPerformance: aws.String("Moderate")}) == nil, "Qualitative network performance should not parse")
+	h.Assert(t, getAggregateNetworkBandwidthGbps(nil) == nil, "Nil network info should not parse")
+}
+
+func TestCalculateNetworkPerVCpu(t *testing.T) {
+	h.Equals(t, aws.Float64(1.25), calculateNetworkPerVCpu(aws.Int64(8), aws.String("Up to 10 Gigabit")))
+	h.Assert(t, calculateNetworkPerVCpu(aws.Int64(0), aws.String("10 Gigabit")) == nil, "Zero vcpus should not have a ratio")
+	h.Assert(t, calculateNetworkPerVCpu(aws.Int64(2), aws.String("Moderate")) == nil, "Qualitative network performance should not have a ratio")
+}
+
+func TestGetMaxEBSVolumeAttachments(t *testing.T) {
+	nitro := &ec2.InstanceTypeInfo{InstanceType: aws.String("c5.large"), Hypervisor: aws.String("nitro")}
+	h.Equals(t, 27, *getMaxEBSVolumeAttachments(nitro))
+
+	nitroNVMe := &ec2.InstanceTypeInfo{
+		InstanceType:        aws.String("c5d.4xlarge"),
+		Hypervisor:          aws.String("nitro"),
+		InstanceStorageInfo: &ec2.InstanceStorageInfo{Disks: []*ec2.DiskInfo{{Count: aws.Int64(1)}}},
+	}
+	h.Equals(t, 26, *getMaxEBSVolumeAttachments(nitroNVMe))
+
+	xen := &ec2.InstanceTypeInfo{InstanceType: aws.String("c4.large"), Hypervisor: aws.String("xen")}
+	h.Equals(t, 40, *getMaxEBSVolumeAttachments(xen))
+
+	metal := &ec2.InstanceTypeInfo{InstanceType: aws.String("c5.metal"), BareMetal: aws.Bool(true)}
+	h.Equals(t, 31, *getMaxEBSVolumeAttachments(metal))
+
+	mac := &ec2.InstanceTypeInfo{InstanceType: aws.String("mac1.metal"), BareMetal: aws.Bool(true)}
+	h.Equals(t, 16, *getMaxEBSVolumeAttachments(mac))
+
+	unknown := &ec2.InstanceTypeInfo{InstanceType: aws.String("x9.large")}
+	h.Assert(t, getMaxEBSVolumeAttachments(unknown) == nil, "Instance types without a hypervisor should not have an EBS volume limit")
+}
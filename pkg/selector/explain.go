@@ -0,0 +1,137 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// ExplainExclusion evaluates each of the filters independently against the instance type and returns a description of every
+// filter the instance type fails, including the instance type's actual value and the value the filter requires.
+// An empty slice is returned when the instance type matches all of the filters.
+func (itf Selector) ExplainExclusion(instanceType string, filters Filters) ([]string, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+	filters, err := itf.AggregateFilterTransform(filters)
+	if err != nil {
+		return nil, err
+	}
+	normalizeFilterAliases(filters)
+	instanceTypeInfo, err := itf.describeInstanceType(instanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	var availabilityZones, locations []string
+	if filters.AvailabilityZones != nil {
+		availabilityZones = *filters.AvailabilityZones
+		locations = *filters.AvailabilityZones
+	} else if filters.Region != nil {
+		locations = []string{*filters.Region}
+	}
+	locationInstanceOfferings, err := itf.RetrieveInstanceTypesSupportedInLocations(locations)
+	if err != nil {
+		return nil, err
+	}
+
+	var onDemandPrice, spotPrice *float64
+	if itf.EC2Pricing.LastOnDemandCacheUTC() != nil {
+		// a negative price means the price is not known rather than free
+		if price, err := itf.EC2Pricing.GetOndemandInstanceTypeCost(instanceType); err == nil && price >= 0 {
+			onDemandPrice = &price
+		}
+	}
+	if itf.EC2Pricing.LastSpotCacheUTC() != nil {
+		if price, err := itf.EC2Pricing.GetSpotInstanceTypeNDayAvgCost(instanceType, availabilityZones, 30); err == nil && price >= 0 {
+			spotPrice = &price
+		}
+	}
+
+	failures := []string{}
+	if isInDenyList(filters.DenyList, instanceType) {
+		failures = append(failures, fmt.Sprintf("%s: instance type matches %s", denyList, filters.DenyList.String()))
+	}
+	if !isInAllowList(filters.AllowList, instanceType) {
+		failures = append(failures, fmt.Sprintf("%s: instance type does not match %s", allowList, filters.AllowList.String()))
+	}
+	if !isSupportedInLocation(locationInstanceOfferings, instanceType) {
+		failures = append(failures, fmt.Sprintf("locations: instance type is not offered in %s", strings.Join(locations, ", ")))
+	}
+
+	filterToInstanceSpecMappingPairs := filterPairs(filters, instanceTypeInfo, onDemandPrice, spotPrice)
+	filterNames := []string{}
+	for filterName := range filterToInstanceSpecMappingPairs {
+		filterNames = append(filterNames, filterName)
+	}
+	sort.Strings(filterNames)
+	for _, filterName := range filterNames {
+		pair := filterToInstanceSpecMappingPairs[filterName]
+		isSupported, err := executeFilter(filterName, pair, instanceType)
+		if err != nil {
+			return nil, err
+		}
+		if !isSupported {
+			failures = append(failures, fmt.Sprintf("%s: instance type has %s, filter requires %s", filterName, describeValue(pair.instanceSpec), describeValue(pair.filterValue)))
+		}
+	}
+	return failures, nil
+}
+
+// describeInstanceType returns the instance type info of a single instance type returned from DescribeInstanceTypes
+func (itf Selector) describeInstanceType(instanceType string) (*ec2.InstanceTypeInfo, error) {
+	instanceTypesOutput, err := itf.EC2.DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []*string{&instanceType},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, instanceTypeInfo := range instanceTypesOutput.InstanceTypes {
+		if instanceTypeInfo.InstanceType != nil && *instanceTypeInfo.InstanceType == instanceType {
+			fillMissingMetadata(instanceTypeInfo)
+			return instanceTypeInfo, nil
+		}
+	}
+	return nil, fmt.Errorf("instance type %s was not returned from DescribeInstanceTypes", instanceType)
+}
+
+// describeValue formats a filter value or instance spec for an exclusion explanation, dereferencing pointers
+// and reporting nil values as unknown
+func describeValue(value interface{}) string {
+	if stringSlice, ok := value.([]*string); ok {
+		values := []string{}
+		for _, s := range stringSlice {
+			if s != nil {
+				values = append(values, *s)
+			}
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	}
+	reflected := reflect.ValueOf(value)
+	if !reflected.IsValid() {
+		return "unknown"
+	}
+	if reflected.Kind() == reflect.Ptr {
+		if reflected.IsNil() {
+			return "unknown"
+		}
+		return fmt.Sprintf("%+v", reflected.Elem().Interface())
+	}
+	return fmt.Sprintf("%+v", value)
+}
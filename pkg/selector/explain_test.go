@@ -0,0 +1,99 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestExplainExclusion(t *testing.T) {
+	itf := selector.Selector{
+		EC2:        setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: &ec2PricingMock{},
+	}
+	filters := selector.Filters{
+		VCpusRange:      &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+		CPUArchitecture: aws.String("arm64"),
+	}
+
+	failures, err := itf.ExplainExclusion("a1.large", filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{}, failures)
+
+	failures, err = itf.ExplainExclusion("c5.large", filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"cpuArchitecture: instance type has [x86_64], filter requires arm64"}, failures)
+
+	failures, err = itf.ExplainExclusion("c4.xlarge", filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{
+		"cpuArchitecture: instance type has [x86_64], filter requires arm64",
+		"vcpusRange: instance type has 4, filter requires {UpperBound:2 LowerBound:2}",
+	}, failures)
+}
+
+func TestExplainExclusion_DenyList(t *testing.T) {
+	itf := selector.Selector{
+		EC2:        setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: &ec2PricingMock{},
+	}
+	failures, err := itf.ExplainExclusion("c5.large", selector.Filters{DenyList: regexp.MustCompile("^c5")})
+	h.Ok(t, err)
+	h.Equals(t, []string{"denyList: instance type matches ^c5"}, failures)
+}
+
+func TestExplainExclusion_UnknownInstanceType(t *testing.T) {
+	itf := selector.Selector{
+		EC2:        setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: &ec2PricingMock{},
+	}
+	_, err := itf.ExplainExclusion("x9.large", selector.Filters{})
+	h.Nok(t, err)
+}
+
+func TestExplainExclusion_DescribesSingleInstanceType(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypesPages, "25_instances.json")
+	// the instance type is described on its own rather than by paging through every instance type
+	ec2Mock.DescribeInstanceTypesPagesErr = errors.New("the instance types should not be paged through")
+	itf := selector.Selector{
+		EC2:        ec2Mock,
+		EC2Pricing: &ec2PricingMock{},
+	}
+	failures, err := itf.ExplainExclusion("c5.large", selector.Filters{CPUArchitecture: aws.String("arm64")})
+	h.Ok(t, err)
+	h.Equals(t, []string{"cpuArchitecture: instance type has [x86_64], filter requires arm64"}, failures)
+}
+
+func TestExplainExclusion_UnknownPrice(t *testing.T) {
+	now := time.Now()
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp: -1,
+			lastOnDemandCacheUTC:            &now,
+		},
+	}
+	failures, err := itf.ExplainExclusion("c5.large", selector.Filters{
+		PricePerHour: &selector.Float64RangeFilter{LowerBound: 0, UpperBound: 1},
+	})
+	h.Ok(t, err)
+	h.Equals(t, []string{"pricePerHour: instance type has unknown, filter requires {UpperBound:1 LowerBound:0}"}, failures)
+}
@@ -0,0 +1,74 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// GlobsToRegexp compiles instance type glob patterns into a single regex which matches an instance type name
+// if any of the globs match the whole name. The result can be used as the AllowList or DenyList filter.
+// Globs support * (any characters), ? (a single character), [...] character classes (negated with ^),
+// and \ to escape a special character.
+// Example: []string{"c6g.*", "*.xlarge"}
+func GlobsToRegexp(globs []string) (*regexp.Regexp, error) {
+	if len(globs) == 0 {
+		return nil, fmt.Errorf("at least one glob pattern must be specified")
+	}
+	globRegexes := []string{}
+	for _, glob := range globs {
+		// path.Match reports malformed patterns regardless of the name being matched
+		if _, err := path.Match(glob, ""); err != nil || glob == "" {
+			return nil, fmt.Errorf("invalid instance type glob pattern %q", glob)
+		}
+		globRegexes = append(globRegexes, globToRegex(glob))
+	}
+	return regexp.Compile("^(?:" + strings.Join(globRegexes, "|") + ")$")
+}
+
+// globToRegex converts a valid glob pattern into an equivalent unanchored regex
+func globToRegex(glob string) string {
+	var regex strings.Builder
+	inClass := false
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '\\' && i+1 < len(glob):
+			i++
+			regex.WriteString(regexp.QuoteMeta(string(glob[i])))
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+			regex.WriteByte(c)
+		case c == '[':
+			inClass = true
+			regex.WriteByte(c)
+			if i+1 < len(glob) && glob[i+1] == '^' {
+				i++
+				regex.WriteByte('^')
+			}
+		case c == '*':
+			regex.WriteString(".*")
+		case c == '?':
+			regex.WriteString(".")
+		default:
+			regex.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return regex.String()
+}
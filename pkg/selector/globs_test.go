@@ -0,0 +1,61 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+)
+
+func TestGlobsToRegexp(t *testing.T) {
+	globRegex, err := selector.GlobsToRegexp([]string{"c6g.*", "*.xlarge"})
+	h.Ok(t, err)
+	for _, instanceType := range []string{"c6g.large", "c6g.metal", "m5.xlarge", "c5.xlarge"} {
+		h.Assert(t, globRegex.MatchString(instanceType), "%s should match the globs", instanceType)
+	}
+	for _, instanceType := range []string{"c6gd.large", "m5.2xlarge", "c6g", "xc6g.large"} {
+		h.Assert(t, !globRegex.MatchString(instanceType), "%s should not match the globs", instanceType)
+	}
+
+	globRegex, err = selector.GlobsToRegexp([]string{"m[45].?large", "[^a-c]*.metal"})
+	h.Ok(t, err)
+	h.Assert(t, globRegex.MatchString("m5.xlarge"), "m5.xlarge should match the character class glob")
+	h.Assert(t, !globRegex.MatchString("m6.xlarge"), "m6.xlarge should not match the character class glob")
+	h.Assert(t, !globRegex.MatchString("m5.2xlarge"), "? should only match a single character")
+	h.Assert(t, globRegex.MatchString("i3.metal"), "i3.metal should match the negated character class glob")
+	h.Assert(t, !globRegex.MatchString("c5.metal"), "c5.metal should not match the negated character class glob")
+}
+
+func TestGlobsToRegexp_Invalid(t *testing.T) {
+	_, err := selector.GlobsToRegexp([]string{"c6g.[large"})
+	h.Nok(t, err)
+	_, err = selector.GlobsToRegexp([]string{""})
+	h.Nok(t, err)
+	_, err = selector.GlobsToRegexp([]string{})
+	h.Nok(t, err)
+}
+
+func TestFilter_AllowListGlob(t *testing.T) {
+	allowList, err := selector.GlobsToRegexp([]string{"c4.*", "*.24xlarge"})
+	h.Ok(t, err)
+	itf := selector.Selector{
+		EC2:        setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: &ec2PricingMock{},
+	}
+	results, err := itf.Filter(selector.Filters{AllowList: allowList})
+	h.Ok(t, err)
+	h.Equals(t, []string{"c4.2xlarge", "c4.4xlarge", "c4.8xlarge", "c4.large", "c4.xlarge", "c5.24xlarge"}, results)
+}
@@ -0,0 +1,103 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/instancetypes"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// GpuTiers are the GPU compute capability tiers (architecture generations) ordered from oldest to newest
+var GpuTiers = []string{"kepler", "maxwell", "pascal", "volta", "turing", "ampere", "ada", "hopper"}
+
+// gpuModelTiers maps the GPU model names reported by DescribeInstanceTypes to their compute capability tier
+// GPU models which are not listed here (non-NVIDIA GPUs for example) do not have a tier
+var gpuModelTiers = map[string]string{
+	"K80":   "kepler",
+	"K520":  "kepler",
+	"M60":   "maxwell",
+	"V100":  "volta",
+	"T4":    "turing",
+	"T4g":   "turing",
+	"A100":  "ampere",
+	"A10G":  "ampere",
+	"L4":    "ada",
+	"L40S":  "ada",
+	"H100":  "hopper",
+	"H200":  "hopper",
+	"GH200": "hopper",
+}
+
+// gpuTierRank returns the position of the tier within GpuTiers starting at 1, or 0 if the tier is unknown
+func gpuTierRank(tier string) int {
+	for i, t := range GpuTiers {
+		if strings.EqualFold(t, tier) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// getGpuTierRank returns the rank of the lowest GPU tier present on an instance type
+// Instance types without GPUs return nil and instance types with GPUs of an unknown model return 0
+func getGpuTierRank(gpusInfo *ec2.GpuInfo) *int {
+	if gpusInfo == nil || len(gpusInfo.Gpus) == 0 {
+		return nil
+	}
+	lowestRank := math.MaxInt32
+	for _, gpu := range gpusInfo.Gpus {
+		rank := 0
+		if gpu.Name != nil {
+			rank = gpuTierRank(gpuModelTiers[*gpu.Name])
+		}
+		if rank < lowestRank {
+			lowestRank = rank
+		}
+	}
+	return aws.Int(lowestRank)
+}
+
+// minGpuTierRange transforms a minimum GPU tier into a range filter over GPU tier ranks
+func minGpuTierRange(minGpuTier *string) *IntRangeFilter {
+	if minGpuTier == nil {
+		return nil
+	}
+	return &IntRangeFilter{LowerBound: gpuTierRank(*minGpuTier), UpperBound: len(GpuTiers)}
+}
+
+// SortByGpuTier sorts instance types by their GPU compute capability tier from newest to oldest
+// Instance types with GPUs of an unknown model and instance types without GPUs are sorted last
+// Instance types within the same tier are sorted alpha-numerically
+func SortByGpuTier(instanceTypeInfoSlice []instancetypes.Details) []instancetypes.Details {
+	rank := func(instanceTypeInfo instancetypes.Details) int {
+		tierRank := getGpuTierRank(instanceTypeInfo.GpuInfo)
+		if tierRank == nil {
+			return -1
+		}
+		return *tierRank
+	}
+	sort.SliceStable(instanceTypeInfoSlice, func(i, j int) bool {
+		iRank, jRank := rank(instanceTypeInfoSlice[i]), rank(instanceTypeInfoSlice[j])
+		if iRank != jRank {
+			return iRank > jRank
+		}
+		return strings.Compare(*instanceTypeInfoSlice[i].InstanceType, *instanceTypeInfoSlice[j].InstanceType) < 0
+	})
+	return instanceTypeInfoSlice
+}
@@ -0,0 +1,49 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func gpuInstanceType(instanceType string, gpuName string) instancetypes.Details {
+	details := instancetypes.Details{InstanceTypeInfo: ec2.InstanceTypeInfo{InstanceType: aws.String(instanceType)}}
+	if gpuName != "" {
+		details.GpuInfo = &ec2.GpuInfo{Gpus: []*ec2.GpuDeviceInfo{{Name: aws.String(gpuName), Count: aws.Int64(1)}}}
+	}
+	return details
+}
+
+func TestSortByGpuTier(t *testing.T) {
+	instanceTypes := []instancetypes.Details{
+		gpuInstanceType("c5.large", ""),
+		gpuInstanceType("g2.2xlarge", "K520"),
+		gpuInstanceType("p4d.24xlarge", "A100"),
+		gpuInstanceType("g4ad.xlarge", "Radeon Pro V520"),
+		gpuInstanceType("g5.xlarge", "A10G"),
+		gpuInstanceType("p3.2xlarge", "V100"),
+	}
+	sorted := selector.SortByGpuTier(instanceTypes)
+	names := []string{}
+	for _, it := range sorted {
+		names = append(names, *it.InstanceType)
+	}
+	h.Equals(t, []string{"g5.xlarge", "p4d.24xlarge", "p3.2xlarge", "g2.2xlarge", "g4ad.xlarge", "c5.large"}, names)
+}
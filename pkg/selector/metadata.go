@@ -0,0 +1,127 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"log"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/instancetypes"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// fillMissingMetadata replaces missing attribute groups of the instance type info with empty ones so that filters
+// and outputs can treat the attributes as unknown. The names of the missing attribute groups are returned.
+func fillMissingMetadata(instanceTypeInfo *ec2.InstanceTypeInfo) []string {
+	missing := []string{}
+	if instanceTypeInfo.ProcessorInfo == nil {
+		instanceTypeInfo.ProcessorInfo = &ec2.ProcessorInfo{}
+		missing = append(missing, "ProcessorInfo")
+	}
+	if instanceTypeInfo.VCpuInfo == nil {
+		instanceTypeInfo.VCpuInfo = &ec2.VCpuInfo{}
+		missing = append(missing, "VCpuInfo")
+	}
+	if instanceTypeInfo.MemoryInfo == nil {
+		instanceTypeInfo.MemoryInfo = &ec2.MemoryInfo{}
+		missing = append(missing, "MemoryInfo")
+	}
+	if instanceTypeInfo.NetworkInfo == nil {
+		instanceTypeInfo.NetworkInfo = &ec2.NetworkInfo{}
+		missing = append(missing, "NetworkInfo")
+	}
+	if instanceTypeInfo.PlacementGroupInfo == nil {
+		instanceTypeInfo.PlacementGroupInfo = &ec2.PlacementGroupInfo{}
+		missing = append(missing, "PlacementGroupInfo")
+	}
+	return missing
+}
+
+// logMissingMetadata fills and logs the missing attribute groups of an instance type returned from DescribeInstanceTypes
+func logMissingMetadata(instanceTypeInfo *ec2.InstanceTypeInfo) {
+	if missing := fillMissingMetadata(instanceTypeInfo); len(missing) != 0 {
+		log.Printf("Instance type %s is missing metadata (%s), its attributes will be reported as unknown\n", *instanceTypeInfo.InstanceType, strings.Join(missing, ", "))
+	}
+}
+
+// catalogOnlyInstanceTypes returns the explicitly requested instance types which were not returned from DescribeInstanceTypes
+// but do have a price in the pricing catalog. Newly launched instance types can be priced before their metadata is available,
+// so they are returned with unknown attributes rather than dropped. Attribute filters cannot be evaluated against these
+// instance types, so none are returned when any attribute filter is set. Otherwise only the allow list, deny list,
+// usage class, and price and spot savings filters are applied. The prices are looked up within the price enrichment budget,
+// and instance types left without a price by an exhausted budget are not returned since they cannot be confirmed to exist.
+func (itf Selector) catalogOnlyInstanceTypes(filters Filters, describedInstanceTypes map[string]bool, availabilityZones []string, priceEnrichment *priceEnrichmentTracker) []instancetypes.Details {
+	if filters.InstanceTypes == nil || hasAttributeFilters(filters) {
+		return nil
+	}
+	catalogOnly := []instancetypes.Details{}
+	for _, instanceTypeName := range *filters.InstanceTypes {
+		if describedInstanceTypes[instanceTypeName] {
+			continue
+		}
+		if isInDenyList(filters.DenyList, instanceTypeName) || !isInAllowList(filters.AllowList, instanceTypeName) {
+			continue
+		}
+		details := instancetypes.Details{InstanceTypeInfo: ec2.InstanceTypeInfo{InstanceType: aws.String(instanceTypeName)}}
+		fillMissingMetadata(&details.InstanceTypeInfo)
+		itf.enrichPrices(priceEnrichment, &details, availabilityZones)
+		// the pricing catalog returns a negative on-demand price for instance types it does not list
+		if details.OndemandPricePerHour != nil && *details.OndemandPricePerHour < 0 {
+			details.OndemandPricePerHour = nil
+		}
+		if details.SpotPrice != nil && (*details.SpotPrice < 0 || math.IsNaN(*details.SpotPrice)) {
+			details.SpotPrice = nil
+		}
+		if filters.RequirePriceAvailable != nil && *filters.RequirePriceAvailable && !*isPriceAvailable(details.OndemandPricePerHour) {
+			continue
+		}
+		if details.OndemandPricePerHour == nil && details.SpotPrice == nil {
+			log.Printf("Instance type %s was not returned from DescribeInstanceTypes and has no price in the pricing catalog\n", instanceTypeName)
+			continue
+		}
+		price := details.OndemandPricePerHour
+		if filters.UsageClass != nil && *filters.UsageClass == "spot" {
+			price = details.SpotPrice
+		}
+		// the usage class is only known to be supported when the catalog has a price for it
+		if filters.UsageClass != nil && price == nil {
+			continue
+		}
+		if filters.PricePerHour != nil && !isSupportedWithRangeFloat64(price, filters.PricePerHour) {
+			continue
+		}
+		if !isSupportedWithRangeFloat64(getSpotSavingsPercent(details.OndemandPricePerHour, details.SpotPrice), minSpotSavingsPercentRange(filters.MinSpotSavingsPercent)) {
+			continue
+		}
+		log.Printf("Instance type %s was not returned from DescribeInstanceTypes but is in the pricing catalog, its attributes will be reported as unknown\n", instanceTypeName)
+		catalogOnly = append(catalogOnly, details)
+	}
+	return catalogOnly
+}
+
+// hasAttributeFilters returns true if any filter needs instance type attributes from DescribeInstanceTypes to be evaluated
+func hasAttributeFilters(filters Filters) bool {
+	instanceTypeInfo := &ec2.InstanceTypeInfo{}
+	fillMissingMetadata(instanceTypeInfo)
+	for filterName, filterPair := range filterPairs(filters, instanceTypeInfo, nil, nil) {
+		isCatalogFilter := filterName == instanceTypes || filterName == usageClass || priceFilters[filterName]
+		if !isCatalogFilter && !reflect.ValueOf(filterPair.filterValue).IsNil() {
+			return true
+		}
+	}
+	return false
+}
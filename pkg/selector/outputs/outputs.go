@@ -49,6 +49,21 @@ func VerboseInstanceTypeOutput(instanceTypeInfoSlice []instancetypes.Details) []
 	return []string{string(output)}
 }
 
+// NDJSONOutput is an OutputFn which outputs each instance type's attributes and prices as a single line of JSON.
+// Newline-delimited JSON can be ingested directly by most data lake and analytics tools.
+func NDJSONOutput(instanceTypeInfoSlice []instancetypes.Details) []string {
+	lines := []string{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		line, err := json.Marshal(instanceTypeInfo)
+		if err != nil {
+			log.Printf("Unable to convert instance type %s to JSON: %v\n", *instanceTypeInfo.InstanceType, err)
+			continue
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
 // TerraformSpotMixedInstancesPolicyHCLOutput is an OutputFn which returns an ASG MixedInstancePolicy in Terraform HCL syntax
 func TerraformSpotMixedInstancesPolicyHCLOutput(instanceTypeInfoSlice []instancetypes.Details) []string {
 	instanceTypeOverrides := instanceTypeInfoToOverrides(instanceTypeInfoSlice)
@@ -178,10 +193,10 @@ func TableOutputShort(instanceTypeInfoSlice []instancetypes.Details) []string {
 	fmt.Fprintf(w, "\n"+headerFormat, separators...)
 
 	for _, instanceTypeInfo := range instanceTypeInfoSlice {
-		fmt.Fprintf(w, "\n%s\t%d\t%s\t",
+		fmt.Fprintf(w, "\n%s\t%s\t%s\t",
 			*instanceTypeInfo.InstanceType,
-			*instanceTypeInfo.VCpuInfo.DefaultVCpus,
-			formatFloat(float64(*instanceTypeInfo.MemoryInfo.SizeInMiB)/1024.0),
+			formatOptionalInt64(instanceTypeInfo.VCpuInfo.DefaultVCpus),
+			formatOptionalMiBAsGiB(instanceTypeInfo.MemoryInfo.SizeInMiB),
 		)
 	}
 	w.Flush()
@@ -215,6 +230,7 @@ func TableOutputWide(instanceTypeInfoSlice []instancetypes.Details) []string {
 		"GPUs",
 		"GPU Mem (GiB)",
 		"GPU Info",
+		"Family Launch Date",
 		onDemandPricePerHourHeader,
 		spotPricePerHourHeader,
 	}
@@ -237,6 +253,10 @@ func TableOutputWide(instanceTypeInfoSlice []instancetypes.Details) []string {
 		for _, cpuArch := range instanceTypeInfo.ProcessorInfo.SupportedArchitectures {
 			cpuArchitectures = append(cpuArchitectures, *cpuArch)
 		}
+		cpuArchitecturesStr := strings.Join(cpuArchitectures, ", ")
+		if len(cpuArchitectures) == 0 {
+			cpuArchitecturesStr = unknownAttribute
+		}
 		gpus := int64(0)
 		gpuMemory := int64(0)
 		gpuType := []string{}
@@ -248,6 +268,11 @@ func TableOutputWide(instanceTypeInfoSlice []instancetypes.Details) []string {
 			}
 		}
 
+		launchDateStr := "unknown"
+		if launchDate := instancetypes.FamilyLaunchDate(*instanceTypeInfo.InstanceType); launchDate != nil {
+			launchDateStr = launchDate.Format("2006-01-02")
+		}
+
 		onDemandPricePerHourStr := "-Not Fetched-"
 		spotPricePerHourStr := "-Not Fetched-"
 		if instanceTypeInfo.OndemandPricePerHour != nil {
@@ -257,19 +282,20 @@ func TableOutputWide(instanceTypeInfoSlice []instancetypes.Details) []string {
 			spotPricePerHourStr = fmt.Sprintf("$%s", formatFloat(*instanceTypeInfo.SpotPrice))
 		}
 
-		fmt.Fprintf(w, "\n%s\t%d\t%s\t%s\t%t\t%t\t%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t",
+		fmt.Fprintf(w, "\n%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t",
 			*instanceTypeInfo.InstanceType,
-			*instanceTypeInfo.VCpuInfo.DefaultVCpus,
-			formatFloat(float64(*instanceTypeInfo.MemoryInfo.SizeInMiB)/1024.0),
+			formatOptionalInt64(instanceTypeInfo.VCpuInfo.DefaultVCpus),
+			formatOptionalMiBAsGiB(instanceTypeInfo.MemoryInfo.SizeInMiB),
 			*hypervisor,
-			*instanceTypeInfo.CurrentGeneration,
-			*instanceTypeInfo.HibernationSupported,
-			strings.Join(cpuArchitectures, ", "),
-			*instanceTypeInfo.NetworkInfo.NetworkPerformance,
-			*instanceTypeInfo.NetworkInfo.MaximumNetworkInterfaces,
+			formatOptionalBool(instanceTypeInfo.CurrentGeneration),
+			formatOptionalBool(instanceTypeInfo.HibernationSupported),
+			cpuArchitecturesStr,
+			formatOptionalString(instanceTypeInfo.NetworkInfo.NetworkPerformance),
+			formatOptionalInt64(instanceTypeInfo.NetworkInfo.MaximumNetworkInterfaces),
 			gpus,
 			formatFloat(float64(gpuMemory)/1024.0),
 			strings.Join(gpuType, ", "),
+			launchDateStr,
 			onDemandPricePerHourStr,
 			spotPricePerHourStr,
 		)
@@ -290,6 +316,37 @@ func OneLineOutput(instanceTypeInfoSlice []instancetypes.Details) []string {
 	return []string{strings.Join(instanceTypeNames, ",")}
 }
 
+// unknownAttribute is displayed for instance type attributes which are missing from the instance type's metadata
+const unknownAttribute = "unknown"
+
+func formatOptionalInt64(i *int64) string {
+	if i == nil {
+		return unknownAttribute
+	}
+	return strconv.FormatInt(*i, 10)
+}
+
+func formatOptionalMiBAsGiB(mib *int64) string {
+	if mib == nil {
+		return unknownAttribute
+	}
+	return formatFloat(float64(*mib) / 1024.0)
+}
+
+func formatOptionalBool(b *bool) string {
+	if b == nil {
+		return unknownAttribute
+	}
+	return strconv.FormatBool(*b)
+}
+
+func formatOptionalString(s *string) string {
+	if s == nil {
+		return unknownAttribute
+	}
+	return *s
+}
+
 func formatFloat(f float64) string {
 	s := strconv.FormatFloat(f, 'f', 5, 64)
 	parts := strings.Split(s, ".")
@@ -77,6 +77,23 @@ func TestVerboseInstanceTypeOutput(t *testing.T) {
 	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 instance types when passed nil")
 }
 
+func TestNDJSONOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "25_instances.json")
+	instanceTypeOut := outputs.NDJSONOutput(instanceTypes)
+	h.Assert(t, len(instanceTypeOut) == len(instanceTypes), "Should return one line per instance type")
+	for i, line := range instanceTypeOut {
+		h.Assert(t, !strings.Contains(line, "\n"), "Each instance type should be output on a single line")
+		details := instancetypes.Details{}
+		h.Ok(t, json.Unmarshal([]byte(line), &details))
+		h.Equals(t, *instanceTypes[i].InstanceType, *details.InstanceType)
+		h.Equals(t, *instanceTypes[i].OndemandPricePerHour, *details.OndemandPricePerHour)
+		h.Equals(t, *instanceTypes[i].VCpuInfo.DefaultVCpus, *details.VCpuInfo.DefaultVCpus)
+	}
+
+	instanceTypeOut = outputs.NDJSONOutput([]instancetypes.Details{})
+	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 lines when passed empty slice")
+}
+
 func TestTerraformSpotMixedInstancesPolicyHCLOutput(t *testing.T) {
 	instanceTypes := getInstanceTypes(t, "t3_micro.json")
 	instanceTypeOut := outputs.TerraformSpotMixedInstancesPolicyHCLOutput(instanceTypes)
@@ -124,6 +141,24 @@ func TestTableOutputWide(t *testing.T) {
 	h.Assert(t, strings.Contains(outputStr, "NVIDIA K520"), "wide table should include GPU Info")
 }
 
+func TestTableOutputWide_MissingMetadata(t *testing.T) {
+	name := "x9.large"
+	instanceTypes := []instancetypes.Details{{
+		InstanceTypeInfo: ec2.InstanceTypeInfo{
+			InstanceType:  &name,
+			ProcessorInfo: &ec2.ProcessorInfo{},
+			VCpuInfo:      &ec2.VCpuInfo{},
+			MemoryInfo:    &ec2.MemoryInfo{},
+			NetworkInfo:   &ec2.NetworkInfo{},
+		},
+	}}
+	outputStr := strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, strings.Contains(outputStr, "x9.large"), "table should include instance type")
+	h.Assert(t, strings.Contains(outputStr, "unknown"), "wide table should report missing attributes as unknown")
+	outputStr = strings.Join(outputs.TableOutputShort(instanceTypes), "")
+	h.Assert(t, strings.Contains(outputStr, "unknown"), "short table should report missing attributes as unknown")
+}
+
 func TestTableOutput_MBtoGB(t *testing.T) {
 	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
 	instanceTypeOut := outputs.TableOutputWide(instanceTypes)
@@ -148,3 +183,38 @@ func TestOneLineOutput(t *testing.T) {
 	instanceTypeOut = outputs.OneLineOutput(nil)
 	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 instance types when passed nil")
 }
+
+func TestPriceTableOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	instanceTypeOut := outputs.PriceTableOutput(instanceTypes)
+	outputStr := strings.Join(instanceTypeOut, "")
+	lines := strings.Split(outputStr, "\n")
+	h.Assert(t, len(lines) == 4, "table should include 2 header lines and 2 instance type result lines")
+	h.Assert(t, strings.Contains(outputStr, "$0.53"), "price table should include the on-demand price")
+	h.Assert(t, strings.Contains(outputStr, "-Not Fetched-"), "price table should mark the spot price as not fetched")
+}
+
+func TestPriceJSONOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro.json")
+	instanceTypeOut := outputs.PriceJSONOutput(instanceTypes)
+	prices := []map[string]interface{}{}
+	h.Ok(t, json.Unmarshal([]byte(strings.Join(instanceTypeOut, "")), &prices))
+	h.Equals(t, 1, len(prices))
+	h.Equals(t, "t3.micro", prices[0]["InstanceType"])
+	h.Equals(t, 0.53, prices[0]["OndemandPricePerHour"])
+	h.Assert(t, prices[0]["SpotPricePerHour"] == nil, "spot price should be null when not fetched")
+}
+
+func TestPriceCSVOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro.json")
+	instanceTypeOut := outputs.PriceCSVOutput(instanceTypes)
+	h.Equals(t, []string{"instance_type,ondemand_price_per_hour,spot_price_per_hour\nt3.micro,0.53,"}, instanceTypeOut)
+}
+
+func TestTableOutputWide_FamilyLaunchDate(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	instanceTypeOut := outputs.TableOutputWide(instanceTypes)
+	outputStr := strings.Join(instanceTypeOut, "")
+	h.Assert(t, strings.Contains(outputStr, "Family Launch Date"), "wide table should include the family launch date header")
+	h.Assert(t, strings.Contains(outputStr, "2013-11-05"), "wide table should include the g2 family launch date")
+}
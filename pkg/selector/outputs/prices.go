@@ -0,0 +1,119 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package outputs
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/instancetypes"
+)
+
+const notFetched = "-Not Fetched-"
+
+// instanceTypePrice is a struct to represent json for the prices of an instance type
+type instanceTypePrice struct {
+	InstanceType         string   `json:"InstanceType"`
+	OndemandPricePerHour *float64 `json:"OndemandPricePerHour"`
+	SpotPricePerHour     *float64 `json:"SpotPricePerHour"`
+}
+
+// PriceTableOutput is an output function which prints a table of the on-demand and spot prices of the instance types
+func PriceTableOutput(instanceTypeInfoSlice []instancetypes.Details) []string {
+	if len(instanceTypeInfoSlice) == 0 {
+		return nil
+	}
+	w := new(tabwriter.Writer)
+	buf := new(bytes.Buffer)
+	w.Init(buf, 8, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	headers := []interface{}{
+		"Instance Type",
+		"On-Demand Price/Hr",
+		"Spot Price/Hr",
+	}
+	separators := []interface{}{}
+
+	headerFormat := ""
+	for _, header := range headers {
+		headerFormat = headerFormat + "%s\t"
+		separators = append(separators, strings.Repeat("-", len(header.(string))))
+	}
+	fmt.Fprintf(w, headerFormat, headers...)
+	fmt.Fprintf(w, "\n"+headerFormat, separators...)
+
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		onDemandPricePerHourStr := notFetched
+		spotPricePerHourStr := notFetched
+		if instanceTypeInfo.OndemandPricePerHour != nil {
+			onDemandPricePerHourStr = fmt.Sprintf("$%s", formatFloat(*instanceTypeInfo.OndemandPricePerHour))
+		}
+		if instanceTypeInfo.SpotPrice != nil {
+			spotPricePerHourStr = fmt.Sprintf("$%s", formatFloat(*instanceTypeInfo.SpotPrice))
+		}
+		fmt.Fprintf(w, "\n%s\t%s\t%s\t", *instanceTypeInfo.InstanceType, onDemandPricePerHourStr, spotPricePerHourStr)
+	}
+	w.Flush()
+	return []string{buf.String()}
+}
+
+// PriceJSONOutput is an output function which prints the on-demand and spot prices of the instance types as a JSON list
+// Prices which could not be retrieved are null
+func PriceJSONOutput(instanceTypeInfoSlice []instancetypes.Details) []string {
+	prices := []instanceTypePrice{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		prices = append(prices, instanceTypePrice{
+			InstanceType:         *instanceTypeInfo.InstanceType,
+			OndemandPricePerHour: instanceTypeInfo.OndemandPricePerHour,
+			SpotPricePerHour:     instanceTypeInfo.SpotPrice,
+		})
+	}
+	output, err := json.MarshalIndent(prices, "", "    ")
+	if err != nil {
+		log.Println("Unable to convert instance type prices to JSON")
+		return []string{}
+	}
+	return []string{string(output)}
+}
+
+// PriceCSVOutput is an output function which prints the on-demand and spot prices of the instance types in CSV format
+// Prices which could not be retrieved are left empty
+func PriceCSVOutput(instanceTypeInfoSlice []instancetypes.Details) []string {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	records := [][]string{{"instance_type", "ondemand_price_per_hour", "spot_price_per_hour"}}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		onDemandPricePerHourStr := ""
+		spotPricePerHourStr := ""
+		if instanceTypeInfo.OndemandPricePerHour != nil {
+			onDemandPricePerHourStr = strconv.FormatFloat(*instanceTypeInfo.OndemandPricePerHour, 'f', -1, 64)
+		}
+		if instanceTypeInfo.SpotPrice != nil {
+			spotPricePerHourStr = strconv.FormatFloat(*instanceTypeInfo.SpotPrice, 'f', -1, 64)
+		}
+		records = append(records, []string{*instanceTypeInfo.InstanceType, onDemandPricePerHourStr, spotPricePerHourStr})
+	}
+	if err := w.WriteAll(records); err != nil {
+		log.Println("Unable to convert instance type prices to CSV")
+		return []string{}
+	}
+	return []string{strings.TrimSuffix(buf.String(), "\n")}
+}
@@ -49,9 +49,13 @@ const (
 	rootDeviceType         = "rootDeviceType"
 	hibernationSupported   = "hibernationSupported"
 	vcpusRange             = "vcpusRange"
+	threadsRange           = "threadsRange"
+	coresRange             = "coresRange"
 	memoryRange            = "memoryRange"
 	gpuMemoryRange         = "gpuMemoryRange"
 	gpusRange              = "gpusRange"
+	gpuTier                = "gpuTier"
+	vcpusPerGpu            = "vcpusPerGpu"
 	placementGroupStrategy = "placementGroupStrategy"
 	hypervisor             = "hypervisor"
 	baremetal              = "baremetal"
@@ -59,10 +63,19 @@ const (
 	fpga                   = "fpga"
 	enaSupport             = "enaSupport"
 	efaSupport             = "efaSupport"
+	acceleratedNetworking  = "acceleratedNetworking"
 	vcpusToMemoryRatio     = "vcpusToMemoryRatio"
 	currentGeneration      = "currentGeneration"
 	networkInterfaces      = "networkInterfaces"
 	networkPerformance     = "networkPerformance"
+	networkPerVCpu         = "networkPerVCpu"
+	aggregateNetwork       = "aggregateNetwork"
+	ebsVolumeAttachments   = "ebsVolumeAttachments"
+	ebsBaselineThroughput  = "ebsBaselineThroughput"
+	storeThroughput        = "storeThroughput"
+	familyAge              = "familyAge"
+	spotSavingsPercent     = "spotSavingsPercent"
+	priceAvailable         = "priceAvailable"
 	allowList              = "allowList"
 	denyList               = "denyList"
 	instanceTypes          = "instanceTypes"
@@ -79,14 +92,23 @@ const (
 
 // New creates an instance of Selector provided an aws session
 func New(sess *session.Session) *Selector {
+	return NewWithSessions(sess, sess)
+}
+
+// NewWithSessions creates an instance of Selector which queries instance type availability with the ec2Session and
+// on-demand prices with the pricingSession, so that pricing can be centralized in a different account
+func NewWithSessions(ec2Session *session.Session, pricingSession *session.Session) *Selector {
 	serviceRegistry := NewRegistry()
 	serviceRegistry.RegisterAWSServices()
 	userAgentTag := fmt.Sprintf("%s-%s", sdkName, versionID)
 	userAgentHandler := request.MakeAddToUserAgentFreeFormHandler(userAgentTag)
-	sess.Handlers.Build.PushBack(userAgentHandler)
+	ec2Session.Handlers.Build.PushBack(userAgentHandler)
+	if pricingSession != ec2Session {
+		pricingSession.Handlers.Build.PushBack(userAgentHandler)
+	}
 	return &Selector{
-		EC2:             ec2.New(sess),
-		EC2Pricing:      ec2pricing.New(sess),
+		EC2:             ec2.New(ec2Session),
+		EC2Pricing:      ec2pricing.NewWithSessions(ec2Session, pricingSession),
 		ServiceRegistry: serviceRegistry,
 	}
 }
@@ -153,18 +175,39 @@ func (itf Selector) AggregateFilterTransform(filters Filters) (Filters, error) {
 // rawFilter accepts a Filters struct which is used to select the available instance types
 // matching the criteria within Filters and returns the detailed specs of matching instance types
 func (itf Selector) rawFilter(filters Filters) ([]instancetypes.Details, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
 	filters, err := itf.AggregateFilterTransform(filters)
 	if err != nil {
 		return nil, err
 	}
+	priceEnrichment, cancel := newPriceEnrichmentTracker(filters.PriceEnrichmentBudget)
+	defer cancel()
+	if filters.PreviousGenerationFallback != nil && *filters.PreviousGenerationFallback && filters.CurrentGeneration == nil {
+		currentGenerationFilters := filters
+		currentGenerationFilters.CurrentGeneration = aws.Bool(true)
+		instanceTypeInfoSlice, err := itf.filterInstanceTypes(currentGenerationFilters, priceEnrichment)
+		if err != nil || len(instanceTypeInfoSlice) != 0 {
+			return instanceTypeInfoSlice, err
+		}
+		instanceTypeInfoSlice, err = itf.filterInstanceTypes(filters, priceEnrichment)
+		for i := range instanceTypeInfoSlice {
+			instanceTypeInfoSlice[i].PreviousGenerationFallback = true
+		}
+		return instanceTypeInfoSlice, err
+	}
+	return itf.filterInstanceTypes(filters, priceEnrichment)
+}
+
+// filterInstanceTypes accepts a transformed Filters struct and executes the filters against
+// the instance types returned from DescribeInstanceTypes
+// Prices are only looked up for instance types which match the attribute filters, and the price filters are not applied to
+// instance types whose price enrichment was skipped so that they are returned with PriceEnrichmentSkipped set
+func (itf Selector) filterInstanceTypes(filters Filters, priceEnrichment *priceEnrichmentTracker) ([]instancetypes.Details, error) {
 	var locations, availabilityZones []string
 
-	if filters.CPUArchitecture != nil && *filters.CPUArchitecture == cpuArchitectureAMD64 {
-		*filters.CPUArchitecture = cpuArchitectureX8664
-	}
-	if filters.VirtualizationType != nil && *filters.VirtualizationType == virtualizationTypePV {
-		*filters.VirtualizationType = virtualizationTypeParaVirtual
-	}
+	normalizeFilterAliases(filters)
 	if filters.AvailabilityZones != nil {
 		availabilityZones = *filters.AvailabilityZones
 		locations = *filters.AvailabilityZones
@@ -178,89 +221,46 @@ func (itf Selector) rawFilter(filters Filters) ([]instancetypes.Details, error)
 
 	instanceTypesInput := &ec2.DescribeInstanceTypesInput{}
 	instanceTypeCandidates := map[string]*instancetypes.Details{}
+	describedInstanceTypes := map[string]bool{}
 	// innerErr will hold any error while processing DescribeInstanceTypes pages
 	var innerErr error
 
 	err = itf.EC2.DescribeInstanceTypesPages(instanceTypesInput, func(page *ec2.DescribeInstanceTypesOutput, lastPage bool) bool {
 		for _, instanceTypeInfo := range page.InstanceTypes {
 			instanceTypeName := *instanceTypeInfo.InstanceType
-			instanceTypeCandidates[instanceTypeName] = &instancetypes.Details{InstanceTypeInfo: *instanceTypeInfo}
-			isFpga := instanceTypeInfo.FpgaInfo != nil
-			var instanceTypeHourlyPriceForFilter float64 // Price used to filter based on usage class
-			var instanceTypeHourlyPriceOnDemand, instanceTypeHourlyPriceSpot *float64
-			// If prices are fetched, populate the fields irrespective of the price filters
-			if itf.EC2Pricing.LastOnDemandCacheUTC() != nil {
-				price, err := itf.EC2Pricing.GetOndemandInstanceTypeCost(instanceTypeName)
-				if err != nil {
-					fmt.Printf("Could not retrieve instantaneous hourly on-demand price for instance type %s\n", instanceTypeName)
-				} else {
-					instanceTypeHourlyPriceOnDemand = &price
-					instanceTypeCandidates[instanceTypeName].OndemandPricePerHour = instanceTypeHourlyPriceOnDemand
-				}
-			}
-			if itf.EC2Pricing.LastSpotCacheUTC() != nil {
-				price, err := itf.EC2Pricing.GetSpotInstanceTypeNDayAvgCost(instanceTypeName, availabilityZones, 30)
-				if err != nil {
-					fmt.Printf("Could not retrieve 30 day avg hourly spot price for instance type %s\n", instanceTypeName)
-				} else {
-					instanceTypeHourlyPriceSpot = &price
-					instanceTypeCandidates[instanceTypeName].SpotPrice = instanceTypeHourlyPriceSpot
-				}
-			}
-			if filters.PricePerHour != nil {
-				// If price filter is present, prices should be already fetched
-				// If prices are not fetched, filter should fail and the corresponding error is already printed
-				if filters.UsageClass != nil && *filters.UsageClass == "spot" && instanceTypeHourlyPriceSpot != nil {
-					instanceTypeHourlyPriceForFilter = *instanceTypeHourlyPriceSpot
-				} else if instanceTypeHourlyPriceOnDemand != nil {
-					instanceTypeHourlyPriceForFilter = *instanceTypeHourlyPriceOnDemand
-				}
-			}
-
-			// filterToInstanceSpecMappingPairs is a map of filter name [key] to filter pair [value].
-			// A filter pair includes user input filter value and instance spec value retrieved from DescribeInstanceTypes
-			filterToInstanceSpecMappingPairs := map[string]filterPair{
-				cpuArchitecture:        {filters.CPUArchitecture, instanceTypeInfo.ProcessorInfo.SupportedArchitectures},
-				usageClass:             {filters.UsageClass, instanceTypeInfo.SupportedUsageClasses},
-				rootDeviceType:         {filters.RootDeviceType, instanceTypeInfo.SupportedRootDeviceTypes},
-				hibernationSupported:   {filters.HibernationSupported, instanceTypeInfo.HibernationSupported},
-				vcpusRange:             {filters.VCpusRange, instanceTypeInfo.VCpuInfo.DefaultVCpus},
-				memoryRange:            {filters.MemoryRange, instanceTypeInfo.MemoryInfo.SizeInMiB},
-				gpuMemoryRange:         {filters.GpuMemoryRange, getTotalGpuMemory(instanceTypeInfo.GpuInfo)},
-				gpusRange:              {filters.GpusRange, getTotalGpusCount(instanceTypeInfo.GpuInfo)},
-				placementGroupStrategy: {filters.PlacementGroupStrategy, instanceTypeInfo.PlacementGroupInfo.SupportedStrategies},
-				hypervisor:             {filters.Hypervisor, instanceTypeInfo.Hypervisor},
-				baremetal:              {filters.BareMetal, instanceTypeInfo.BareMetal},
-				burstable:              {filters.Burstable, instanceTypeInfo.BurstablePerformanceSupported},
-				fpga:                   {filters.Fpga, &isFpga},
-				enaSupport:             {filters.EnaSupport, supportSyntaxToBool(instanceTypeInfo.NetworkInfo.EnaSupport)},
-				efaSupport:             {filters.EfaSupport, instanceTypeInfo.NetworkInfo.EfaSupported},
-				vcpusToMemoryRatio:     {filters.VCpusToMemoryRatio, calculateVCpusToMemoryRatio(instanceTypeInfo.VCpuInfo.DefaultVCpus, instanceTypeInfo.MemoryInfo.SizeInMiB)},
-				currentGeneration:      {filters.CurrentGeneration, instanceTypeInfo.CurrentGeneration},
-				networkInterfaces:      {filters.NetworkInterfaces, instanceTypeInfo.NetworkInfo.MaximumNetworkInterfaces},
-				networkPerformance:     {filters.NetworkPerformance, getNetworkPerformance(instanceTypeInfo.NetworkInfo.NetworkPerformance)},
-				instanceTypes:          {filters.InstanceTypes, instanceTypeInfo.InstanceType},
-				virtualizationType:     {filters.VirtualizationType, instanceTypeInfo.SupportedVirtualizationTypes},
-				pricePerHour:           {filters.PricePerHour, &instanceTypeHourlyPriceForFilter},
-			}
-
+			describedInstanceTypes[instanceTypeName] = true
+			logMissingMetadata(instanceTypeInfo)
 			if isInDenyList(filters.DenyList, instanceTypeName) || !isInAllowList(filters.AllowList, instanceTypeName) {
-				delete(instanceTypeCandidates, instanceTypeName)
+				continue
 			}
-
 			if !isSupportedInLocation(locationInstanceOfferings, instanceTypeName) {
-				delete(instanceTypeCandidates, instanceTypeName)
+				continue
 			}
 
 			var isInstanceSupported bool
-			isInstanceSupported, innerErr = itf.executeFilters(filterToInstanceSpecMappingPairs, instanceTypeName)
+			attributeFilterPairs, _ := splitPriceFilterPairs(filterPairs(filters, instanceTypeInfo, nil, nil))
+			isInstanceSupported, innerErr = itf.executeFilters(attributeFilterPairs, instanceTypeName)
 			if innerErr != nil {
 				// stops paging through instance types
 				return false
 			}
 			if !isInstanceSupported {
-				delete(instanceTypeCandidates, instanceTypeName)
+				continue
 			}
+
+			details := &instancetypes.Details{InstanceTypeInfo: *instanceTypeInfo}
+			itf.enrichPrices(priceEnrichment, details, availabilityZones)
+			if !details.PriceEnrichmentSkipped {
+				_, priceFilterPairs := splitPriceFilterPairs(filterPairs(filters, instanceTypeInfo, details.OndemandPricePerHour, details.SpotPrice))
+				isInstanceSupported, innerErr = itf.executeFilters(priceFilterPairs, instanceTypeName)
+				if innerErr != nil {
+					return false
+				}
+				if !isInstanceSupported {
+					continue
+				}
+			}
+			instanceTypeCandidates[instanceTypeName] = details
 		}
 		// continue paging through instance types
 		return true
@@ -276,15 +276,102 @@ func (itf Selector) rawFilter(filters Filters) ([]instancetypes.Details, error)
 	for _, instanceTypeInfo := range instanceTypeCandidates {
 		instanceTypeInfoSlice = append(instanceTypeInfoSlice, *instanceTypeInfo)
 	}
+	instanceTypeInfoSlice = append(instanceTypeInfoSlice, itf.catalogOnlyInstanceTypes(filters, describedInstanceTypes, availabilityZones, priceEnrichment)...)
 	return sortInstanceTypeInfo(instanceTypeInfoSlice), nil
 }
 
+// normalizeFilterAliases replaces aliased filter values with the values returned from DescribeInstanceTypes
+func normalizeFilterAliases(filters Filters) {
+	if filters.CPUArchitecture != nil && *filters.CPUArchitecture == cpuArchitectureAMD64 {
+		*filters.CPUArchitecture = cpuArchitectureX8664
+	}
+	if filters.VirtualizationType != nil && *filters.VirtualizationType == virtualizationTypePV {
+		*filters.VirtualizationType = virtualizationTypeParaVirtual
+	}
+}
+
+// filterPairs maps each filter name to the filter value and the instance type's corresponding spec
+// The on-demand and spot prices are nil when they were not retrieved
+func filterPairs(filters Filters, instanceTypeInfo *ec2.InstanceTypeInfo, instanceTypeHourlyPriceOnDemand *float64, instanceTypeHourlyPriceSpot *float64) map[string]filterPair {
+	isFpga := instanceTypeInfo.FpgaInfo != nil
+	var instanceTypeHourlyPriceForFilter *float64 // Price used to filter based on usage class
+	if filters.PricePerHour != nil {
+		// If price filter is present, prices should be already fetched
+		// If prices are not fetched, filter should fail and the corresponding error is already printed
+		if filters.UsageClass != nil && *filters.UsageClass == "spot" && instanceTypeHourlyPriceSpot != nil {
+			instanceTypeHourlyPriceForFilter = instanceTypeHourlyPriceSpot
+		} else if instanceTypeHourlyPriceOnDemand != nil {
+			instanceTypeHourlyPriceForFilter = instanceTypeHourlyPriceOnDemand
+		}
+	}
+
+	// A filter pair includes user input filter value and instance spec value retrieved from DescribeInstanceTypes
+	return map[string]filterPair{
+		cpuArchitecture:        {filters.CPUArchitecture, instanceTypeInfo.ProcessorInfo.SupportedArchitectures},
+		usageClass:             {filters.UsageClass, instanceTypeInfo.SupportedUsageClasses},
+		rootDeviceType:         {filters.RootDeviceType, instanceTypeInfo.SupportedRootDeviceTypes},
+		hibernationSupported:   {filters.HibernationSupported, instanceTypeInfo.HibernationSupported},
+		vcpusRange:             {filters.VCpusRange, instanceTypeInfo.VCpuInfo.DefaultVCpus},
+		threadsRange:           {filters.ThreadsRange, instanceTypeInfo.VCpuInfo.DefaultVCpus},
+		coresRange:             {filters.CoresRange, instanceTypeInfo.VCpuInfo.DefaultCores},
+		memoryRange:            {memoryRangeWithTolerance(filters.MemoryRange, filters.MemoryTolerancePercent), instanceTypeInfo.MemoryInfo.SizeInMiB},
+		gpuMemoryRange:         {filters.GpuMemoryRange, getTotalGpuMemory(instanceTypeInfo.GpuInfo)},
+		gpusRange:              {filters.GpusRange, getTotalGpusCount(instanceTypeInfo.GpuInfo)},
+		gpuTier:                {minGpuTierRange(filters.MinGpuTier), getGpuTierRank(instanceTypeInfo.GpuInfo)},
+		vcpusPerGpu:            {minVCpusPerGpuRange(filters.MinVCpusPerGpu), calculateVCpusPerGpu(instanceTypeInfo.VCpuInfo.DefaultVCpus, instanceTypeInfo.GpuInfo)},
+		placementGroupStrategy: {filters.PlacementGroupStrategy, instanceTypeInfo.PlacementGroupInfo.SupportedStrategies},
+		hypervisor:             {filters.Hypervisor, instanceTypeInfo.Hypervisor},
+		baremetal:              {filters.BareMetal, instanceTypeInfo.BareMetal},
+		burstable:              {filters.Burstable, instanceTypeInfo.BurstablePerformanceSupported},
+		fpga:                   {filters.Fpga, &isFpga},
+		enaSupport:             {filters.EnaSupport, supportSyntaxToBool(instanceTypeInfo.NetworkInfo.EnaSupport)},
+		efaSupport:             {filters.EfaSupport, instanceTypeInfo.NetworkInfo.EfaSupported},
+		acceleratedNetworking:  {filters.AcceleratedNetworking, hasAcceleratedNetworking(instanceTypeInfo)},
+		vcpusToMemoryRatio:     {filters.VCpusToMemoryRatio, calculateVCpusToMemoryRatio(instanceTypeInfo.VCpuInfo.DefaultVCpus, instanceTypeInfo.MemoryInfo.SizeInMiB)},
+		currentGeneration:      {filters.CurrentGeneration, instanceTypeInfo.CurrentGeneration},
+		networkInterfaces:      {filters.NetworkInterfaces, instanceTypeInfo.NetworkInfo.MaximumNetworkInterfaces},
+		networkPerformance:     {filters.NetworkPerformance, getNetworkPerformance(instanceTypeInfo.NetworkInfo.NetworkPerformance)},
+		networkPerVCpu:         {minNetworkPerVCpuRange(filters.MinNetworkPerVCpu), calculateNetworkPerVCpu(instanceTypeInfo.VCpuInfo.DefaultVCpus, instanceTypeInfo.NetworkInfo.NetworkPerformance)},
+		aggregateNetwork:       {minAggregateNetworkRange(filters.MinAggregateNetworkGbps), getAggregateNetworkBandwidthGbps(instanceTypeInfo.NetworkInfo)},
+		ebsVolumeAttachments:   {filters.EBSVolumeAttachments, getMaxEBSVolumeAttachments(instanceTypeInfo)},
+		ebsBaselineThroughput:  {minEBSBaselineThroughputRange(filters.MinEBSThroughputForRestore), getEBSBaselineThroughput(instanceTypeInfo.EbsInfo)},
+		storeThroughput:        {minInstanceStoreThroughputRange(filters.MinInstanceStoreThroughput), getInstanceStoreReadThroughput(instanceTypeInfo)},
+		familyAge:              {filters.FamilyAge, getFamilyAgeDays(instanceTypeInfo.InstanceType)},
+		instanceTypes:          {filters.InstanceTypes, instanceTypeInfo.InstanceType},
+		virtualizationType:     {filters.VirtualizationType, instanceTypeInfo.SupportedVirtualizationTypes},
+		pricePerHour:           {filters.PricePerHour, instanceTypeHourlyPriceForFilter},
+		spotSavingsPercent:     {minSpotSavingsPercentRange(filters.MinSpotSavingsPercent), getSpotSavingsPercent(instanceTypeHourlyPriceOnDemand, instanceTypeHourlyPriceSpot)},
+		priceAvailable:         {requirePriceAvailable(filters.RequirePriceAvailable), isPriceAvailable(instanceTypeHourlyPriceOnDemand)},
+	}
+}
+
+// priceFilters are the filters which are evaluated against the prices of an instance type
+var priceFilters = map[string]bool{
+	pricePerHour:       true,
+	spotSavingsPercent: true,
+	priceAvailable:     true,
+}
+
+// splitPriceFilterPairs splits the filter pairs into the attribute filter pairs and the price filter pairs
+func splitPriceFilterPairs(pairs map[string]filterPair) (map[string]filterPair, map[string]filterPair) {
+	attributeFilterPairs := map[string]filterPair{}
+	priceFilterPairs := map[string]filterPair{}
+	for filterName, pair := range pairs {
+		if priceFilters[filterName] {
+			priceFilterPairs[filterName] = pair
+		} else {
+			attributeFilterPairs[filterName] = pair
+		}
+	}
+	return attributeFilterPairs, priceFilterPairs
+}
+
 // sortInstanceTypeInfo will sort based on instance type info alpha-numerically
 func sortInstanceTypeInfo(instanceTypeInfoSlice []instancetypes.Details) []instancetypes.Details {
 	sort.Slice(instanceTypeInfoSlice, func(i, j int) bool {
 		iInstanceInfo := instanceTypeInfoSlice[i]
 		jInstanceInfo := instanceTypeInfoSlice[j]
-		return strings.Compare(*iInstanceInfo.InstanceType, *jInstanceInfo.InstanceType) <= 0
+		return strings.Compare(*iInstanceInfo.InstanceType, *jInstanceInfo.InstanceType) < 0
 	})
 	return instanceTypeInfoSlice
 }
@@ -293,117 +380,127 @@ func sortInstanceTypeInfo(instanceTypeInfoSlice []instancetypes.Details) []insta
 // to determine if the instance type matches the filter values.
 func (itf Selector) executeFilters(filterToInstanceSpecMapping map[string]filterPair, instanceType string) (bool, error) {
 	for filterName, filterPair := range filterToInstanceSpecMapping {
-		filterVal := filterPair.filterValue
-		instanceSpec := filterPair.instanceSpec
-		// if filter is nil, user did not specify a filter, so skip evaluation
-		if reflect.ValueOf(filterVal).IsNil() {
-			continue
+		isSupported, err := executeFilter(filterName, filterPair, instanceType)
+		if err != nil || !isSupported {
+			return false, err
 		}
-		instanceSpecType := reflect.ValueOf(instanceSpec).Type()
-		filterType := reflect.ValueOf(filterVal).Type()
-		filterDetailsMsg := fmt.Sprintf("filter (%s: %s => %s) corresponding to instance spec (%s => %s) for instance type %s", filterName, filterVal, filterType, instanceSpec, instanceSpecType, instanceType)
-		invalidInstanceSpecTypeMsg := fmt.Sprintf("Unable to process for %s", filterDetailsMsg)
+	}
+	return true, nil
+}
 
-		// Determine appropriate filter comparator by switching on filter type
-		switch filter := filterVal.(type) {
+// executeFilter determines if the instance type matches a single filter pair
+// Filters which the user did not specify always match
+func executeFilter(filterName string, filterPair filterPair, instanceType string) (bool, error) {
+	filterVal := filterPair.filterValue
+	instanceSpec := filterPair.instanceSpec
+	// if filter is nil, user did not specify a filter, so skip evaluation
+	if reflect.ValueOf(filterVal).IsNil() {
+		return true, nil
+	}
+	instanceSpecType := reflect.ValueOf(instanceSpec).Type()
+	filterType := reflect.ValueOf(filterVal).Type()
+	filterDetailsMsg := fmt.Sprintf("filter (%s: %s => %s) corresponding to instance spec (%s => %s) for instance type %s", filterName, filterVal, filterType, instanceSpec, instanceSpecType, instanceType)
+	invalidInstanceSpecTypeMsg := fmt.Sprintf("Unable to process for %s", filterDetailsMsg)
+
+	// Determine appropriate filter comparator by switching on filter type
+	switch filter := filterVal.(type) {
+	case *string:
+		switch iSpec := instanceSpec.(type) {
+		case []*string:
+			if !isSupportedFromStrings(iSpec, filter) {
+				return false, nil
+			}
 		case *string:
-			switch iSpec := instanceSpec.(type) {
-			case []*string:
-				if !isSupportedFromStrings(iSpec, filter) {
-					return false, nil
-				}
-			case *string:
-				if !isSupportedFromString(iSpec, filter) {
-					return false, nil
-				}
-			default:
-				return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+			if !isSupportedFromString(iSpec, filter) {
+				return false, nil
 			}
+		default:
+			return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+		}
+	case *bool:
+		switch iSpec := instanceSpec.(type) {
 		case *bool:
-			switch iSpec := instanceSpec.(type) {
-			case *bool:
-				if !isSupportedWithBool(iSpec, filter) {
-					return false, nil
-				}
-			default:
-				return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+			if !isSupportedWithBool(iSpec, filter) {
+				return false, nil
 			}
-		case *IntRangeFilter:
-			switch iSpec := instanceSpec.(type) {
-			case *int64:
-				if !isSupportedWithRangeInt64(iSpec, filter) {
-					return false, nil
-				}
-			case *int:
-				if !isSupportedWithRangeInt(iSpec, filter) {
-					return false, nil
-				}
-			default:
-				return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+		default:
+			return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+		}
+	case *IntRangeFilter:
+		switch iSpec := instanceSpec.(type) {
+		case *int64:
+			if !isSupportedWithRangeInt64(iSpec, filter) {
+				return false, nil
 			}
-		case *Float64RangeFilter:
-			switch iSpec := instanceSpec.(type) {
-			case *float64:
-				if !isSupportedWithRangeFloat64(iSpec, filter) {
-					return false, nil
-				}
-			default:
-				return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+		case *int:
+			if !isSupportedWithRangeInt(iSpec, filter) {
+				return false, nil
 			}
-		case *ByteQuantityRangeFilter:
+		default:
+			return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+		}
+	case *Float64RangeFilter:
+		switch iSpec := instanceSpec.(type) {
+		case *float64:
+			if !isSupportedWithRangeFloat64(iSpec, filter) {
+				return false, nil
+			}
+		default:
+			return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+		}
+	case *ByteQuantityRangeFilter:
+		mibRange := Uint64RangeFilter{
+			LowerBound: filter.LowerBound.Quantity,
+			UpperBound: filter.UpperBound.Quantity,
+		}
+		switch iSpec := instanceSpec.(type) {
+		case *int:
+			var iSpec64 *int64
+			if iSpec != nil {
+				iSpecVal := int64(*iSpec)
+				iSpec64 = &iSpecVal
+			}
+			if !isSupportedWithRangeUint64(iSpec64, &mibRange) {
+				return false, nil
+			}
+		case *int64:
 			mibRange := Uint64RangeFilter{
 				LowerBound: filter.LowerBound.Quantity,
 				UpperBound: filter.UpperBound.Quantity,
 			}
-			switch iSpec := instanceSpec.(type) {
-			case *int:
-				var iSpec64 *int64
-				if iSpec != nil {
-					iSpecVal := int64(*iSpec)
-					iSpec64 = &iSpecVal
-				}
-				if !isSupportedWithRangeUint64(iSpec64, &mibRange) {
-					return false, nil
-				}
-			case *int64:
-				mibRange := Uint64RangeFilter{
-					LowerBound: filter.LowerBound.Quantity,
-					UpperBound: filter.UpperBound.Quantity,
-				}
-				if !isSupportedWithRangeUint64(iSpec, &mibRange) {
-					return false, nil
-				}
-			default:
-				return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+			if !isSupportedWithRangeUint64(iSpec, &mibRange) {
+				return false, nil
 			}
+		default:
+			return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+		}
+	case *float64:
+		switch iSpec := instanceSpec.(type) {
 		case *float64:
-			switch iSpec := instanceSpec.(type) {
-			case *float64:
-				if !isSupportedWithFloat64(iSpec, filter) {
-					return false, nil
-				}
-			default:
-				return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+			if !isSupportedWithFloat64(iSpec, filter) {
+				return false, nil
 			}
-		case *[]string:
-			switch iSpec := instanceSpec.(type) {
-			case *string:
-				filterOfPtrs := []*string{}
-				for _, f := range *filter {
-					// this allows us to copy a static pointer to f into filterOfPtrs
-					// since the pointer to f is updated on each loop iteration
-					temp := f
-					filterOfPtrs = append(filterOfPtrs, &temp)
-				}
-				if !isSupportedFromStrings(filterOfPtrs, iSpec) {
-					return false, nil
-				}
-			default:
-				return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+		default:
+			return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
+		}
+	case *[]string:
+		switch iSpec := instanceSpec.(type) {
+		case *string:
+			filterOfPtrs := []*string{}
+			for _, f := range *filter {
+				// this allows us to copy a static pointer to f into filterOfPtrs
+				// since the pointer to f is updated on each loop iteration
+				temp := f
+				filterOfPtrs = append(filterOfPtrs, &temp)
+			}
+			if !isSupportedFromStrings(filterOfPtrs, iSpec) {
+				return false, nil
 			}
 		default:
-			return false, fmt.Errorf("No filter handler found for %s", filterDetailsMsg)
+			return false, fmt.Errorf(invalidInstanceSpecTypeMsg)
 		}
+	default:
+		return false, fmt.Errorf("No filter handler found for %s", filterDetailsMsg)
 	}
 	return true, nil
 }
@@ -14,10 +14,12 @@
 package selector_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"regexp"
 	"strconv"
 	"testing"
@@ -63,6 +65,18 @@ func (m mockedEC2) DescribeAvailabilityZones(input *ec2.DescribeAvailabilityZone
 }
 
 func (m mockedEC2) DescribeInstanceTypes(input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+	if len(m.DescribeInstanceTypesResp.InstanceTypes) == 0 && len(input.InstanceTypes) != 0 {
+		// look the requested instance types up in the paginated response when no single response is mocked
+		output := &ec2.DescribeInstanceTypesOutput{}
+		for _, instanceTypeInfo := range m.DescribeInstanceTypesPagesResp.InstanceTypes {
+			for _, instanceType := range input.InstanceTypes {
+				if *instanceTypeInfo.InstanceType == *instanceType {
+					output.InstanceTypes = append(output.InstanceTypes, instanceTypeInfo)
+				}
+			}
+		}
+		return output, m.DescribeInstanceTypesErr
+	}
 	return &m.DescribeInstanceTypesResp, m.DescribeInstanceTypesErr
 }
 
@@ -146,6 +160,14 @@ func TestNew(t *testing.T) {
 	h.Assert(t, itf != nil, "selector instance created without error")
 }
 
+func TestNewWithSessions(t *testing.T) {
+	ec2Session := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-west-2")}))
+	pricingSession := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	itf := selector.NewWithSessions(ec2Session, pricingSession)
+	h.Assert(t, itf != nil, "selector instance created without error")
+	h.Assert(t, itf.EC2Pricing != nil, "selector pricing created without error")
+}
+
 func TestFilterVerbose(t *testing.T) {
 	ec2Mock := setupMock(t, describeInstanceTypesPages, "t3_micro.json")
 	itf := selector.Selector{
@@ -728,3 +750,583 @@ func TestFilter_PricePerHour_Spot(t *testing.T) {
 	h.Ok(t, err)
 	h.Assert(t, len(results) == 1, fmt.Sprintf("Should return 1 instance type; got %d", len(results)))
 }
+
+func TestFilter_PreviousGenerationFallback(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypesPages, "25_instances.json")
+	itf := selector.Selector{
+		EC2:        ec2Mock,
+		EC2Pricing: &ec2PricingMock{},
+	}
+	filters := selector.Filters{
+		VCpusRange:                 &selector.IntRangeFilter{LowerBound: 8, UpperBound: 8},
+		PreviousGenerationFallback: aws.Bool(true),
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.2xlarge", "c4.2xlarge", "c5.2xlarge"}, results)
+	details, err := itf.FilterVerbose(filters)
+	h.Ok(t, err)
+	for _, result := range details {
+		h.Assert(t, !result.PreviousGenerationFallback, "Expected %s to be a current generation match", *result.InstanceType)
+	}
+
+	filters.VCpusRange = &selector.IntRangeFilter{LowerBound: 32, UpperBound: 32}
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"c3.8xlarge"}, results)
+	details, err = itf.FilterVerbose(filters)
+	h.Ok(t, err)
+	h.Equals(t, 1, len(details))
+	h.Assert(t, details[0].PreviousGenerationFallback, "Expected c3.8xlarge to be marked as a previous generation fallback")
+}
+
+func TestFilter_EBSVolumeAttachments(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypesPages, "25_instances.json")
+	itf := selector.Selector{
+		EC2:        ec2Mock,
+		EC2Pricing: &ec2PricingMock{},
+	}
+	filters := selector.Filters{
+		EBSVolumeAttachments: &selector.IntRangeFilter{LowerBound: 28, UpperBound: 31},
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.metal"}, results)
+
+	filters = selector.Filters{
+		EBSVolumeAttachments: &selector.IntRangeFilter{LowerBound: 40, UpperBound: 40},
+		VCpusRange:           &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+	}
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"c1.medium", "c3.large", "c4.large"}, results)
+}
+
+func TestFilter_MinGpuTier(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypesPages, "t3_micro_and_p3_16xl.json")
+	itf := selector.Selector{
+		EC2:        ec2Mock,
+		EC2Pricing: &ec2PricingMock{},
+	}
+	filters := selector.Filters{
+		MinGpuTier: aws.String("volta"),
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"p3.16xlarge"}, results)
+
+	filters = selector.Filters{
+		MinGpuTier: aws.String("ampere"),
+	}
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+}
+
+func TestFilter_MinVCpusPerGpu(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypesPages, "t3_micro_and_p3_16xl.json")
+	itf := selector.Selector{
+		EC2:        ec2Mock,
+		EC2Pricing: &ec2PricingMock{},
+	}
+	// p3.16xlarge has 64 vcpus and 8 GPUs, t3.micro has no GPUs
+	results, err := itf.Filter(selector.Filters{MinVCpusPerGpu: aws.Float64(8)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"p3.16xlarge"}, results)
+
+	results, err = itf.Filter(selector.Filters{MinVCpusPerGpu: aws.Float64(9)})
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+}
+
+func TestFilter_ThreadsAndCores(t *testing.T) {
+	itf := selector.Selector{
+		EC2:        setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: &ec2PricingMock{},
+	}
+	results, err := itf.Filter(selector.Filters{ThreadsRange: &selector.IntRangeFilter{LowerBound: 8, UpperBound: 8}})
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.2xlarge", "c1.xlarge", "c3.2xlarge", "c4.2xlarge", "c5.2xlarge"}, results)
+
+	results, err = itf.Filter(selector.Filters{CoresRange: &selector.IntRangeFilter{LowerBound: 4, UpperBound: 4}})
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.xlarge", "c3.2xlarge", "c4.2xlarge", "c5.2xlarge"}, results)
+
+	// 8 threads on 4 cores excludes the single threaded instance types
+	results, err = itf.Filter(selector.Filters{
+		ThreadsRange: &selector.IntRangeFilter{LowerBound: 8, UpperBound: 8},
+		CoresRange:   &selector.IntRangeFilter{LowerBound: 4, UpperBound: 4},
+	})
+	h.Ok(t, err)
+	h.Equals(t, []string{"c3.2xlarge", "c4.2xlarge", "c5.2xlarge"}, results)
+}
+
+func TestFilter_AcceleratedNetworking(t *testing.T) {
+	itf := selector.Selector{
+		EC2:        setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: &ec2PricingMock{},
+	}
+	// c3 and c4 support the Intel 82599 VF interface but not ENA, so they do not qualify
+	results, err := itf.Filter(selector.Filters{
+		AcceleratedNetworking: aws.Bool(true),
+		VCpusRange:            &selector.IntRangeFilter{LowerBound: 2, UpperBound: 2},
+	})
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.large", "c5.large"}, results)
+
+	results, err = itf.Filter(selector.Filters{AcceleratedNetworking: aws.Bool(false)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"c1.medium", "c1.xlarge", "c3.2xlarge", "c3.4xlarge", "c3.8xlarge", "c3.large", "c3.xlarge",
+		"c4.2xlarge", "c4.4xlarge", "c4.8xlarge", "c4.large", "c4.xlarge"}, results)
+}
+
+func TestFilterVerbose_PriceEnrichmentBudget(t *testing.T) {
+	now := time.Now()
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp:    0.1,
+			GetSpotInstanceTypeNDayAvgCostResp: 0.03,
+			lastOnDemandCacheUTC:               &now,
+			lastSpotCacheUTC:                   &now,
+		},
+	}
+	// each instance type needs an on-demand and a spot lookup
+	results, err := itf.FilterVerbose(selector.Filters{PriceEnrichmentBudget: &selector.PriceEnrichmentBudget{MaxLookups: 10}})
+	h.Ok(t, err)
+	h.Equals(t, 25, len(results))
+	enriched, skipped := 0, 0
+	for _, result := range results {
+		if result.PriceEnrichmentSkipped {
+			skipped++
+			h.Assert(t, result.OndemandPricePerHour == nil && result.SpotPrice == nil, "Expected no prices for %s", *result.InstanceType)
+		} else {
+			enriched++
+			h.Assert(t, result.OndemandPricePerHour != nil && result.SpotPrice != nil, "Expected prices for %s", *result.InstanceType)
+		}
+	}
+	h.Equals(t, 5, enriched)
+	h.Equals(t, 20, skipped)
+
+	// an unlimited budget enriches every instance type
+	results, err = itf.FilterVerbose(selector.Filters{PriceEnrichmentBudget: &selector.PriceEnrichmentBudget{}})
+	h.Ok(t, err)
+	for _, result := range results {
+		h.Assert(t, !result.PriceEnrichmentSkipped, "Expected %s to be enriched", *result.InstanceType)
+	}
+}
+
+func TestFilterVerbose_PriceEnrichmentBudgetColdCache(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp: 0.1,
+		},
+	}
+	// the on-demand cache was not hydrated, so each on-demand lookup is an API call bounded by the budget
+	budget := &selector.PriceEnrichmentBudget{MaxLookups: 4, LookupOnDemandPrices: true}
+	results, err := itf.FilterVerbose(selector.Filters{PriceEnrichmentBudget: budget})
+	h.Ok(t, err)
+	h.Equals(t, 25, len(results))
+	enriched := 0
+	for _, result := range results {
+		if !result.PriceEnrichmentSkipped {
+			enriched++
+			h.Equals(t, 0.1, *result.OndemandPricePerHour)
+		}
+		h.Assert(t, result.SpotPrice == nil, "Expected no spot price for %s", *result.InstanceType)
+	}
+	h.Equals(t, 4, enriched)
+
+	// skipped instance types are returned rather than dropped by the price filter
+	results, err = itf.FilterVerbose(selector.Filters{
+		PricePerHour:          &selector.Float64RangeFilter{LowerBound: 0, UpperBound: 0.05},
+		PriceEnrichmentBudget: budget,
+	})
+	h.Ok(t, err)
+	h.Equals(t, 21, len(results))
+	for _, result := range results {
+		h.Assert(t, result.PriceEnrichmentSkipped, "Expected %s to be skipped", *result.InstanceType)
+	}
+}
+
+func TestFilterVerbose_PriceEnrichmentBudgetPreviousGenerationFallback(t *testing.T) {
+	itf := selector.Selector{
+		EC2: setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp: 0.1,
+		},
+	}
+	// the current generation pass spends the budget on its 3 instance types, which are all too expensive,
+	// so the fallback pass continues with the exhausted budget instead of a new one
+	results, err := itf.FilterVerbose(selector.Filters{
+		VCpusRange:                 &selector.IntRangeFilter{LowerBound: 8, UpperBound: 8},
+		PricePerHour:               &selector.Float64RangeFilter{LowerBound: 0, UpperBound: 0.05},
+		PreviousGenerationFallback: aws.Bool(true),
+		PriceEnrichmentBudget:      &selector.PriceEnrichmentBudget{MaxLookups: 3, LookupOnDemandPrices: true},
+	})
+	h.Ok(t, err)
+	h.Assert(t, len(results) > 3, "Expected the previous generation instance types to be returned, got %d", len(results))
+	for _, result := range results {
+		h.Assert(t, result.PriceEnrichmentSkipped, "Expected %s to be skipped", *result.InstanceType)
+		h.Assert(t, result.PreviousGenerationFallback, "Expected %s to be a previous generation fallback", *result.InstanceType)
+	}
+}
+
+// cancelableEC2PricingMock blocks each lookup until its context is done
+type cancelableEC2PricingMock struct {
+	ec2PricingMock
+	lookups int
+}
+
+func (p *cancelableEC2PricingMock) GetOndemandInstanceTypeCostWithContext(ctx context.Context, instanceType string) (float64, error) {
+	p.lookups++
+	<-ctx.Done()
+	return -1, ctx.Err()
+}
+
+func (p *cancelableEC2PricingMock) GetSpotInstanceTypeNDayAvgCostWithContext(ctx context.Context, instanceType string, availabilityZones []string, days int) (float64, error) {
+	p.lookups++
+	<-ctx.Done()
+	return -1, ctx.Err()
+}
+
+func TestFilterVerbose_PriceEnrichmentBudgetCancelsLookups(t *testing.T) {
+	pricingMock := &cancelableEC2PricingMock{}
+	itf := selector.Selector{
+		EC2:        setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: pricingMock,
+	}
+	start := time.Now()
+	results, err := itf.FilterVerbose(selector.Filters{
+		PriceEnrichmentBudget: &selector.PriceEnrichmentBudget{MaxDuration: 50 * time.Millisecond, LookupOnDemandPrices: true},
+	})
+	h.Ok(t, err)
+	h.Assert(t, time.Since(start) < 5*time.Second, "Expected the lookup in flight to be canceled by the time budget")
+	h.Equals(t, 1, pricingMock.lookups)
+	h.Equals(t, 25, len(results))
+	for _, result := range results {
+		h.Assert(t, result.PriceEnrichmentSkipped, "Expected %s to be skipped", *result.InstanceType)
+	}
+}
+
+func TestFilter_MinEBSThroughputForRestore(t *testing.T) {
+	itf := selector.Selector{
+		EC2:        setupMock(t, describeInstanceTypesPages, "g2_2xlarge_group.json"),
+		EC2Pricing: &ec2PricingMock{},
+	}
+	results, err := itf.Filter(selector.Filters{MinEBSThroughputForRestore: aws.Float64(140)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"c5.2xlarge", "c5d.2xlarge", "inf1.2xlarge"}, results)
+
+	// g2.2xlarge is not EBS-optimized
+	results, err = itf.Filter(selector.Filters{MinEBSThroughputForRestore: aws.Float64(0)})
+	h.Ok(t, err)
+	h.Equals(t, 6, len(results))
+}
+
+func TestFilter_MinNetworkPerVCpu(t *testing.T) {
+	itf := selector.Selector{
+		EC2:        setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: &ec2PricingMock{},
+	}
+	results, err := itf.Filter(selector.Filters{MinNetworkPerVCpu: aws.Float64(1.25)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.2xlarge", "a1.large", "a1.medium", "a1.xlarge", "c5.2xlarge", "c5.large"}, results)
+}
+
+func TestFilter_MinAggregateNetworkGbps(t *testing.T) {
+	itf := selector.Selector{
+		EC2:        setupMock(t, describeInstanceTypesPages, "multi_network_cards.json"),
+		EC2Pricing: &ec2PricingMock{},
+	}
+	results, err := itf.Filter(selector.Filters{MinAggregateNetworkGbps: aws.Float64(400)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"p4d.24xlarge", "p5.48xlarge"}, results)
+
+	// m5.large has no network cards so its network performance is used
+	results, err = itf.Filter(selector.Filters{MinAggregateNetworkGbps: aws.Float64(10)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"c5n.18xlarge", "m5.large", "p4d.24xlarge", "p5.48xlarge"}, results)
+
+	results, err = itf.Filter(selector.Filters{MinAggregateNetworkGbps: aws.Float64(3200)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"p5.48xlarge"}, results)
+}
+
+func TestFilter_MinInstanceStoreThroughput(t *testing.T) {
+	itf := selector.Selector{
+		EC2:        setupMock(t, describeInstanceTypesPages, "instance_store.json"),
+		EC2Pricing: &ec2PricingMock{},
+	}
+	results, err := itf.Filter(selector.Filters{MinInstanceStoreThroughput: aws.Float64(1200)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"i4i.xlarge"}, results)
+
+	// m5d.large has an NVMe instance store without published throughput, so it is excluded even with a threshold of 0
+	results, err = itf.Filter(selector.Filters{MinInstanceStoreThroughput: aws.Float64(0)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"i3.large", "i4i.large", "i4i.xlarge", "im4gn.large"}, results)
+}
+
+func TestFilter_FamilyAge(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypesPages, "25_instances.json")
+	itf := selector.Selector{
+		EC2:        ec2Mock,
+		EC2Pricing: &ec2PricingMock{},
+	}
+	// c1 launched in 2008 while every other family in the fixture launched after 2013
+	filters := selector.Filters{
+		FamilyAge: &selector.IntRangeFilter{LowerBound: 365 * 16, UpperBound: math.MaxInt32},
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"c1.medium", "c1.xlarge"}, results)
+
+	filters = selector.Filters{
+		FamilyAge: &selector.IntRangeFilter{LowerBound: 0, UpperBound: 365},
+	}
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+}
+
+func TestFilter_MissingMetadata(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesPagesResp: ec2.DescribeInstanceTypesOutput{
+			InstanceTypes: []*ec2.InstanceTypeInfo{{InstanceType: aws.String("x9.large")}},
+		},
+	}
+	itf := selector.Selector{
+		EC2:        ec2Mock,
+		EC2Pricing: &ec2PricingMock{},
+	}
+	results, err := itf.FilterVerbose(selector.Filters{})
+	h.Ok(t, err)
+	h.Equals(t, 1, len(results))
+	h.Equals(t, "x9.large", *results[0].InstanceType)
+
+	results, err = itf.FilterVerbose(selector.Filters{
+		VCpusRange: &selector.IntRangeFilter{LowerBound: 2, UpperBound: 4},
+	})
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+}
+
+func TestFilter_CatalogOnlyInstanceTypes(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypesPages, "t3_micro.json")
+	now := time.Now()
+	itf := selector.Selector{
+		EC2: ec2Mock,
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp: 0.5,
+			lastOnDemandCacheUTC:            &now,
+		},
+	}
+	filters := selector.Filters{
+		InstanceTypes: &[]string{"t3.micro", "x9.large"},
+	}
+	results, err := itf.FilterVerbose(filters)
+	h.Ok(t, err)
+	h.Equals(t, 2, len(results))
+	h.Equals(t, "x9.large", *results[1].InstanceType)
+	h.Equals(t, 0.5, *results[1].OndemandPricePerHour)
+	h.Assert(t, results[1].VCpuInfo.DefaultVCpus == nil, "catalog only instance types should have unknown vcpus")
+
+	filters.PricePerHour = &selector.Float64RangeFilter{LowerBound: 0, UpperBound: 0.1}
+	results, err = itf.FilterVerbose(filters)
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+
+	// without a pricing catalog, instance types missing from DescribeInstanceTypes cannot be reported
+	itf.EC2Pricing = &ec2PricingMock{}
+	results, err = itf.FilterVerbose(selector.Filters{InstanceTypes: &[]string{"t3.micro", "x9.large"}})
+	h.Ok(t, err)
+	h.Equals(t, 1, len(results))
+	h.Equals(t, "t3.micro", *results[0].InstanceType)
+}
+
+func TestFilter_CatalogOnlyInstanceTypesAttributeFilters(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypesPages, "t3_micro.json")
+	now := time.Now()
+	itf := selector.Selector{
+		EC2: ec2Mock,
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp: 0.5,
+			lastOnDemandCacheUTC:            &now,
+		},
+	}
+	// attribute filters cannot be evaluated against catalog only instance types, so they are excluded
+	results, err := itf.Filter(selector.Filters{
+		InstanceTypes:   &[]string{"t3.micro", "x9.large"},
+		VCpusRange:      &selector.IntRangeFilter{LowerBound: 64, UpperBound: 64},
+		CPUArchitecture: aws.String("arm64"),
+	})
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+
+	results, err = itf.Filter(selector.Filters{
+		InstanceTypes:     &[]string{"t3.micro", "x9.large"},
+		CurrentGeneration: aws.Bool(true),
+	})
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+
+	// the usage class is only evaluated from the catalog prices
+	results, err = itf.Filter(selector.Filters{
+		InstanceTypes: &[]string{"x9.large"},
+		UsageClass:    aws.String("spot"),
+	})
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+}
+
+func TestFilter_CatalogOnlyInstanceTypesPreviousGenerationFallback(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypesPages, "25_instances.json")
+	now := time.Now()
+	itf := selector.Selector{
+		EC2: ec2Mock,
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp: 0.5,
+			lastOnDemandCacheUTC:            &now,
+		},
+	}
+	filters := selector.Filters{
+		InstanceTypes:              &[]string{"c3.large", "x9.large"},
+		PreviousGenerationFallback: aws.Bool(true),
+	}
+	results, err := itf.FilterVerbose(filters)
+	h.Ok(t, err)
+	h.Equals(t, 2, len(results))
+	h.Equals(t, "c3.large", *results[0].InstanceType)
+	h.Equals(t, "x9.large", *results[1].InstanceType)
+	for _, result := range results {
+		h.Assert(t, result.PreviousGenerationFallback, "Expected %s to be marked as a previous generation fallback", *result.InstanceType)
+	}
+}
+
+func TestFilter_CatalogOnlyInstanceTypesMinSpotSavingsPercent(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypesPages, "t3_micro.json")
+	now := time.Now()
+	itf := selector.Selector{
+		EC2: ec2Mock,
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp:    0.1,
+			GetSpotInstanceTypeNDayAvgCostResp: 0.03,
+			lastOnDemandCacheUTC:               &now,
+			lastSpotCacheUTC:                   &now,
+		},
+	}
+	filters := selector.Filters{
+		InstanceTypes:         &[]string{"x9.large"},
+		MinSpotSavingsPercent: aws.Float64(60),
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"x9.large"}, results)
+
+	filters.MinSpotSavingsPercent = aws.Float64(80)
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+
+	// both prices are needed to compute the savings
+	itf.EC2Pricing = &ec2PricingMock{
+		GetOndemandInstanceTypeCostResp: 0.1,
+		lastOnDemandCacheUTC:            &now,
+	}
+	filters.MinSpotSavingsPercent = aws.Float64(60)
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+}
+
+func TestFilter_MinSpotSavingsPercent(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypesPages, "t3_micro.json")
+	now := time.Now()
+	itf := selector.Selector{
+		EC2: ec2Mock,
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp:    0.1,
+			GetSpotInstanceTypeNDayAvgCostResp: 0.03,
+			lastOnDemandCacheUTC:               &now,
+			lastSpotCacheUTC:                   &now,
+		},
+	}
+	results, err := itf.Filter(selector.Filters{MinSpotSavingsPercent: aws.Float64(60)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+
+	results, err = itf.Filter(selector.Filters{MinSpotSavingsPercent: aws.Float64(80)})
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+
+	// both prices are needed to compute the savings
+	itf.EC2Pricing = &ec2PricingMock{
+		GetOndemandInstanceTypeCostResp: 0.1,
+		lastOnDemandCacheUTC:            &now,
+	}
+	results, err = itf.Filter(selector.Filters{MinSpotSavingsPercent: aws.Float64(60)})
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+}
+
+func TestFilter_MemoryTolerancePercent(t *testing.T) {
+	itf := selector.Selector{
+		EC2:        setupMock(t, describeInstanceTypesPages, "25_instances.json"),
+		EC2Pricing: &ec2PricingMock{},
+	}
+	filters := selector.Filters{
+		MemoryRange: &selector.ByteQuantityRangeFilter{LowerBound: bytequantity.FromGiB(4), UpperBound: bytequantity.FromGiB(4)},
+	}
+	results, err := itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.large", "c5.large"}, results)
+
+	// 3840 MiB is within 7% of 4 GiB
+	filters.MemoryTolerancePercent = aws.Float64(7)
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.large", "c3.large", "c4.large", "c5.large"}, results)
+
+	// the tolerance only applies to exact memory filters
+	filters.MemoryRange = &selector.ByteQuantityRangeFilter{LowerBound: bytequantity.FromGiB(4), UpperBound: bytequantity.FromGiB(8)}
+	results, err = itf.Filter(filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.large", "a1.xlarge", "c1.xlarge", "c3.xlarge", "c4.xlarge", "c5.large"}, results)
+}
+
+func TestFilter_RequirePriceAvailable(t *testing.T) {
+	ec2Mock := setupMock(t, describeInstanceTypesPages, "t3_micro.json")
+	now := time.Now()
+	itf := selector.Selector{
+		EC2: ec2Mock,
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp: 0.0104,
+			lastOnDemandCacheUTC:            &now,
+		},
+	}
+	results, err := itf.Filter(selector.Filters{RequirePriceAvailable: aws.Bool(true)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+
+	// instance types that are not in the pricing catalog have a negative price
+	itf.EC2Pricing = &ec2PricingMock{
+		GetOndemandInstanceTypeCostResp: -1,
+		lastOnDemandCacheUTC:            &now,
+	}
+	results, err = itf.Filter(selector.Filters{RequirePriceAvailable: aws.Bool(true)})
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+
+	// not requiring a price does not select unpriced instance types only
+	results, err = itf.Filter(selector.Filters{RequirePriceAvailable: aws.Bool(false)})
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+
+	// without a hydrated cache no price can be resolved
+	itf.EC2Pricing = &ec2PricingMock{GetOndemandInstanceTypeCostResp: 0.0104}
+	results, err = itf.Filter(selector.Filters{RequirePriceAvailable: aws.Bool(true)})
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+}
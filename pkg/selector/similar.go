@@ -0,0 +1,146 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/ec2pricing"
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/instancetypes"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	// PriceTypeOnDemand compares instance types by their on-demand hourly price
+	PriceTypeOnDemand = "on-demand"
+	// PriceTypeSpot compares instance types by their 30 day average hourly spot price
+	PriceTypeSpot = "spot"
+
+	similarSpotDays = 30
+)
+
+// SelectSimilarOrBetter returns instance types that meet or exceed the vcpus, memory, gpus, and network performance of
+// the reference instance type while costing no more than it for the given price type (on-demand or spot).
+// The reference instance type itself is excluded and the results are sorted from cheapest to most expensive.
+func (itf Selector) SelectSimilarOrBetter(referenceType string, priceType string) ([]instancetypes.Details, error) {
+	if priceType != PriceTypeOnDemand && priceType != PriceTypeSpot {
+		return nil, fmt.Errorf("price type %s must be one of: %s, %s", priceType, PriceTypeOnDemand, PriceTypeSpot)
+	}
+	instanceTypesOutput, err := itf.EC2.DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []*string{aws.String(referenceType)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(instanceTypesOutput.InstanceTypes) == 0 {
+		return nil, fmt.Errorf("error instance type %s is not a valid instance type", referenceType)
+	}
+	referenceInfo := instanceTypesOutput.InstanceTypes[0]
+
+	referencePrice, err := itf.referencePrice(referenceType, priceType)
+	if err != nil {
+		return nil, err
+	}
+	if referencePrice < 0 {
+		return nil, fmt.Errorf("no %s price is available for instance type %s", priceType, referenceType)
+	}
+
+	// any attribute missing from the reference instance type is left unbounded
+	fillMissingMetadata(referenceInfo)
+	filters := Filters{
+		PricePerHour: &Float64RangeFilter{LowerBound: 0, UpperBound: referencePrice},
+		UsageClass:   aws.String(priceType),
+	}
+	if referenceInfo.VCpuInfo.DefaultVCpus != nil {
+		filters.VCpusRange = &IntRangeFilter{LowerBound: int(*referenceInfo.VCpuInfo.DefaultVCpus), UpperBound: math.MaxInt32}
+	}
+	if referenceInfo.MemoryInfo.SizeInMiB != nil {
+		filters.MemoryRange = &ByteQuantityRangeFilter{LowerBound: bytequantity.ByteQuantity{Quantity: uint64(*referenceInfo.MemoryInfo.SizeInMiB)}, UpperBound: bytequantity.ByteQuantity{Quantity: math.MaxInt32}}
+	}
+	if referenceInfo.GpuInfo != nil {
+		filters.GpusRange = &IntRangeFilter{LowerBound: int(*getTotalGpusCount(referenceInfo.GpuInfo)), UpperBound: math.MaxInt32}
+	}
+	if len(referenceInfo.ProcessorInfo.SupportedArchitectures) == 1 {
+		filters.CPUArchitecture = referenceInfo.ProcessorInfo.SupportedArchitectures[0]
+	}
+	if networkBandwidth := getNetworkBandwidthGbps(referenceInfo.NetworkInfo.NetworkPerformance); networkBandwidth != nil && *networkBandwidth > 0 {
+		filters.NetworkPerformance = &IntRangeFilter{LowerBound: int(math.Floor(*networkBandwidth)), UpperBound: math.MaxInt32}
+	}
+
+	instanceTypes, err := itf.rawFilter(filters)
+	if err != nil {
+		return nil, err
+	}
+	similarInstanceTypes := []instancetypes.Details{}
+	for _, instanceType := range instanceTypes {
+		// instance types without a price would otherwise pass the price filter with a price of 0
+		if *instanceType.InstanceType == referenceType || similarPrice(instanceType, priceType) == nil {
+			continue
+		}
+		similarInstanceTypes = append(similarInstanceTypes, instanceType)
+	}
+	sort.SliceStable(similarInstanceTypes, func(i, j int) bool {
+		iPrice := *similarPrice(similarInstanceTypes[i], priceType)
+		jPrice := *similarPrice(similarInstanceTypes[j], priceType)
+		if iPrice != jPrice {
+			return iPrice < jPrice
+		}
+		return *similarInstanceTypes[i].InstanceType < *similarInstanceTypes[j].InstanceType
+	})
+	return similarInstanceTypes, nil
+}
+
+// referencePrice hydrates the pricing cache for the price type if needed and returns the reference instance type's price
+func (itf Selector) referencePrice(referenceType string, priceType string) (float64, error) {
+	if priceType == PriceTypeSpot {
+		if itf.EC2Pricing.LastSpotCacheUTC() == nil {
+			if err := itf.EC2Pricing.HydrateSpotCache(similarSpotDays); err != nil && !isPartialHydration(err) {
+				return 0, fmt.Errorf("there was a problem refreshing the spot instance type pricing cache: %w", err)
+			}
+		}
+		price, err := itf.EC2Pricing.GetSpotInstanceTypeNDayAvgCost(referenceType, []string{}, similarSpotDays)
+		if err != nil {
+			return 0, fmt.Errorf("unable to retrieve the spot price of instance type %s: %w", referenceType, err)
+		}
+		return price, nil
+	}
+	if itf.EC2Pricing.LastOnDemandCacheUTC() == nil {
+		if err := itf.EC2Pricing.HydrateOndemandCache(); err != nil && !isPartialHydration(err) {
+			return 0, fmt.Errorf("there was a problem refreshing the on-demand instance type pricing cache: %w", err)
+		}
+	}
+	price, err := itf.EC2Pricing.GetOndemandInstanceTypeCost(referenceType)
+	if err != nil {
+		return 0, fmt.Errorf("unable to retrieve the on-demand price of instance type %s: %w", referenceType, err)
+	}
+	return price, nil
+}
+
+// isPartialHydration returns true if the cache was hydrated without some of its entries, which still leaves the reference price usable
+func isPartialHydration(err error) bool {
+	var partialErr *ec2pricing.PartialHydrationError
+	return errors.As(err, &partialErr)
+}
+
+func similarPrice(instanceType instancetypes.Details, priceType string) *float64 {
+	if priceType == PriceTypeSpot {
+		return instanceType.SpotPrice
+	}
+	return instanceType.OndemandPricePerHour
+}
@@ -0,0 +1,149 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func similarMockedEC2(t *testing.T) mockedEC2 {
+	return mockedEC2{
+		DescribeInstanceTypesResp:      setupMock(t, describeInstanceTypes, "c4_large.json").DescribeInstanceTypesResp,
+		DescribeInstanceTypesPagesResp: setupMock(t, describeInstanceTypesPages, "25_instances.json").DescribeInstanceTypesPagesResp,
+	}
+}
+
+func TestSelectSimilarOrBetter_OnDemand(t *testing.T) {
+	now := time.Now()
+	itf := selector.Selector{
+		EC2: similarMockedEC2(t),
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp: 0.1,
+			lastOnDemandCacheUTC:            &now,
+		},
+	}
+	results, err := itf.SelectSimilarOrBetter("c4.large", selector.PriceTypeOnDemand)
+	h.Ok(t, err)
+	expected := []string{
+		"c1.xlarge", "c3.2xlarge", "c3.4xlarge", "c3.8xlarge", "c3.large", "c3.xlarge",
+		"c4.2xlarge", "c4.4xlarge", "c4.8xlarge", "c4.xlarge",
+		"c5.12xlarge", "c5.18xlarge", "c5.24xlarge", "c5.2xlarge", "c5.4xlarge", "c5.9xlarge", "c5.large",
+	}
+	actual := []string{}
+	for _, result := range results {
+		actual = append(actual, *result.InstanceType)
+	}
+	h.Equals(t, expected, actual)
+}
+
+func TestSelectSimilarOrBetter_Spot(t *testing.T) {
+	now := time.Now()
+	itf := selector.Selector{
+		EC2: similarMockedEC2(t),
+		EC2Pricing: &ec2PricingMock{
+			GetSpotInstanceTypeNDayAvgCostResp: 0.03,
+			lastSpotCacheUTC:                   &now,
+		},
+	}
+	results, err := itf.SelectSimilarOrBetter("c4.large", selector.PriceTypeSpot)
+	h.Ok(t, err)
+	h.Equals(t, 16, len(results))
+	for _, result := range results {
+		h.Assert(t, *result.InstanceType != "c1.xlarge", "c1.xlarge does not support spot and should not be returned")
+		h.Equals(t, 0.03, *result.SpotPrice)
+	}
+}
+
+func TestSelectSimilarOrBetter_Errors(t *testing.T) {
+	now := time.Now()
+	itf := selector.Selector{
+		EC2: similarMockedEC2(t),
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostErr: errors.New("error"),
+			lastOnDemandCacheUTC:           &now,
+		},
+	}
+	_, err := itf.SelectSimilarOrBetter("c4.large", "reserved")
+	h.Nok(t, err)
+	_, err = itf.SelectSimilarOrBetter("c4.large", selector.PriceTypeOnDemand)
+	h.Nok(t, err)
+
+	itf.EC2Pricing = &ec2PricingMock{HydrateOndemandCacheErr: errors.New("error")}
+	_, err = itf.SelectSimilarOrBetter("c4.large", selector.PriceTypeOnDemand)
+	h.Nok(t, err)
+
+	itf.EC2 = setupMock(t, describeInstanceTypes, "empty.json")
+	_, err = itf.SelectSimilarOrBetter("c4.large", selector.PriceTypeOnDemand)
+	h.Nok(t, err)
+}
+
+func TestSelectSimilarOrBetter_MissingMetadata(t *testing.T) {
+	now := time.Now()
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeInstanceTypesResp: ec2.DescribeInstanceTypesOutput{
+				InstanceTypes: []*ec2.InstanceTypeInfo{{InstanceType: aws.String("x9.large")}},
+			},
+			DescribeInstanceTypesPagesResp: setupMock(t, describeInstanceTypesPages, "25_instances.json").DescribeInstanceTypesPagesResp,
+		},
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp: 0.1,
+			lastOnDemandCacheUTC:            &now,
+		},
+	}
+	// only the price bounds the results when the reference instance type has no attributes
+	results, err := itf.SelectSimilarOrBetter("x9.large", selector.PriceTypeOnDemand)
+	h.Ok(t, err)
+	h.Equals(t, 25, len(results))
+}
+
+func TestSelectSimilarOrBetter_FractionalNetworkPerformance(t *testing.T) {
+	now := time.Now()
+	instanceType := func(name string, networkPerformance string) *ec2.InstanceTypeInfo {
+		return &ec2.InstanceTypeInfo{
+			InstanceType:          aws.String(name),
+			SupportedUsageClasses: []*string{aws.String("on-demand")},
+			NetworkInfo:           &ec2.NetworkInfo{NetworkPerformance: aws.String(networkPerformance)},
+		}
+	}
+	itf := selector.Selector{
+		EC2: mockedEC2{
+			DescribeInstanceTypesResp: ec2.DescribeInstanceTypesOutput{
+				InstanceTypes: []*ec2.InstanceTypeInfo{instanceType("m5.large", "Up to 12.5 Gigabit")},
+			},
+			DescribeInstanceTypesPagesResp: ec2.DescribeInstanceTypesOutput{
+				InstanceTypes: []*ec2.InstanceTypeInfo{
+					instanceType("m5.large", "Up to 12.5 Gigabit"),
+					instanceType("m4.large", "Up to 10 Gigabit"),
+					instanceType("m5n.large", "25 Gigabit"),
+				},
+			},
+		},
+		EC2Pricing: &ec2PricingMock{
+			GetOndemandInstanceTypeCostResp: 0.1,
+			lastOnDemandCacheUTC:            &now,
+		},
+	}
+	results, err := itf.SelectSimilarOrBetter("m5.large", selector.PriceTypeOnDemand)
+	h.Ok(t, err)
+	h.Equals(t, 1, len(results))
+	h.Equals(t, "m5n.large", *results[0].InstanceType)
+}
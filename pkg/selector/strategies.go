@@ -0,0 +1,89 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/instancetypes"
+)
+
+// SelectionStrategy determines the order in which selected instance types are returned
+type SelectionStrategy string
+
+const (
+	// SelectionStrategyAlphabetical sorts instance types alpha-numerically, which is the order filter results are returned in
+	SelectionStrategyAlphabetical SelectionStrategy = "alphabetical"
+	// SelectionStrategyStableThenCheapest sorts instance types by spot interruption-rate bucket ascending and then by spot price ascending
+	// Instance types with an unknown interruption rate are sorted last and instance types without a spot price are sorted last within their bucket
+	SelectionStrategyStableThenCheapest SelectionStrategy = "stable-then-cheapest"
+)
+
+// SelectionStrategies are all of the supported selection strategies
+var SelectionStrategies = []SelectionStrategy{SelectionStrategyAlphabetical, SelectionStrategyStableThenCheapest}
+
+// SpotInterruptionRates provides the spot interruption-rate bucket of instance types
+type SpotInterruptionRates interface {
+	// GetSpotInterruptionRateBucket returns the index of the instance type's interruption-rate bucket,
+	// where 0 is the least frequently interrupted bucket
+	GetSpotInterruptionRateBucket(instanceType string) (int, error)
+}
+
+// SortBySelectionStrategy sorts instance types in the order of the selection strategy
+// Strategies which order by spot interruption rate require interruptionRates, and spot prices should already be populated on the instance types
+func SortBySelectionStrategy(instanceTypeInfoSlice []instancetypes.Details, strategy SelectionStrategy, interruptionRates SpotInterruptionRates) ([]instancetypes.Details, error) {
+	switch strategy {
+	case SelectionStrategyAlphabetical:
+		return sortInstanceTypeInfo(instanceTypeInfoSlice), nil
+	case SelectionStrategyStableThenCheapest:
+		if interruptionRates == nil {
+			return nil, fmt.Errorf("selection strategy %s requires spot interruption rates", strategy)
+		}
+		return sortByInterruptionRateThenSpotPrice(instanceTypeInfoSlice, interruptionRates), nil
+	default:
+		return nil, fmt.Errorf("selection strategy %s is not supported", strategy)
+	}
+}
+
+func sortByInterruptionRateThenSpotPrice(instanceTypeInfoSlice []instancetypes.Details, interruptionRates SpotInterruptionRates) []instancetypes.Details {
+	buckets := map[string]int{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		bucket, err := interruptionRates.GetSpotInterruptionRateBucket(*instanceTypeInfo.InstanceType)
+		if err != nil {
+			bucket = math.MaxInt32
+		}
+		buckets[*instanceTypeInfo.InstanceType] = bucket
+	}
+	spotPrice := func(instanceTypeInfo instancetypes.Details) float64 {
+		if instanceTypeInfo.SpotPrice == nil || math.IsNaN(*instanceTypeInfo.SpotPrice) {
+			return math.MaxFloat64
+		}
+		return *instanceTypeInfo.SpotPrice
+	}
+	sort.SliceStable(instanceTypeInfoSlice, func(i, j int) bool {
+		iInstanceInfo, jInstanceInfo := instanceTypeInfoSlice[i], instanceTypeInfoSlice[j]
+		iBucket, jBucket := buckets[*iInstanceInfo.InstanceType], buckets[*jInstanceInfo.InstanceType]
+		if iBucket != jBucket {
+			return iBucket < jBucket
+		}
+		if iPrice, jPrice := spotPrice(iInstanceInfo), spotPrice(jInstanceInfo); iPrice != jPrice {
+			return iPrice < jPrice
+		}
+		return strings.Compare(*iInstanceInfo.InstanceType, *jInstanceInfo.InstanceType) < 0
+	})
+	return instanceTypeInfoSlice
+}
@@ -0,0 +1,100 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+type mockedInterruptionRates map[string]int
+
+func (m mockedInterruptionRates) GetSpotInterruptionRateBucket(instanceType string) (int, error) {
+	bucket, ok := m[instanceType]
+	if !ok {
+		return -1, fmt.Errorf("no interruption rate for %s", instanceType)
+	}
+	return bucket, nil
+}
+
+func spotPricedDetails(instanceType string, spotPrice *float64) instancetypes.Details {
+	return instancetypes.Details{
+		InstanceTypeInfo: ec2.InstanceTypeInfo{InstanceType: aws.String(instanceType)},
+		SpotPrice:        spotPrice,
+	}
+}
+
+func instanceTypeNames(instanceTypes []instancetypes.Details) []string {
+	names := []string{}
+	for _, instanceType := range instanceTypes {
+		names = append(names, *instanceType.InstanceType)
+	}
+	return names
+}
+
+func TestSortBySelectionStrategy_StableThenCheapest(t *testing.T) {
+	instanceTypes := []instancetypes.Details{
+		spotPricedDetails("c5.large", aws.Float64(0.03)),
+		spotPricedDetails("m5.large", aws.Float64(0.04)),
+		spotPricedDetails("r5.large", aws.Float64(0.02)),
+		spotPricedDetails("t3.large", aws.Float64(0.01)),
+		spotPricedDetails("m4.large", nil),
+		spotPricedDetails("x9.large", aws.Float64(0.001)),
+	}
+	rates := mockedInterruptionRates{"c5.large": 1, "m5.large": 0, "r5.large": 1, "t3.large": 2, "m4.large": 0}
+	sorted, err := selector.SortBySelectionStrategy(instanceTypes, selector.SelectionStrategyStableThenCheapest, rates)
+	h.Ok(t, err)
+	h.Equals(t, []string{"m5.large", "m4.large", "r5.large", "c5.large", "t3.large", "x9.large"}, instanceTypeNames(sorted))
+
+	_, err = selector.SortBySelectionStrategy(instanceTypes, selector.SelectionStrategyStableThenCheapest, nil)
+	h.Nok(t, err)
+}
+
+func TestSortBySelectionStrategy_DuplicatePrices(t *testing.T) {
+	rates := mockedInterruptionRates{"c5.large": 0, "m5.large": 0, "r5.large": 0, "t3.large": 1}
+	expected := []string{"c5.large", "m5.large", "r5.large", "t3.large"}
+	orders := [][]string{
+		{"r5.large", "m5.large", "c5.large", "t3.large"},
+		{"t3.large", "c5.large", "r5.large", "m5.large"},
+		{"m5.large", "t3.large", "r5.large", "c5.large"},
+	}
+	for _, order := range orders {
+		instanceTypes := []instancetypes.Details{}
+		for _, instanceType := range order {
+			instanceTypes = append(instanceTypes, spotPricedDetails(instanceType, aws.Float64(0.03)))
+		}
+		sorted, err := selector.SortBySelectionStrategy(instanceTypes, selector.SelectionStrategyStableThenCheapest, rates)
+		h.Ok(t, err)
+		h.Equals(t, expected, instanceTypeNames(sorted))
+	}
+}
+
+func TestSortBySelectionStrategy_Alphabetical(t *testing.T) {
+	instanceTypes := []instancetypes.Details{
+		spotPricedDetails("m5.large", nil),
+		spotPricedDetails("c5.large", nil),
+	}
+	sorted, err := selector.SortBySelectionStrategy(instanceTypes, selector.SelectionStrategyAlphabetical, nil)
+	h.Ok(t, err)
+	h.Equals(t, []string{"c5.large", "m5.large"}, instanceTypeNames(sorted))
+
+	_, err = selector.SortBySelectionStrategy(instanceTypes, selector.SelectionStrategy("random"), nil)
+	h.Nok(t, err)
+}
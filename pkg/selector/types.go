@@ -121,9 +121,18 @@ type Filters struct {
 	// CurrentGeneration returns the latest generation of instance types
 	CurrentGeneration *bool
 
+	// PreviousGenerationFallback first filters to current generation instance types and, only if none match,
+	// filters again including previous generation instance types. It cannot be used with CurrentGeneration.
+	// Results from the fallback are returned with PreviousGenerationFallback set.
+	PreviousGenerationFallback *bool
+
 	// EnaSupport returns instances that can support an Elastic Network Adapter.
 	EnaSupport *bool
 
+	// AcceleratedNetworking returns instance types which support enhanced networking through an Elastic Network Adapter,
+	// which is SR-IOV based. Instance types limited to the Intel 82599 VF interface do not qualify
+	AcceleratedNetworking *bool
+
 	// EfaSupport returns instances that can support an Elastic Fabric Adapter.
 	EfaSupport *bool
 
@@ -136,6 +145,15 @@ type Filters struct {
 	// GpuMemoryRange filter is a range of acceptable GPU memory in Gibibytes (GiB) available to an EC2 instance type in aggreagte across all GPUs.
 	GpuMemoryRange *ByteQuantityRangeFilter
 
+	// MinGpuTier filters instance types to those with GPUs of at least the specified compute capability tier (architecture generation)
+	// Possible values are: kepler, maxwell, pascal, volta, turing, ampere, ada, or hopper
+	MinGpuTier *string
+
+	// MinVCpusPerGpu filters instance types to those with at least this many vcpus for each GPU
+	// Instance types without GPUs do not match this filter
+	// Example: 8 returns p3.16xlarge (64 vcpus and 8 GPUs)
+	MinVCpusPerGpu *float64
+
 	// HibernationSupported denotes whether EC2 hibernate is supported
 	// Possible values are: true or false
 	HibernationSupported *bool
@@ -150,12 +168,46 @@ type Filters struct {
 	// MemoryRange filter is a range of acceptable DRAM memory in Gibibytes (GiB) for the instance type
 	MemoryRange *ByteQuantityRangeFilter
 
+	// MemoryTolerancePercent widens an exact MemoryRange (equal lower and upper bounds) by this percentage in both directions
+	// so that memory sizes which are slightly off due to unit rounding still match. The default of 0 is strict equality.
+	// Example: 1 matches 16000 MiB when MemoryRange is exactly 16 GiB (16384 MiB)
+	MemoryTolerancePercent *float64
+
 	// NetworkInterfaces filter is a range of the number of ENI attachments an instance type can support
 	NetworkInterfaces *IntRangeFilter
 
 	// NetworkPerformance filter is a range of network bandwidth an instance type can support
 	NetworkPerformance *IntRangeFilter
 
+	// MinNetworkPerVCpu filters instance types to those with at least this much network bandwidth in Gbps for each vcpu
+	// "Up to" bandwidths count as their burst bandwidth and instance types with qualitative performance like "High" do not match
+	// Example: 1.25 returns c5.2xlarge (Up to 10 Gigabit and 8 vcpus)
+	MinNetworkPerVCpu *float64
+
+	// MinAggregateNetworkGbps filters instance types to those with at least this much network bandwidth in Gbps summed across all network cards
+	// Instance types without network card info are compared by their network performance
+	// Example: 400 returns p4d.24xlarge (4 network cards of 100 Gigabit)
+	MinAggregateNetworkGbps *float64
+
+	// EBSVolumeAttachments filter is a range of the maximum number of EBS volumes (including the root volume) an instance type can attach
+	// Nitro instance types share attachments with ENIs and NVMe instance store volumes, so the limit assumes only the primary ENI is attached
+	EBSVolumeAttachments *IntRangeFilter
+
+	// MinEBSThroughputForRestore filters instance types to those with an EBS-optimized baseline throughput of at least this many MB/s,
+	// so that volumes restored from snapshots can be initialized quickly
+	// Instance types which are not EBS-optimized do not match this filter
+	// Example: 1187.5 MB/s (9.5 Gbps)
+	MinEBSThroughputForRestore *float64
+
+	// MinInstanceStoreThroughput filters instance types to those with an NVMe instance store read throughput of at least this many MB/s
+	// Throughput is taken from a maintained table of storage optimized families, instance types without published throughput do not match
+	// Example: 1200 returns i4i.xlarge and larger
+	MinInstanceStoreThroughput *float64
+
+	// FamilyAge filter is a range of the number of days since the instance type's family was launched
+	// Instance types of families with an unknown launch date do not match this filter
+	FamilyAge *IntRangeFilter
+
 	// PlacementGroupStrategy is used to return instance types based on its support
 	// for a specific placement group strategy
 	// Possible values are: cluster, spread, or partition
@@ -177,6 +229,13 @@ type Filters struct {
 	// VCpusRange filter is a range of acceptable VCpus for the instance type
 	VCpusRange *IntRangeFilter
 
+	// ThreadsRange filter is a range of acceptable logical processors (threads) for the instance type
+	// Each vcpu is a thread, so this is useful alongside CoresRange for software licensed per logical processor
+	ThreadsRange *IntRangeFilter
+
+	// CoresRange filter is a range of acceptable physical cores for the instance type
+	CoresRange *IntRangeFilter
+
 	// VcpusToMemoryRatio is a ratio of vcpus to memory expressed as a floating point
 	VCpusToMemoryRatio *float64
 
@@ -205,4 +264,18 @@ type Filters struct {
 
 	// PricePerHour is used to return instance types that are equal to or cheaper than the specified price
 	PricePerHour *Float64RangeFilter
+
+	// MinSpotSavingsPercent is used to return instance types whose spot price is at least this percentage below their on-demand price
+	// Both the on-demand and spot pricing caches must be hydrated, instance types without both prices do not match this filter
+	// Example: 60 returns instance types where spot is 60% or more cheaper than on-demand
+	MinSpotSavingsPercent *float64
+
+	// RequirePriceAvailable drops instance types which do not have an on-demand price in the region
+	// The on-demand pricing cache must be hydrated, otherwise no instance types match this filter
+	RequirePriceAvailable *bool
+
+	// PriceEnrichmentBudget bounds the time and number of price lookups spent enriching instance types with prices
+	// Instance types which are not enriched before the budget is exhausted are returned with PriceEnrichmentSkipped set,
+	// and the price filters are not applied to them
+	PriceEnrichmentBudget *PriceEnrichmentBudget
 }
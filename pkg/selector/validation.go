@@ -0,0 +1,121 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+// Validate checks the Filters for impossible or contradictory combinations which would always result in no instance types
+// being returned. All problems found are returned together so that they can be fixed at once.
+// Validate does not make any API calls.
+func (f Filters) Validate() error {
+	var err error
+	if f.VCpusRange != nil && f.VCpusRange.LowerBound > f.VCpusRange.UpperBound {
+		err = multierr.Append(err, rangeBoundsErr("VCpusRange", f.VCpusRange.LowerBound, f.VCpusRange.UpperBound))
+	}
+	if f.ThreadsRange != nil && f.ThreadsRange.LowerBound > f.ThreadsRange.UpperBound {
+		err = multierr.Append(err, rangeBoundsErr("ThreadsRange", f.ThreadsRange.LowerBound, f.ThreadsRange.UpperBound))
+	}
+	if f.CoresRange != nil && f.CoresRange.LowerBound > f.CoresRange.UpperBound {
+		err = multierr.Append(err, rangeBoundsErr("CoresRange", f.CoresRange.LowerBound, f.CoresRange.UpperBound))
+	}
+	if f.GpusRange != nil && f.GpusRange.LowerBound > f.GpusRange.UpperBound {
+		err = multierr.Append(err, rangeBoundsErr("GpusRange", f.GpusRange.LowerBound, f.GpusRange.UpperBound))
+	}
+	if f.NetworkInterfaces != nil && f.NetworkInterfaces.LowerBound > f.NetworkInterfaces.UpperBound {
+		err = multierr.Append(err, rangeBoundsErr("NetworkInterfaces", f.NetworkInterfaces.LowerBound, f.NetworkInterfaces.UpperBound))
+	}
+	if f.NetworkPerformance != nil && f.NetworkPerformance.LowerBound > f.NetworkPerformance.UpperBound {
+		err = multierr.Append(err, rangeBoundsErr("NetworkPerformance", f.NetworkPerformance.LowerBound, f.NetworkPerformance.UpperBound))
+	}
+	if f.EBSVolumeAttachments != nil && f.EBSVolumeAttachments.LowerBound > f.EBSVolumeAttachments.UpperBound {
+		err = multierr.Append(err, rangeBoundsErr("EBSVolumeAttachments", f.EBSVolumeAttachments.LowerBound, f.EBSVolumeAttachments.UpperBound))
+	}
+	if f.FamilyAge != nil && f.FamilyAge.LowerBound > f.FamilyAge.UpperBound {
+		err = multierr.Append(err, rangeBoundsErr("FamilyAge", f.FamilyAge.LowerBound, f.FamilyAge.UpperBound))
+	}
+	if f.MemoryRange != nil && f.MemoryRange.LowerBound.Quantity > f.MemoryRange.UpperBound.Quantity {
+		err = multierr.Append(err, rangeBoundsErr("MemoryRange", f.MemoryRange.LowerBound.StringGiB(), f.MemoryRange.UpperBound.StringGiB()))
+	}
+	if f.GpuMemoryRange != nil && f.GpuMemoryRange.LowerBound.Quantity > f.GpuMemoryRange.UpperBound.Quantity {
+		err = multierr.Append(err, rangeBoundsErr("GpuMemoryRange", f.GpuMemoryRange.LowerBound.StringGiB(), f.GpuMemoryRange.UpperBound.StringGiB()))
+	}
+	if f.PricePerHour != nil && f.PricePerHour.LowerBound > f.PricePerHour.UpperBound {
+		err = multierr.Append(err, rangeBoundsErr("PricePerHour", f.PricePerHour.LowerBound, f.PricePerHour.UpperBound))
+	}
+	if f.VCpusToMemoryRatio != nil && *f.VCpusToMemoryRatio <= 0 {
+		err = multierr.Append(err, fmt.Errorf("VCpusToMemoryRatio must be greater than 0"))
+	}
+	if f.MemoryTolerancePercent != nil && (*f.MemoryTolerancePercent < 0 || *f.MemoryTolerancePercent >= 100) {
+		err = multierr.Append(err, fmt.Errorf("MemoryTolerancePercent (%v) must be at least 0 and less than 100", *f.MemoryTolerancePercent))
+	}
+	if f.MinSpotSavingsPercent != nil && (*f.MinSpotSavingsPercent < 0 || *f.MinSpotSavingsPercent > 100) {
+		err = multierr.Append(err, fmt.Errorf("MinSpotSavingsPercent (%v) must be between 0 and 100", *f.MinSpotSavingsPercent))
+	}
+	if f.PriceEnrichmentBudget != nil && (f.PriceEnrichmentBudget.MaxDuration < 0 || f.PriceEnrichmentBudget.MaxLookups < 0) {
+		err = multierr.Append(err, fmt.Errorf("PriceEnrichmentBudget limits must not be negative"))
+	}
+	if f.MaxResults != nil && *f.MaxResults < 0 {
+		err = multierr.Append(err, fmt.Errorf("MaxResults must not be negative"))
+	}
+	if f.BareMetal != nil && *f.BareMetal && f.Hypervisor != nil {
+		err = multierr.Append(err, fmt.Errorf("BareMetal instance types do not run on a hypervisor, so Hypervisor (%s) cannot be set when BareMetal is true", *f.Hypervisor))
+	}
+	if f.GpusRange != nil && f.GpusRange.UpperBound == 0 {
+		if f.GpuMemoryRange != nil && f.GpuMemoryRange.LowerBound.Quantity > 0 {
+			err = multierr.Append(err, fmt.Errorf("GpuMemoryRange requires at least one GPU, but GpusRange only allows 0 GPUs"))
+		}
+	}
+	if f.MinGpuTier != nil && gpuTierRank(*f.MinGpuTier) == 0 {
+		err = multierr.Append(err, fmt.Errorf("MinGpuTier (%s) must be one of: %s", *f.MinGpuTier, strings.Join(GpuTiers, ", ")))
+	}
+	if f.MinEBSThroughputForRestore != nil && *f.MinEBSThroughputForRestore < 0 {
+		err = multierr.Append(err, fmt.Errorf("MinEBSThroughputForRestore (%v) must not be negative", *f.MinEBSThroughputForRestore))
+	}
+	if f.MinInstanceStoreThroughput != nil && *f.MinInstanceStoreThroughput < 0 {
+		err = multierr.Append(err, fmt.Errorf("MinInstanceStoreThroughput (%v) must not be negative", *f.MinInstanceStoreThroughput))
+	}
+	if f.MinNetworkPerVCpu != nil && *f.MinNetworkPerVCpu < 0 {
+		err = multierr.Append(err, fmt.Errorf("MinNetworkPerVCpu (%v) must not be negative", *f.MinNetworkPerVCpu))
+	}
+	if f.MinAggregateNetworkGbps != nil && *f.MinAggregateNetworkGbps < 0 {
+		err = multierr.Append(err, fmt.Errorf("MinAggregateNetworkGbps (%v) must not be negative", *f.MinAggregateNetworkGbps))
+	}
+	if f.MinVCpusPerGpu != nil && *f.MinVCpusPerGpu < 0 {
+		err = multierr.Append(err, fmt.Errorf("MinVCpusPerGpu (%v) must not be negative", *f.MinVCpusPerGpu))
+	}
+	if f.GpusRange != nil && f.GpusRange.UpperBound == 0 && f.MinVCpusPerGpu != nil {
+		err = multierr.Append(err, fmt.Errorf("MinVCpusPerGpu requires at least one GPU, but GpusRange only allows 0 GPUs"))
+	}
+	if f.GpusRange != nil && f.GpusRange.UpperBound == 0 && f.MinGpuTier != nil {
+		err = multierr.Append(err, fmt.Errorf("MinGpuTier requires at least one GPU, but GpusRange only allows 0 GPUs"))
+	}
+	if f.PreviousGenerationFallback != nil && *f.PreviousGenerationFallback && f.CurrentGeneration != nil {
+		err = multierr.Append(err, fmt.Errorf("PreviousGenerationFallback selects the instance type generation itself, so CurrentGeneration cannot also be set"))
+	}
+	if f.VirtualizationType != nil && f.Hypervisor != nil && *f.Hypervisor == "nitro" {
+		if *f.VirtualizationType == virtualizationTypePV || *f.VirtualizationType == virtualizationTypeParaVirtual {
+			err = multierr.Append(err, fmt.Errorf("paravirtual (pv) virtualization is only supported on the xen hypervisor, not nitro"))
+		}
+	}
+	return err
+}
+
+func rangeBoundsErr(filterName string, lowerBound interface{}, upperBound interface{}) error {
+	return fmt.Errorf("%s lower bound (%v) must be less than or equal to the upper bound (%v)", filterName, lowerBound, upperBound)
+}
@@ -0,0 +1,166 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package selector_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v2/pkg/test"
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/multierr"
+)
+
+// Tests
+
+func TestValidate(t *testing.T) {
+	filters := selector.Filters{
+		VCpusRange:  &selector.IntRangeFilter{LowerBound: 2, UpperBound: 4},
+		BareMetal:   aws.Bool(false),
+		Hypervisor:  aws.String("nitro"),
+		GpusRange:   &selector.IntRangeFilter{LowerBound: 0, UpperBound: 0},
+		MemoryRange: &selector.ByteQuantityRangeFilter{LowerBound: bytequantity.FromGiB(2), UpperBound: bytequantity.FromGiB(4)},
+	}
+	h.Ok(t, filters.Validate())
+	h.Ok(t, selector.Filters{}.Validate())
+}
+
+func TestValidate_BareMetalWithHypervisor(t *testing.T) {
+	filters := selector.Filters{
+		BareMetal:  aws.Bool(true),
+		Hypervisor: aws.String("xen"),
+	}
+	err := filters.Validate()
+	h.Nok(t, err)
+	h.Assert(t, strings.Contains(err.Error(), "BareMetal"), "Error should mention BareMetal: %s", err)
+}
+
+func TestValidate_GpuMemoryWithZeroGpus(t *testing.T) {
+	filters := selector.Filters{
+		GpusRange:      &selector.IntRangeFilter{LowerBound: 0, UpperBound: 0},
+		GpuMemoryRange: &selector.ByteQuantityRangeFilter{LowerBound: bytequantity.FromGiB(8), UpperBound: bytequantity.FromGiB(16)},
+	}
+	err := filters.Validate()
+	h.Nok(t, err)
+	h.Assert(t, strings.Contains(err.Error(), "GpuMemoryRange"), "Error should mention GpuMemoryRange: %s", err)
+}
+
+func TestValidate_PVOnNitro(t *testing.T) {
+	filters := selector.Filters{
+		VirtualizationType: aws.String("pv"),
+		Hypervisor:         aws.String("nitro"),
+	}
+	h.Nok(t, filters.Validate())
+}
+
+func TestValidate_InvertedRanges(t *testing.T) {
+	filters := selector.Filters{
+		VCpusRange:   &selector.IntRangeFilter{LowerBound: 8, UpperBound: 4},
+		MemoryRange:  &selector.ByteQuantityRangeFilter{LowerBound: bytequantity.FromGiB(8), UpperBound: bytequantity.FromGiB(4)},
+		PricePerHour: &selector.Float64RangeFilter{LowerBound: 1.0, UpperBound: 0.5},
+	}
+	err := filters.Validate()
+	h.Nok(t, err)
+	h.Equals(t, 3, len(multierr.Errors(err)))
+}
+
+func TestFilter_InvalidFilters(t *testing.T) {
+	itf := selector.Selector{
+		EC2:        mockedEC2{},
+		EC2Pricing: &ec2PricingMock{},
+	}
+	filters := selector.Filters{
+		BareMetal:  aws.Bool(true),
+		Hypervisor: aws.String("nitro"),
+	}
+	results, err := itf.Filter(filters)
+	h.Nok(t, err)
+	h.Assert(t, results == nil, "Results should be nil when filters are invalid")
+}
+
+func TestValidate_PreviousGenerationFallbackWithCurrentGeneration(t *testing.T) {
+	filters := selector.Filters{
+		CurrentGeneration:          aws.Bool(true),
+		PreviousGenerationFallback: aws.Bool(true),
+	}
+	h.Nok(t, filters.Validate())
+}
+
+func TestValidate_MinGpuTier(t *testing.T) {
+	h.Ok(t, selector.Filters{MinGpuTier: aws.String("Ampere")}.Validate())
+	h.Nok(t, selector.Filters{MinGpuTier: aws.String("blackwell-ultra")}.Validate())
+	h.Nok(t, selector.Filters{
+		MinGpuTier: aws.String("volta"),
+		GpusRange:  &selector.IntRangeFilter{LowerBound: 0, UpperBound: 0},
+	}.Validate())
+}
+
+func TestValidate_ThreadsAndCores(t *testing.T) {
+	h.Ok(t, selector.Filters{
+		ThreadsRange: &selector.IntRangeFilter{LowerBound: 8, UpperBound: 16},
+		CoresRange:   &selector.IntRangeFilter{LowerBound: 4, UpperBound: 8},
+	}.Validate())
+	h.Nok(t, selector.Filters{ThreadsRange: &selector.IntRangeFilter{LowerBound: 16, UpperBound: 8}}.Validate())
+	h.Nok(t, selector.Filters{CoresRange: &selector.IntRangeFilter{LowerBound: 8, UpperBound: 4}}.Validate())
+}
+
+func TestValidate_PriceEnrichmentBudget(t *testing.T) {
+	h.Ok(t, selector.Filters{PriceEnrichmentBudget: &selector.PriceEnrichmentBudget{MaxDuration: time.Second, MaxLookups: 10}}.Validate())
+	h.Nok(t, selector.Filters{PriceEnrichmentBudget: &selector.PriceEnrichmentBudget{MaxDuration: -time.Second}}.Validate())
+	h.Nok(t, selector.Filters{PriceEnrichmentBudget: &selector.PriceEnrichmentBudget{MaxLookups: -1}}.Validate())
+}
+
+func TestValidate_MinEBSThroughputForRestore(t *testing.T) {
+	h.Ok(t, selector.Filters{MinEBSThroughputForRestore: aws.Float64(1187.5)}.Validate())
+	h.Nok(t, selector.Filters{MinEBSThroughputForRestore: aws.Float64(-1)}.Validate())
+}
+
+func TestValidate_MinInstanceStoreThroughput(t *testing.T) {
+	h.Ok(t, selector.Filters{MinInstanceStoreThroughput: aws.Float64(1200)}.Validate())
+	h.Nok(t, selector.Filters{MinInstanceStoreThroughput: aws.Float64(-1)}.Validate())
+}
+
+func TestValidate_MinNetworkPerVCpu(t *testing.T) {
+	h.Ok(t, selector.Filters{MinNetworkPerVCpu: aws.Float64(1.25)}.Validate())
+	h.Nok(t, selector.Filters{MinNetworkPerVCpu: aws.Float64(-1)}.Validate())
+}
+
+func TestValidate_MinAggregateNetworkGbps(t *testing.T) {
+	h.Ok(t, selector.Filters{MinAggregateNetworkGbps: aws.Float64(400)}.Validate())
+	h.Nok(t, selector.Filters{MinAggregateNetworkGbps: aws.Float64(-1)}.Validate())
+}
+
+func TestValidate_MinVCpusPerGpu(t *testing.T) {
+	h.Ok(t, selector.Filters{MinVCpusPerGpu: aws.Float64(8)}.Validate())
+	h.Nok(t, selector.Filters{MinVCpusPerGpu: aws.Float64(-1)}.Validate())
+	h.Nok(t, selector.Filters{
+		MinVCpusPerGpu: aws.Float64(8),
+		GpusRange:      &selector.IntRangeFilter{LowerBound: 0, UpperBound: 0},
+	}.Validate())
+}
+
+func TestValidate_MinSpotSavingsPercent(t *testing.T) {
+	h.Ok(t, selector.Filters{MinSpotSavingsPercent: aws.Float64(60)}.Validate())
+	h.Nok(t, selector.Filters{MinSpotSavingsPercent: aws.Float64(-1)}.Validate())
+	h.Nok(t, selector.Filters{MinSpotSavingsPercent: aws.Float64(101)}.Validate())
+}
+
+func TestValidate_MemoryTolerancePercent(t *testing.T) {
+	h.Ok(t, selector.Filters{MemoryTolerancePercent: aws.Float64(1)}.Validate())
+	h.Nok(t, selector.Filters{MemoryTolerancePercent: aws.Float64(-1)}.Validate())
+	h.Nok(t, selector.Filters{MemoryTolerancePercent: aws.Float64(100)}.Validate())
+}